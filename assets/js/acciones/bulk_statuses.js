@@ -0,0 +1,103 @@
+// Texto y clase de cada acción prevista para una fila
+const acciones = {
+  create: ["Crear", "text-success"],
+  exists: ["Ya existe", "text-muted"],
+  invalid: ["Inválida", "text-danger"]
+};
+
+// Pinta el resultado del alta (o de la previsualización) fila a fila
+function renderBulkResult(resultado) {
+  const contenedor = document.getElementById("resultado");
+  contenedor.innerHTML = "";
+
+  const resumen = document.createElement("p");
+  const aCrear = resultado.rows.filter(f => f.action === "create").length;
+  resumen.textContent = resultado.dryRun
+    ? `Previsualización: ${aCrear} a crear, ${resultado.existing} ya existen, ${resultado.invalid} inválidas.`
+    : `${resultado.created} creados, ${resultado.failed} con error, ${resultado.existing} ya existían, ${resultado.invalid} inválidas.`;
+  contenedor.appendChild(resumen);
+
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>
+    <th>Fila</th>
+    <th>Nombre</th>
+    <th>Descripción</th>
+    <th>Categoría</th>
+    <th>Acción</th>
+    <th>Resultado</th>
+    <th>ID</th>
+    <th>Mensaje</th>
+  </tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  resultado.rows.forEach(f => {
+    const [texto, clase] = acciones[f.action] || [f.action, ""];
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td>${f.row}</td>
+                    <td>${f.name}</td>
+                    <td>${f.description}</td>
+                    <td>${f.statusCategory}</td>
+                    <td class="${clase}">${texto}</td>
+                    <td>${f.result}</td>
+                    <td>${f.id || ""}</td>
+                    <td>${f.message || ""}</td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  contenedor.appendChild(table);
+}
+
+// Envía el CSV al backend en modo dry-run o real
+async function enviarCSV(dryRun) {
+  const res = await fetch("/bulkcreatestatuses", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ csv: document.getElementById("csv").value, dryRun })
+  });
+  if (!res.ok) {
+    document.getElementById("resultado").textContent = await res.text();
+    return null;
+  }
+  const resultado = await res.json();
+  renderBulkResult(resultado);
+  return resultado;
+}
+
+// Función de inicialización para bulk_statuses.html
+export function initBulkStatuses() {
+  const csv = document.getElementById("csv");
+  const crear = document.getElementById("crear");
+
+  document.getElementById("ficheroCSV").addEventListener("change", async (e) => {
+    const fichero = e.target.files[0];
+    if (fichero) csv.value = await fichero.text();
+    crear.disabled = true;
+  });
+  // Cualquier cambio en el CSV obliga a previsualizar de nuevo
+  csv.addEventListener("input", () => { crear.disabled = true; });
+
+  document.getElementById("bulkForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    try {
+      const resultado = await enviarCSV(true);
+      crear.disabled = !resultado || !resultado.rows.some(f => f.action === "create");
+    } catch (error) {
+      console.error("Error al previsualizar el CSV:", error);
+      alert("Error al previsualizar el CSV: " + error);
+    }
+  });
+
+  crear.addEventListener("click", async () => {
+    if (!confirm("¿Crear en Jira los estados marcados como 'Crear'?")) return;
+    crear.disabled = true;
+    try {
+      await enviarCSV(false);
+    } catch (error) {
+      console.error("Error al crear los estados:", error);
+      alert("Error al crear los estados: " + error);
+    }
+  });
+}
@@ -0,0 +1,62 @@
+// Lista de estados como "Nombre (CATEGORÍA)"
+function listaEstados(estados) {
+  return estados.map(e => `${e.name || e.id} (${e.statusCategory || "?"})`).join(", ");
+}
+
+// Crea una tabla con las cabeceras y filas indicadas
+function crearTabla(cabeceras, filas) {
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>${cabeceras.map(c => `<th>${c}</th>`).join("")}</tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  filas.forEach(celdas => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = celdas.map(c => `<td>${c}</td>`).join("");
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  return table;
+}
+
+// Pinta los estados y workflows con categorías incoherentes
+function renderCategoryConsistency(informe) {
+  const estados = document.getElementById("estados");
+  estados.innerHTML = "";
+  if (informe.statuses.length === 0) {
+    estados.textContent = "Ningún estado contradice su categoría.";
+  } else {
+    estados.appendChild(crearTabla(
+      ["ID", "Estado", "Categoría", "Categoría esperada", "Palabra", "Workflows"],
+      informe.statuses.map(st => [st.id, st.name, st.statusCategory, st.expectedCategory, st.keyword, st.workflows.join(", ")])
+    ));
+  }
+
+  const workflows = document.getElementById("workflows");
+  workflows.innerHTML = "";
+  if (informe.workflows.length === 0) {
+    workflows.textContent = "Todos los estados finales están en la categoría Done.";
+  } else {
+    workflows.appendChild(crearTabla(
+      ["Workflow", "Estados finales", "Fuera de Done"],
+      informe.workflows.map(wf => [wf.workflow, listaEstados(wf.finalStates), listaEstados(wf.notDone)])
+    ));
+  }
+}
+
+// Función de inicialización para category_consistency.html
+export async function initCategoryConsistency() {
+  try {
+    const res = await fetch("/getcategoryconsistency");
+    if (!res.ok) {
+      document.getElementById("estados").textContent = await res.text();
+      return;
+    }
+    renderCategoryConsistency(await res.json());
+  } catch (error) {
+    console.error("Error al cargar el análisis de categorías:", error);
+    alert("Error al cargar el análisis de categorías: " + error);
+  }
+}
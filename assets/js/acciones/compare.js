@@ -0,0 +1,56 @@
+import { fillConnectionSelect } from "/assets/js/acciones/workflow_diff.js";
+
+// Pinta una sección del diff (solo en A, solo en B y modificados)
+export function renderSection(titulo, seccion, etiquetaA = "Solo en A", etiquetaB = "Solo en B") {
+  if (!seccion) return `<h4>${titulo}</h4><p>No comparado (falta la sección en algún lado).</p>`;
+  const lista = elementos => elementos.length === 0 ? "<p>Ninguno</p>" :
+    '<ul>' + elementos.map(e => `<li>${e}</li>`).join("") + '</ul>';
+  const cambios = seccion.changed.length === 0 ? "<p>Ninguno</p>" :
+    '<ul>' + seccion.changed.map(c => `<li><strong>${c.name}</strong>: ${c.changes.join("; ")}</li>`).join("") + '</ul>';
+  return `<h4>${titulo}</h4>
+    <div class="row">
+      <div class="col-md-4"><h6>${etiquetaA} (${seccion.onlyInA.length})</h6>${lista(seccion.onlyInA)}</div>
+      <div class="col-md-4"><h6>${etiquetaB} (${seccion.onlyInB.length})</h6>${lista(seccion.onlyInB)}</div>
+      <div class="col-md-4"><h6>Modificados (${seccion.changed.length})</h6>${cambios}</div>
+    </div>`;
+}
+
+// Pinta el resultado completo de la comparación
+function renderCompare(comparacion) {
+  const resultado = document.getElementById("resultado");
+  const etiquetaA = `Solo en ${comparacion.domainA}`;
+  const etiquetaB = `Solo en ${comparacion.domainB}`;
+  resultado.innerHTML = `
+    <p>Fuente: ${comparacion.source === "live" ? "descarga en vivo" : "snapshots guardados"}</p>
+    ${comparacion.rulesByType ? '<p class="text-muted">Las reglas de los workflows se comparan solo por tipo: su configuración guarda IDs propios de cada sitio.</p>' : ""}
+    ${renderSection("Estados", comparacion.statuses, etiquetaA, etiquetaB)}
+    ${renderSection("Proyectos", comparacion.projects, etiquetaA, etiquetaB)}
+    ${renderSection("Categorías de proyecto", comparacion.projectCategories, etiquetaA, etiquetaB)}
+    ${renderSection("Workflows", comparacion.workflows, etiquetaA, etiquetaB)}`;
+}
+
+// Función de inicialización para compare.html
+export async function initCompare() {
+  const conexionA = document.getElementById("conexionA");
+  const conexionB = document.getElementById("conexionB");
+  await fillConnectionSelect(conexionA);
+  await fillConnectionSelect(conexionB);
+
+  document.getElementById("compareForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    const params = new URLSearchParams({ a: conexionA.value, b: conexionB.value });
+    if (document.getElementById("enVivo").checked) params.set("fuente", "live");
+    document.getElementById("resultado").textContent = "Comparando...";
+    try {
+      const res = await fetch(`/getcompare?${params}`);
+      if (!res.ok) {
+        document.getElementById("resultado").textContent = await res.text();
+        return;
+      }
+      renderCompare(await res.json());
+    } catch (error) {
+      console.error("Error al comparar conexiones:", error);
+      alert("Error al comparar conexiones: " + error);
+    }
+  });
+}
@@ -0,0 +1,56 @@
+// Pinta una tabla por cada grupo de estados duplicados
+function renderDuplicateStates(grupos) {
+  const resultado = document.getElementById("resultado");
+  resultado.innerHTML = "";
+
+  const resumen = document.createElement("p");
+  resumen.textContent = `${grupos.length} grupos de estados duplicados.`;
+  resultado.appendChild(resumen);
+
+  grupos.forEach(grupo => {
+    const heading = document.createElement("h4");
+    heading.textContent = `${grupo.canonicalName} (${grupo.variants.length} variantes)`;
+    resultado.appendChild(heading);
+
+    const table = document.createElement("table");
+    table.classList.add("table", "table-striped");
+    const thead = document.createElement("thead");
+    thead.innerHTML = `<tr>
+      <th>ID</th>
+      <th>Nombre</th>
+      <th>Categoría</th>
+      <th>Ámbito</th>
+      <th>Workflows</th>
+    </tr>`;
+    table.appendChild(thead);
+
+    const tbody = document.createElement("tbody");
+    grupo.variants.forEach(variante => {
+      const tr = document.createElement("tr");
+      const canonico = variante.id === grupo.canonicalId ? " <span class=\"badge bg-success\">canónico</span>" : "";
+      tr.innerHTML = `<td>${variante.id}</td>
+                      <td>${variante.name}${canonico}</td>
+                      <td>${variante.statusCategory || ""}</td>
+                      <td>${variante.scope}</td>
+                      <td>${variante.workflows.join(", ")}</td>`;
+      tbody.appendChild(tr);
+    });
+    table.appendChild(tbody);
+    resultado.appendChild(table);
+  });
+}
+
+// Función de inicialización para duplicate_states.html
+export async function initDuplicateStates() {
+  try {
+    const res = await fetch("/getduplicatestates");
+    if (!res.ok) {
+      document.getElementById("resultado").textContent = await res.text();
+      return;
+    }
+    renderDuplicateStates(await res.json());
+  } catch (error) {
+    console.error("Error al cargar los estados duplicados:", error);
+    alert("Error al cargar los estados duplicados: " + error);
+  }
+}
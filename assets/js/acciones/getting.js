@@ -22,6 +22,15 @@ async function getStoredCredentials() {
   }
 }
 
+// Cuenta las condiciones simples de un árbol de condiciones de transición
+function contarCondiciones(nodo) {
+  if (!nodo) return 0;
+  if (nodo.nodeType === "compound") {
+    return (nodo.conditions || []).reduce((total, hijo) => total + contarCondiciones(hijo), 0);
+  }
+  return 1;
+}
+
 // Función para renderizar las tablas con los datos obtenidos
 function renderDataTables(data) {
   const resultado = document.getElementById("resultado");
@@ -40,15 +49,26 @@ function renderDataTables(data) {
       <th>ID</th>
       <th>Nombre</th>
       <th>Descripción</th>
+      <th>Categoría</th>
+      <th>Ámbito</th>
+      <th>Uso</th>
     </tr>`;
     estadosTable.appendChild(theadEstados);
 
     const tbodyEstados = document.createElement("tbody");
     data.estados.forEach(estado => {
+      // Ámbito global o proyecto team-managed al que pertenece el estado
+      const ambito = estado.scope && estado.scope.type === "PROJECT"
+        ? `Proyecto ${estado.scope.project ? estado.scope.project.id : ""}`
+        : "Global";
+      const uso = `${(estado.usages || []).length} proyectos, ${(estado.workflowUsages || []).length} workflows`;
       const tr = document.createElement("tr");
       tr.innerHTML = `<td>${estado.id}</td>
                       <td>${estado.name}</td>
-                      <td>${estado.description || ""}</td>`;
+                      <td>${estado.description || ""}</td>
+                      <td>${estado.statusCategory || ""}</td>
+                      <td>${ambito}</td>
+                      <td>${uso}</td>`;
       tbodyEstados.appendChild(tr);
     });
     estadosTable.appendChild(tbodyEstados);
@@ -67,6 +87,10 @@ function renderDataTables(data) {
     theadProyectos.innerHTML = `<tr>
       <th>Clave</th>
       <th>Nombre</th>
+      <th>Categoría</th>
+      <th>Tipo</th>
+      <th>Responsable</th>
+      <th>Archivado</th>
     </tr>`;
     proyectosTable.appendChild(theadProyectos);
 
@@ -74,7 +98,11 @@ function renderDataTables(data) {
     data.proyectos.forEach(proyecto => {
       const tr = document.createElement("tr");
       tr.innerHTML = `<td>${proyecto.key}</td>
-                      <td>${proyecto.name}</td>`;
+                      <td>${proyecto.name}</td>
+                      <td>${proyecto.projectCategory ? proyecto.projectCategory.name : ""}</td>
+                      <td>${proyecto.projectTypeKey || ""}</td>
+                      <td>${proyecto.lead ? proyecto.lead.displayName : ""}</td>
+                      <td>${proyecto.archived ? "Sí" : ""}</td>`;
       tbodyProyectos.appendChild(tr);
     });
     proyectosTable.appendChild(tbodyProyectos);
@@ -101,12 +129,25 @@ function renderDataTables(data) {
       const tr = document.createElement("tr");
       // Se asume que workflow.id es un objeto con el nombre del workflow en "name"
       const workflowName = workflow.id.name || "";
-      // Procesar cada transición para mostrar sus estados de origen y destino
+      // Nombres de los estados del workflow para no mostrar solo IDs
+      const nombresEstado = {};
+      (workflow.statuses || []).forEach(st => { nombresEstado[st.id] = st.name; });
+      const nombre = id => nombresEstado[id] || id;
+      // Procesar cada transición para mostrar sus estados de origen y destino y sus reglas
       let transitionsHtml = "";
       if (workflow.transitions && Array.isArray(workflow.transitions)) {
         transitionsHtml = '<ul>' + workflow.transitions.map(t => {
-          const fromStates = (t.from && t.from.length > 0) ? t.from.join(", ") : "Inicio";
-          return `<li>Desde: ${fromStates} → Hasta: ${t.to}</li>`;
+          let fromStates = (t.from && t.from.length > 0) ? t.from.map(nombre).join(", ") : "Inicio";
+          if (t.type === "global") fromStates = "Cualquier estado";
+          const reglas = t.rules || {};
+          const condiciones = contarCondiciones(reglas.conditionsTree) + (reglas.conditions || []).length;
+          const detalle = [
+            t.screen ? `pantalla ${t.screen.name || t.screen.id}` : "",
+            `${condiciones} condiciones`,
+            `${(reglas.validators || []).length} validadores`,
+            `${(reglas.postFunctions || []).length} post functions`
+          ].filter(Boolean).join(", ");
+          return `<li><strong>${t.name || t.id || ""}</strong> [${t.type || ""}] Desde: ${fromStates} → Hasta: ${nombre(t.to)} <small>(${detalle})</small></li>`;
         }).join("") + '</ul>';
       }
       tr.innerHTML = `<td>${workflowName}</td>
@@ -116,6 +157,255 @@ function renderDataTables(data) {
     workflowsTable.appendChild(tbodyWorkflows);
     resultado.appendChild(workflowsTable);
   }
+
+  // Tabla para "Tipos de incidencia"
+  if (data.tiposIncidencia) {
+    const headingTipos = document.createElement("h2");
+    headingTipos.textContent = "Tipos de incidencia";
+    resultado.appendChild(headingTipos);
+
+    // Clave de proyecto por ID para mostrar claves en lugar de IDs
+    const clavesProyecto = {};
+    (data.proyectos || []).forEach(p => { clavesProyecto[p.id] = p.key; });
+    const esquemas = data.esquemasTiposIncidencia || [];
+
+    const tiposTable = document.createElement("table");
+    tiposTable.classList.add("table", "table-striped");
+    const theadTipos = document.createElement("thead");
+    theadTipos.innerHTML = `<tr>
+      <th>ID</th>
+      <th>Nombre</th>
+      <th>Esquemas</th>
+      <th>Proyectos</th>
+    </tr>`;
+    tiposTable.appendChild(theadTipos);
+
+    const tbodyTipos = document.createElement("tbody");
+    data.tiposIncidencia.forEach(tipo => {
+      // Esquemas que contienen el tipo y proyectos que usan esos esquemas
+      const esquemasTipo = esquemas.filter(e => (e.issueTypeIds || []).includes(tipo.id));
+      const proyectosTipo = esquemasTipo.flatMap(e => (e.projectIds || []).map(id => clavesProyecto[id] || id));
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${tipo.id}</td>
+                      <td>${tipo.name}${tipo.subtask ? " (subtarea)" : ""}</td>
+                      <td>${esquemasTipo.map(e => e.name).join(", ")}</td>
+                      <td>${proyectosTipo.join(", ")}</td>`;
+      tbodyTipos.appendChild(tr);
+    });
+    tiposTable.appendChild(tbodyTipos);
+    resultado.appendChild(tiposTable);
+  }
+
+  // Tabla para "Campos"
+  if (data.campos) {
+    const headingCampos = document.createElement("h2");
+    headingCampos.textContent = "Campos";
+    resultado.appendChild(headingCampos);
+
+    const camposTable = document.createElement("table");
+    camposTable.classList.add("table", "table-striped");
+    const theadCampos = document.createElement("thead");
+    theadCampos.innerHTML = `<tr>
+      <th>ID</th>
+      <th>Nombre</th>
+      <th>Tipo</th>
+      <th>Contextos</th>
+    </tr>`;
+    camposTable.appendChild(theadCampos);
+
+    const tbodyCampos = document.createElement("tbody");
+    data.campos.forEach(campo => {
+      const tipo = campo.schema ? (campo.schema.custom || campo.schema.type) : "";
+      // Cada contexto muestra su alcance y, si las tiene, el número de opciones
+      let contextosHtml = "";
+      if (campo.contexts && campo.contexts.length > 0) {
+        contextosHtml = '<ul>' + campo.contexts.map(c => {
+          const proyectos = c.isGlobalContext ? "Global" : `${(c.projectIds || []).length} proyectos`;
+          const tipos = c.isAnyIssueType ? "todos los tipos" : `${(c.issueTypeIds || []).length} tipos`;
+          const opciones = c.options ? ` - ${c.options.length} opciones` : "";
+          return `<li>${c.name}: ${proyectos}, ${tipos}${opciones}</li>`;
+        }).join("") + '</ul>';
+      }
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${campo.id}</td>
+                      <td>${campo.name}</td>
+                      <td>${tipo}</td>
+                      <td>${contextosHtml}</td>`;
+      tbodyCampos.appendChild(tr);
+    });
+    camposTable.appendChild(tbodyCampos);
+    resultado.appendChild(camposTable);
+  }
+
+  // Tabla para "Pantallas"
+  if (data.pantallas) {
+    const headingPantallas = document.createElement("h2");
+    headingPantallas.textContent = "Pantallas";
+    resultado.appendChild(headingPantallas);
+
+    const pantallasTable = document.createElement("table");
+    pantallasTable.classList.add("table", "table-striped");
+    const theadPantallas = document.createElement("thead");
+    theadPantallas.innerHTML = `<tr>
+      <th>ID</th>
+      <th>Pantalla</th>
+      <th>Campos por pestaña</th>
+    </tr>`;
+    pantallasTable.appendChild(theadPantallas);
+
+    const tbodyPantallas = document.createElement("tbody");
+    data.pantallas.forEach(pantalla => {
+      let pestanasHtml = "";
+      if (pantalla.tabs && pantalla.tabs.length > 0) {
+        pestanasHtml = '<ul>' + pantalla.tabs.map(t => {
+          const campos = (t.fields || []).map(f => f.name).join(", ");
+          return `<li><strong>${t.name}</strong>: ${campos}</li>`;
+        }).join("") + '</ul>';
+      }
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${pantalla.id}</td>
+                      <td>${pantalla.name}</td>
+                      <td>${pestanasHtml}</td>`;
+      tbodyPantallas.appendChild(tr);
+    });
+    pantallasTable.appendChild(tbodyPantallas);
+    resultado.appendChild(pantallasTable);
+  }
+
+  // Tabla para "Esquemas de pantallas" con los proyectos que los comparten
+  if (data.esquemasPantallas) {
+    const headingEsquemas = document.createElement("h2");
+    headingEsquemas.textContent = "Esquemas de pantallas";
+    resultado.appendChild(headingEsquemas);
+
+    const clavesProyecto = {};
+    (data.proyectos || []).forEach(p => { clavesProyecto[p.id] = p.key; });
+    const nombresPantalla = {};
+    (data.pantallas || []).forEach(p => { nombresPantalla[p.id] = p.name; });
+    const esquemasTipos = data.esquemasPantallasTipos || [];
+
+    const esquemasTable = document.createElement("table");
+    esquemasTable.classList.add("table", "table-striped");
+    const theadEsquemas = document.createElement("thead");
+    theadEsquemas.innerHTML = `<tr>
+      <th>Esquema</th>
+      <th>Pantallas</th>
+      <th>Proyectos</th>
+    </tr>`;
+    esquemasTable.appendChild(theadEsquemas);
+
+    const tbodyEsquemas = document.createElement("tbody");
+    data.esquemasPantallas.forEach(esquema => {
+      const pantallas = Object.entries(esquema.screens || {})
+        .map(([operacion, id]) => `${operacion}: ${nombresPantalla[id] || id}`)
+        .join("<br>");
+      // Un esquema de pantallas llega a los proyectos a través de los esquemas por tipo que lo mapean
+      const proyectos = new Set();
+      esquemasTipos
+        .filter(e => (e.mappings || []).some(m => m.screenSchemeId === String(esquema.id)))
+        .forEach(e => (e.projectIds || []).forEach(id => proyectos.add(clavesProyecto[id] || id)));
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${esquema.name}</td>
+                      <td>${pantallas}</td>
+                      <td>${[...proyectos].join(", ")}</td>`;
+      tbodyEsquemas.appendChild(tr);
+    });
+    esquemasTable.appendChild(tbodyEsquemas);
+    resultado.appendChild(esquemasTable);
+  }
+
+  // Tabla para "Permisos por proyecto"
+  if (data.permisosProyectos) {
+    const headingPermisos = document.createElement("h2");
+    headingPermisos.textContent = "Permisos por proyecto";
+    resultado.appendChild(headingPermisos);
+
+    const permisosTable = document.createElement("table");
+    permisosTable.classList.add("table", "table-striped");
+    const theadPermisos = document.createElement("thead");
+    theadPermisos.innerHTML = `<tr>
+      <th>Proyecto</th>
+      <th>Esquema</th>
+      <th>Permisos</th>
+    </tr>`;
+    permisosTable.appendChild(theadPermisos);
+
+    const tbodyPermisos = document.createElement("tbody");
+    data.permisosProyectos.forEach(proyecto => {
+      const permisosHtml = '<details><summary>Ver permisos</summary><ul>' +
+        Object.keys(proyecto.permissions || {}).sort().map(permiso =>
+          `<li><strong>${permiso}</strong>: ${proyecto.permissions[permiso].join(", ")}</li>`
+        ).join("") + '</ul></details>';
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${proyecto.projectKey} - ${proyecto.projectName}</td>
+                      <td>${proyecto.schemeName}</td>
+                      <td>${proyecto.error ? `<span class="text-danger">${proyecto.error}</span>` : permisosHtml}</td>`;
+      tbodyPermisos.appendChild(tr);
+    });
+    permisosTable.appendChild(tbodyPermisos);
+    resultado.appendChild(permisosTable);
+  }
+
+  // Tabla para "Workflows por proyecto"
+  if (data.workflowsProyectos) {
+    const headingWfProyectos = document.createElement("h2");
+    headingWfProyectos.textContent = "Workflows por proyecto";
+    resultado.appendChild(headingWfProyectos);
+
+    const wfProyectosTable = document.createElement("table");
+    wfProyectosTable.classList.add("table", "table-striped");
+    const theadWfProyectos = document.createElement("thead");
+    theadWfProyectos.innerHTML = `<tr>
+      <th>Proyecto</th>
+      <th>Esquema de workflows</th>
+      <th>Tipo → Workflow (estados)</th>
+    </tr>`;
+    wfProyectosTable.appendChild(theadWfProyectos);
+
+    const tbodyWfProyectos = document.createElement("tbody");
+    data.workflowsProyectos.forEach(proyecto => {
+      const tiposHtml = '<ul>' + (proyecto.issueTypes || []).map(t => {
+        const workflow = t.workflow || "Sin esquema (team-managed)";
+        const estados = (t.statuses || []).join(", ");
+        return `<li><strong>${t.issueTypeName || t.issueTypeId}</strong> → ${workflow}${estados ? ` (${estados})` : ""}</li>`;
+      }).join("") + '</ul>';
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${proyecto.projectKey} - ${proyecto.projectName}</td>
+                      <td>${proyecto.schemeName || ""}</td>
+                      <td>${tiposHtml}</td>`;
+      tbodyWfProyectos.appendChild(tr);
+    });
+    wfProyectosTable.appendChild(tbodyWfProyectos);
+    resultado.appendChild(wfProyectosTable);
+  }
+
+  // Tabla para "Categorías de proyecto"
+  if (data.categoriasProyecto) {
+    const headingCategorias = document.createElement("h2");
+    headingCategorias.textContent = "Categorías de proyecto";
+    resultado.appendChild(headingCategorias);
+
+    const categoriasTable = document.createElement("table");
+    categoriasTable.classList.add("table", "table-striped");
+    const theadCategorias = document.createElement("thead");
+    theadCategorias.innerHTML = `<tr>
+      <th>ID</th>
+      <th>Nombre</th>
+      <th>Descripción</th>
+    </tr>`;
+    categoriasTable.appendChild(theadCategorias);
+
+    const tbodyCategorias = document.createElement("tbody");
+    data.categoriasProyecto.forEach(categoria => {
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${categoria.id}</td>
+                      <td>${categoria.name}</td>
+                      <td>${categoria.description || ""}</td>`;
+      tbodyCategorias.appendChild(tr);
+    });
+    categoriasTable.appendChild(tbodyCategorias);
+    resultado.appendChild(categoriasTable);
+  }
 }
 
 // Función para asignar el listener al formulario y ejecutar la consulta a Jira
@@ -137,6 +427,13 @@ export async function submitJiraFormData() {
     const proyectos = document.getElementById("proyectos").checked;
     const workflows = document.getElementById("workflows").checked;
     const estados = document.getElementById("estados").checked;
+    const tiposIncidencia = document.getElementById("tiposIncidencia").checked;
+    const campos = document.getElementById("campos").checked;
+    const pantallas = document.getElementById("pantallas").checked;
+    const permisos = document.getElementById("permisos").checked;
+    const workflowsProyectos = document.getElementById("workflowsProyectos").checked;
+    const categoriasProyecto = document.getElementById("categoriasProyecto").checked;
+    const proyectosArchivados = document.getElementById("proyectosArchivados").checked;
 
     const bodyData = {
       domain: creds.domain,
@@ -144,7 +441,14 @@ export async function submitJiraFormData() {
       token: creds.token,
       Proyectos: proyectos,
       Workflows: workflows,
-      Estados: estados
+      Estados: estados,
+      TiposIncidencia: tiposIncidencia,
+      Campos: campos,
+      Pantallas: pantallas,
+      Permisos: permisos,
+      WorkflowsProyectos: workflowsProyectos,
+      CategoriasProyecto: categoriasProyecto,
+      ProyectosArchivados: proyectosArchivados
     };
 
     try {
@@ -0,0 +1,137 @@
+let entradas = [];
+
+const operaciones = {
+  crearEstados: "Crear estados",
+  actualizarEstados: "Editar estados",
+  borrarEstados: "Borrar estados",
+  crearWorkflow: "Crear workflow",
+  actualizarWorkflow: "Actualizar workflow",
+  borrarWorkflow: "Borrar workflow",
+  crearCategoriaProyecto: "Crear categoría",
+  actualizarCategoriaProyecto: "Editar categoría",
+  borrarCategoriaProyecto: "Borrar categoría"
+};
+
+// Estado de un objeto del diario tal y como se muestra en la tabla
+function estadoItem(item) {
+  if (!item.applied) return '<span class="text-danger">No aplicado</span>';
+  if (item.revertedBy) return `<span class="text-muted">Deshecho (${item.revertedBy})</span>`;
+  return '<span class="text-success">Aplicado</span>';
+}
+
+// Pinta las entradas agrupadas por lote, del más reciente al más antiguo
+function renderDiario() {
+  const contenedor = document.getElementById("diario");
+  contenedor.innerHTML = "";
+  if (entradas.length === 0) {
+    contenedor.textContent = "No hay escrituras registradas para esta conexión.";
+    return;
+  }
+
+  const lotes = new Map();
+  entradas.forEach((entrada, index) => {
+    if (!lotes.has(entrada.batch)) lotes.set(entrada.batch, []);
+    lotes.get(entrada.batch).push(index);
+  });
+
+  lotes.forEach((indices, lote) => {
+    const pendientes = indices.some(i => entradas[i].items.some(item => item.applied && !item.revertedBy));
+    const card = document.createElement("div");
+    card.classList.add("card", "mb-3");
+    let filas = "";
+    indices.forEach(i => {
+      const entrada = entradas[i];
+      const origen = entrada.revertOf ? ` <span class="badge bg-secondary">deshace ${entrada.revertOf}</span>` : "";
+      const error = entrada.error ? `<div class="text-danger small">${entrada.error}</div>` : "";
+      entrada.items.forEach((item, j) => {
+        const boton = item.applied && !item.revertedBy
+          ? `<button type="button" class="btn btn-sm btn-outline-danger" data-entry="${entrada.id}" data-item="${j}">Deshacer</button>` : "";
+        filas += `<tr>
+                    <td>${new Date(entrada.executedAt).toLocaleString()}</td>
+                    <td>${operaciones[entrada.operation] || entrada.operation}${origen}</td>
+                    <td>${item.name || ""}</td>
+                    <td>${item.id || ""}</td>
+                    <td>${estadoItem(item)}</td>
+                    <td>${boton}</td>
+                  </tr>`;
+      });
+      if (entrada.items.length === 0) {
+        filas += `<tr><td>${new Date(entrada.executedAt).toLocaleString()}</td><td>${operaciones[entrada.operation] || entrada.operation}${origen}</td><td colspan="4">${error}</td></tr>`;
+      } else if (error) {
+        filas += `<tr><td colspan="6">${error}</td></tr>`;
+      }
+    });
+    card.innerHTML = `<div class="card-body">
+        <div class="d-flex justify-content-between align-items-center mb-2">
+          <h6 class="mb-0">Lote ${lote}</h6>
+          ${pendientes ? `<button type="button" class="btn btn-sm btn-danger" data-batch="${lote}">Deshacer lote</button>` : ""}
+        </div>
+        <table class="table table-sm table-striped mb-0">
+          <thead><tr><th>Fecha</th><th>Operación</th><th>Objeto</th><th>ID</th><th>Estado</th><th></th></tr></thead>
+          <tbody>${filas}</tbody>
+        </table>
+      </div>`;
+    contenedor.appendChild(card);
+  });
+}
+
+// Pinta el resultado de una reversión
+function renderResultado(resultado) {
+  const clases = { reverted: "text-success", skipped: "text-muted", error: "text-danger" };
+  document.getElementById("resultado").innerHTML = `<div class="card mb-3"><div class="card-body">
+      <h6>Reversión ${resultado.batch}: ${resultado.reverted} deshechos, ${resultado.skipped} omitidos, ${resultado.failed} con error</h6>
+      <ul class="mb-0">` + (resultado.items || []).map(i =>
+        `<li class="${clases[i.result] || ""}">${operaciones[i.operation] || i.operation} · ${i.name}: ${i.message || i.result}</li>`).join("") +
+    `</ul></div></div>`;
+}
+
+// Envía la petición de deshacer y recarga el diario
+async function deshacer(peticion, descripcion) {
+  if (!confirm(`¿Deshacer ${descripcion} en Jira?`)) return;
+  document.getElementById("resultado").textContent = "Deshaciendo cambios en Jira...";
+  try {
+    const res = await fetch("/revertjournal", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(peticion)
+    });
+    if (!res.ok) {
+      document.getElementById("resultado").textContent = await res.text();
+      return;
+    }
+    renderResultado(await res.json());
+    await cargarDiario();
+  } catch (error) {
+    console.error("Error al deshacer:", error);
+    alert("Error al deshacer: " + error);
+  }
+}
+
+// Carga el diario de la conexión activa
+async function cargarDiario() {
+  const res = await fetch("/getjournal");
+  if (!res.ok) {
+    document.getElementById("diario").textContent = await res.text();
+    return;
+  }
+  entradas = await res.json();
+  renderDiario();
+}
+
+// Función de inicialización para journal.html
+export async function initJournal() {
+  document.getElementById("diario").addEventListener("click", e => {
+    const { batch, entry, item } = e.target.dataset;
+    if (batch) {
+      deshacer({ batch }, `todos los cambios del lote ${batch}`);
+    } else if (entry) {
+      deshacer({ entry, item: Number(item) }, "este cambio");
+    }
+  });
+
+  try {
+    await cargarDiario();
+  } catch (error) {
+    console.error("Error al cargar el diario:", error);
+  }
+}
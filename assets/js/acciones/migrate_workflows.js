@@ -0,0 +1,121 @@
+import { fillConnectionSelect } from "/assets/js/acciones/workflow_diff.js";
+
+// Asignaciones manuales: nombre del estado en origen -> estado del destino (nombre o ID)
+let overrides = {};
+
+const acciones = { map: "Mismo nombre", override: "Asignado a mano", create: "Se crea", error: "Sin equivalente" };
+const clasesResultado = { created: "text-success", mapped: "text-success", skipped: "text-muted", error: "text-danger" };
+
+// Rellena el select de workflows con los del snapshot de la conexión de origen
+async function fillWorkflows() {
+  const select = document.getElementById("workflows");
+  select.innerHTML = "";
+  const res = await fetch(`/getworkflownames?conexion=${document.getElementById("origen").value}`);
+  if (!res.ok) {
+    select.innerHTML = `<option value="">Sin workflows en el snapshot</option>`;
+    return;
+  }
+  const nombres = await res.json();
+  select.innerHTML = nombres.map(n => `<option value="${n}">${n}</option>`).join("");
+}
+
+// Pinta la correspondencia de estados y los workflows, con el botón de copiar si procede
+function renderResultado(resultado) {
+  const contenedor = document.getElementById("resultado");
+  const filasEstados = resultado.statuses.map((m, index) => `<tr>
+      <td>${m.sourceName} <span class="text-muted">(${m.sourceId})</span></td>
+      <td>${m.statusCategory || ""}</td>
+      <td>${acciones[m.action] || m.action}</td>
+      <td>${m.targetName || ""}${m.targetId ? ` <span class="text-muted">(${m.targetId})</span>` : ""}</td>
+      <td>${resultado.applied ? "" : `<input type="text" class="form-control form-control-sm" data-index="${index}" value="${overrides[m.sourceName] || ""}" placeholder="Automático">`}</td>
+      <td>${m.workflows.join(", ")}</td>
+      <td class="${clasesResultado[m.result] || ""}">${m.result}${m.message ? ": " + m.message : ""}</td>
+    </tr>`).join("");
+  const lista = (elementos, clase) => elementos.length === 0 ? "" :
+    `<ul class="${clase} mb-0">` + elementos.map(e => `<li>${e}</li>`).join("") + '</ul>';
+  const filasWorkflows = resultado.workflows.map(wf => `<tr>
+      <td>${wf.name}</td>
+      <td>${wf.action === "create" ? "Se crea" : "Se omite"}</td>
+      <td>${lista(wf.errors, "text-danger")}${lista(wf.warnings, "text-warning")}</td>
+      <td class="${clasesResultado[wf.result] || ""}">${wf.result}${wf.message ? ": " + wf.message : ""}</td>
+    </tr>`).join("");
+  const copiables = resultado.workflows.filter(wf => wf.action === "create").length;
+
+  contenedor.innerHTML = `
+    <h4>${resultado.applied ? "Resultado" : "Previsualización"}: ${resultado.sourceDomain} → ${resultado.targetDomain}</h4>
+    ${resultado.batch ? `<p>Escrituras registradas en el <a href="/journal">diario</a> como lote ${resultado.batch}.</p>` : ""}
+    <h5 class="mt-3">Estados</h5>
+    <table class="table table-sm table-striped">
+      <thead><tr><th>Origen</th><th>Categoría</th><th>Correspondencia</th><th>Destino</th><th>Asignar a</th><th>Workflows</th><th>Resultado</th></tr></thead>
+      <tbody>${filasEstados}</tbody>
+    </table>
+    <h5>Workflows</h5>
+    <table class="table table-sm table-striped">
+      <thead><tr><th>Workflow</th><th>Acción</th><th>Errores y avisos</th><th>Resultado</th></tr></thead>
+      <tbody>${filasWorkflows}</tbody>
+    </table>
+    ${resultado.applied ? "" : `<button type="button" id="recalcular" class="btn btn-secondary me-2">Recalcular con las asignaciones</button>`}
+    ${!resultado.applied && copiables > 0 ? `<button type="button" id="copiar" class="btn btn-primary">Copiar ${copiables} workflows al destino</button>` : ""}`;
+
+  if (resultado.applied) return;
+  contenedor.querySelectorAll("input[data-index]").forEach(input => {
+    input.addEventListener("change", () => {
+      const nombre = resultado.statuses[input.dataset.index].sourceName;
+      if (input.value.trim()) overrides[nombre] = input.value.trim();
+      else delete overrides[nombre];
+    });
+  });
+  document.getElementById("recalcular").addEventListener("click", () => migrar(false));
+  const copiar = document.getElementById("copiar");
+  if (copiar) {
+    copiar.addEventListener("click", () => {
+      if (!confirm(`¿Crear los estados que faltan y ${copiables} workflows en ${resultado.targetDomain}?`)) return;
+      copiar.disabled = true;
+      migrar(true);
+    });
+  }
+}
+
+// Pide la previsualización o, con aplicar, ejecuta la copia
+async function migrar(aplicar) {
+  const peticion = {
+    source: Number(document.getElementById("origen").value),
+    target: Number(document.getElementById("destino").value),
+    workflows: Array.from(document.getElementById("workflows").selectedOptions).map(o => o.value).filter(v => v),
+    overrides,
+    apply: aplicar
+  };
+  document.getElementById("resultado").textContent = aplicar ? "Copiando workflows en el destino..." : "Descargando el destino y preparando la copia...";
+  try {
+    const res = await fetch("/migrateworkflows", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(peticion)
+    });
+    if (!res.ok) {
+      document.getElementById("resultado").textContent = await res.text();
+      return;
+    }
+    renderResultado(await res.json());
+  } catch (error) {
+    console.error("Error al copiar workflows:", error);
+    alert("Error al copiar workflows: " + error);
+  }
+}
+
+// Función de inicialización para migrate_workflows.html
+export async function initMigrateWorkflows() {
+  const origen = document.getElementById("origen");
+  await fillConnectionSelect(origen);
+  await fillConnectionSelect(document.getElementById("destino"));
+  await fillWorkflows();
+  origen.addEventListener("change", () => {
+    overrides = {};
+    fillWorkflows();
+  });
+
+  document.getElementById("migrateForm").addEventListener("submit", (e) => {
+    e.preventDefault();
+    migrar(false);
+  });
+}
@@ -0,0 +1,135 @@
+let planes = [];
+
+const simbolos = { create: "+", update: "~", delete: "-" };
+const clasesAccion = { create: "text-success", update: "text-warning", delete: "text-danger" };
+
+// Pinta un plan con sus cambios, avisos y errores, y el botón de aplicar si procede
+function renderPlan(plan) {
+  const contenedor = document.getElementById("plan");
+  const enParte = !plan.appliedAt && plan.changes.some(c => c.result);
+  const aplicable = !plan.appliedAt && !enParte && plan.errors.length === 0 && plan.changes.length > 0;
+  const estado = plan.appliedAt ? `Aplicado el ${new Date(plan.appliedAt).toLocaleString()}` :
+    enParte ? "Aplicado en parte: genera un plan nuevo" : "Pendiente de aplicar";
+  const cambios = plan.changes.length === 0 ? "<p>Sin cambios: el sitio ya está en el estado deseado.</p>" :
+    '<ul class="font-monospace">' + plan.changes.map(c => {
+      const detalle = c.changes.length ? `: ${c.changes.join("; ")}` : "";
+      const resultado = c.result ? ` [${c.result}]${c.message ? " " + c.message : ""}` : "";
+      return `<li class="${clasesAccion[c.action] || ""}">${simbolos[c.action] || c.action} ${c.objectType} ${c.name}${detalle}${resultado}</li>`;
+    }).join("") + '</ul>';
+  const lista = (titulo, elementos, clase) => elementos.length === 0 ? "" :
+    `<h6>${titulo}</h6><ul class="${clase}">` + elementos.map(e => `<li>${e}</li>`).join("") + '</ul>';
+
+  contenedor.innerHTML = `<div class="card mb-3"><div class="card-body">
+      <h5>Plan ${plan.id} · ${plan.domain}</h5>
+      <p class="text-muted">${estado} · huella ${plan.fingerprint.slice(0, 12)}</p>
+      ${cambios}
+      ${lista("Errores", plan.errors, "text-danger")}
+      ${lista("Avisos", plan.warnings, "text-warning")}
+      ${aplicable ? '<button type="button" id="aplicarPlan" class="btn btn-primary">Aprobar y aplicar</button>' : ""}
+    </div></div>`;
+
+  if (!aplicable) return;
+  document.getElementById("aplicarPlan").addEventListener("click", async () => {
+    if (!confirm(`¿Aplicar los ${plan.changes.length} cambios del plan ${plan.id} en ${plan.domain}?`)) return;
+    document.getElementById("aplicarPlan").disabled = true;
+    try {
+      const res = await fetch("/applyplan", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ id: plan.id, approve: true })
+      });
+      if (!res.ok) {
+        alert(await res.text());
+        // Si se aplicó en parte, el plan guardado ya tiene el resultado de cada cambio
+        await cargarPlanes();
+        const guardado = planes.find(p => p.id === plan.id);
+        if (guardado && guardado.changes.some(c => c.result)) {
+          renderPlan(guardado);
+        } else {
+          document.getElementById("aplicarPlan").disabled = false;
+        }
+        return;
+      }
+      renderPlan(await res.json());
+      await cargarPlanes();
+    } catch (error) {
+      console.error("Error al aplicar el plan:", error);
+      alert("Error al aplicar el plan: " + error);
+    }
+  });
+}
+
+// Pinta la tabla de planes guardados
+function renderPlanes() {
+  const contenedor = document.getElementById("planes");
+  contenedor.innerHTML = "";
+  if (planes.length === 0) {
+    contenedor.textContent = "No hay planes guardados para esta conexión.";
+    return;
+  }
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  table.innerHTML = `<thead><tr><th>ID</th><th>Cambios</th><th>Errores</th><th>Estado</th><th></th></tr></thead>`;
+  const tbody = document.createElement("tbody");
+  planes.forEach((plan, index) => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td>${plan.id}</td>
+                    <td>${plan.changes.length}</td>
+                    <td>${plan.errors.length}</td>
+                    <td>${plan.appliedAt ? "Aplicado" : plan.changes.some(c => c.result) ? "Aplicado en parte" : "Pendiente"}</td>
+                    <td><button type="button" class="btn btn-sm btn-outline-primary" data-index="${index}">Ver</button></td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  contenedor.appendChild(table);
+  tbody.addEventListener("click", e => {
+    const index = e.target.dataset.index;
+    if (index !== undefined) renderPlan(planes[index]);
+  });
+}
+
+// Carga los planes guardados de la conexión activa
+async function cargarPlanes() {
+  const res = await fetch("/getplans");
+  if (!res.ok) {
+    document.getElementById("planes").textContent = await res.text();
+    return;
+  }
+  planes = await res.json();
+  renderPlanes();
+}
+
+// Función de inicialización para plan.html
+export async function initPlan() {
+  document.getElementById("ficheroDeseado").addEventListener("change", async (e) => {
+    const fichero = e.target.files[0];
+    if (fichero) document.getElementById("contenido").value = await fichero.text();
+  });
+
+  document.getElementById("planForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    document.getElementById("plan").textContent = "Descargando la configuración en vivo y calculando el plan...";
+    try {
+      const res = await fetch("/createplan", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ content: document.getElementById("contenido").value })
+      });
+      if (!res.ok) {
+        document.getElementById("plan").textContent = await res.text();
+        return;
+      }
+      renderPlan(await res.json());
+      await cargarPlanes();
+    } catch (error) {
+      console.error("Error al generar el plan:", error);
+      alert("Error al generar el plan: " + error);
+    }
+  });
+
+  try {
+    await cargarPlanes();
+  } catch (error) {
+    console.error("Error al cargar los planes:", error);
+  }
+}
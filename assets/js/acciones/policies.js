@@ -0,0 +1,63 @@
+let resultados = [];
+
+// Clase de Bootstrap para cada severidad
+const clasesSeveridad = {
+  error: "bg-danger",
+  warning: "bg-warning text-dark",
+  info: "bg-info text-dark"
+};
+
+// Pinta la tabla de incumplimientos filtrada por severidad
+function renderPolicies() {
+  const resultado = document.getElementById("resultado");
+  resultado.innerHTML = "";
+  const severidad = document.getElementById("severidad").value;
+  const filas = severidad ? resultados.filter(r => r.severity === severidad) : resultados;
+
+  const resumen = document.createElement("p");
+  const errores = resultados.filter(r => r.severity === "error").length;
+  resumen.textContent = `${resultados.length} incumplimientos (${errores} de severidad error).`;
+  resultado.appendChild(resumen);
+
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>
+    <th>Severidad</th>
+    <th>Regla</th>
+    <th>Tipo</th>
+    <th>Objeto</th>
+    <th>Mensaje</th>
+  </tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  filas.forEach(r => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td><span class="badge ${clasesSeveridad[r.severity] || "bg-secondary"}">${r.severity}</span></td>
+                    <td>${r.rule}</td>
+                    <td>${r.objectType}</td>
+                    <td><a href="${r.link}" target="_blank">${r.object}</a></td>
+                    <td>${r.message}</td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  resultado.appendChild(table);
+}
+
+// Función de inicialización para policies.html
+export async function initPolicies() {
+  document.getElementById("severidad").addEventListener("change", renderPolicies);
+  try {
+    const res = await fetch("/getpolicies");
+    if (!res.ok) {
+      document.getElementById("resultado").textContent = await res.text();
+      return;
+    }
+    resultados = await res.json();
+    renderPolicies();
+  } catch (error) {
+    console.error("Error al cargar las reglas:", error);
+    alert("Error al cargar las reglas: " + error);
+  }
+}
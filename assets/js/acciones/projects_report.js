@@ -0,0 +1,95 @@
+// Texto legible del estilo del proyecto
+function estiloProyecto(p) {
+  return p.simplified || p.style === "next-gen" ? "Team-managed" : "Company-managed";
+}
+
+// Tabla de proyectos con sus metadatos
+function tablaProyectos(proyectos) {
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>
+    <th></th>
+    <th>Clave</th>
+    <th>Nombre</th>
+    <th>Tipo</th>
+    <th>Estilo</th>
+    <th>Responsable</th>
+    <th>Archivado</th>
+    <th>Descripción</th>
+  </tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  proyectos.forEach(p => {
+    const avatar = (p.avatarUrls || {})["24x24"];
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td>${avatar ? `<img src="${avatar}" width="24" height="24" alt="">` : ""}</td>
+                    <td>${p.url ? `<a href="${p.url}" target="_blank">${p.key}</a>` : p.key}</td>
+                    <td>${p.name}</td>
+                    <td>${p.projectTypeKey || ""}</td>
+                    <td>${estiloProyecto(p)}</td>
+                    <td>${p.lead ? p.lead.displayName : ""}</td>
+                    <td>${p.archived ? "Sí" : ""}</td>
+                    <td>${p.description || ""}</td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  return table;
+}
+
+// Pinta el informe completo
+function renderProjectsReport(informe) {
+  document.getElementById("resumen").textContent =
+    `${informe.total} proyectos (${informe.archived} archivados, ${informe.teamManaged} team-managed), ` +
+    `${informe.byCategory.length} categorías y ${informe.withoutCategory.length} proyectos sin categoría.`;
+
+  const sinCategoria = document.getElementById("sinCategoria");
+  sinCategoria.innerHTML = "";
+  if (informe.withoutCategory.length === 0) {
+    sinCategoria.textContent = "Todos los proyectos tienen categoría.";
+  } else {
+    sinCategoria.appendChild(tablaProyectos(informe.withoutCategory));
+  }
+
+  const porResponsable = document.getElementById("porResponsable");
+  porResponsable.innerHTML = "";
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  table.innerHTML = `<thead><tr><th>Responsable</th><th>Proyectos</th><th>Claves</th></tr></thead>`;
+  const tbody = document.createElement("tbody");
+  informe.byLead.forEach(l => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td>${l.lead}</td>
+                    <td>${l.count}</td>
+                    <td>${l.projects.join(", ")}</td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  porResponsable.appendChild(table);
+
+  const porCategoria = document.getElementById("porCategoria");
+  porCategoria.innerHTML = "";
+  informe.byCategory.forEach(grupo => {
+    const heading = document.createElement("h5");
+    heading.classList.add("mt-3");
+    heading.textContent = `${grupo.category} (${grupo.projects.length})`;
+    porCategoria.appendChild(heading);
+    porCategoria.appendChild(tablaProyectos(grupo.projects));
+  });
+}
+
+// Función de inicialización para projects_report.html
+export async function initProjectsReport() {
+  try {
+    const res = await fetch("/getprojectreport");
+    if (!res.ok) {
+      document.getElementById("resumen").textContent = await res.text();
+      return;
+    }
+    renderProjectsReport(await res.json());
+  } catch (error) {
+    console.error("Error al cargar el informe de proyectos:", error);
+    alert("Error al cargar el informe de proyectos: " + error);
+  }
+}
@@ -0,0 +1,75 @@
+import { fillConnectionSelect } from "/assets/js/acciones/workflow_diff.js";
+import { renderSection } from "/assets/js/acciones/compare.js";
+
+// Lista los snapshots de la conexión elegida con selectores A/B
+async function listSnapshots() {
+  const conexion = document.getElementById("conexion").value;
+  const contenedor = document.getElementById("listaSnapshots");
+  const res = await fetch(`/getsnapshots?conexion=${conexion}`);
+  if (!res.ok) {
+    contenedor.textContent = await res.text();
+    return;
+  }
+  const snapshots = await res.json();
+  if (snapshots.length === 0) {
+    contenedor.innerHTML = "<p>No hay snapshots guardados para esta conexión.</p>";
+    return;
+  }
+
+  let html = `<table class="table table-striped"><thead><tr>
+    <th>A</th><th>B</th><th>Fecha</th><th>Secciones</th><th>Elementos</th><th></th>
+  </tr></thead><tbody>`;
+  snapshots.forEach((s, index) => {
+    const elementos = Object.entries(s.counts || {}).map(([k, v]) => `${k}: ${v}`).join(", ");
+    html += `<tr>
+      <td><input type="radio" name="snapshotA" value="${s.id}" ${index === 1 ? "checked" : ""}></td>
+      <td><input type="radio" name="snapshotB" value="${s.id}" ${index === 0 ? "checked" : ""}></td>
+      <td>${new Date(s.fetchedAt).toLocaleString()}</td>
+      <td>${s.sections.join(", ")}${s.origin === "write" ? ' <span class="badge bg-secondary">tras una escritura</span>' : ""}</td>
+      <td>${elementos}</td>
+      <td><a href="/getsnapshot?conexion=${conexion}&id=${s.id}" target="_blank">Ver JSON</a></td>
+    </tr>`;
+  });
+  html += "</tbody></table>";
+  contenedor.innerHTML = html;
+}
+
+// Compara los dos snapshots elegidos
+async function compareSnapshots() {
+  const a = document.querySelector("input[name=snapshotA]:checked");
+  const b = document.querySelector("input[name=snapshotB]:checked");
+  if (!a || !b) {
+    alert("Elige los snapshots A y B");
+    return;
+  }
+  const conexion = document.getElementById("conexion").value;
+  const resultado = document.getElementById("resultado");
+  try {
+    const res = await fetch(`/getsnapshotdiff?conexion=${conexion}&a=${a.value}&b=${b.value}`);
+    if (!res.ok) {
+      resultado.textContent = await res.text();
+      return;
+    }
+    const data = await res.json();
+    const c = data.comparacion;
+    resultado.innerHTML = `
+      <h4>${c.snapshotA} (${new Date(data.metadataA.fetchedAt).toLocaleString()}) → ${c.snapshotB} (${new Date(data.metadataB.fetchedAt).toLocaleString()})</h4>
+      ${c.skipped && c.skipped.length ? `<p class="text-muted">Secciones sin comparar: ${c.skipped.join(", ")}</p>` : ""}
+      ${renderSection("Estados", c.statuses, "Eliminados", "Añadidos")}
+      ${renderSection("Proyectos", c.projects, "Eliminados", "Añadidos")}
+      ${renderSection("Categorías de proyecto", c.projectCategories, "Eliminadas", "Añadidas")}
+      ${renderSection("Workflows", c.workflows, "Eliminados", "Añadidos")}`;
+  } catch (error) {
+    console.error("Error al comparar snapshots:", error);
+    alert("Error al comparar snapshots: " + error);
+  }
+}
+
+// Función de inicialización para snapshots.html
+export async function initSnapshots() {
+  const conexion = document.getElementById("conexion");
+  await fillConnectionSelect(conexion);
+  conexion.addEventListener("change", listSnapshots);
+  document.getElementById("compararSnapshots").addEventListener("click", compareSnapshots);
+  await listSnapshots();
+}
@@ -0,0 +1,156 @@
+let estados = [];
+
+const categorias = ["TODO", "IN_PROGRESS", "DONE"];
+
+// Escapa comillas y ángulos para poder poner texto de Jira o del usuario en el HTML, también
+// dentro de un atributo value
+function escapar(texto) {
+  return (texto || "").replace(/&/g, "&amp;").replace(/"/g, "&quot;").replace(/</g, "&lt;").replace(/>/g, "&gt;");
+}
+
+// Pinta la tabla editable de estados, aplicando el filtro actual
+function renderStatesTable() {
+  const resultado = document.getElementById("resultado");
+  resultado.innerHTML = "";
+  const filtro = document.getElementById("filtro").value.toLowerCase();
+
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped", "align-middle");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>
+    <th>ID</th>
+    <th>Nombre</th>
+    <th>Descripción</th>
+    <th>Categoría</th>
+    <th>Ámbito</th>
+  </tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  estados
+    .filter(e => !filtro || e.name.toLowerCase().includes(filtro) || e.id === filtro)
+    .forEach(e => {
+      const tr = document.createElement("tr");
+      tr.dataset.id = e.id;
+      // Las ediciones pendientes se conservan al volver a pintar la tabla (por ejemplo, al filtrar)
+      const valores = e.editado ?? e;
+      if (e.editado) tr.classList.add("table-warning");
+      const opciones = categorias
+        .map(c => `<option value="${c}" ${c === valores.statusCategory ? "selected" : ""}>${c}</option>`)
+        .join("");
+      const ambito = e.scope && e.scope.type === "PROJECT" ? `Proyecto ${escapar(e.scope.project ? e.scope.project.id : "")}` : "Global";
+      tr.innerHTML = `<td>${escapar(e.id)}</td>
+                      <td><input class="form-control form-control-sm" data-campo="name" value="${escapar(valores.name)}"></td>
+                      <td><input class="form-control form-control-sm" data-campo="description" value="${escapar(valores.description)}"></td>
+                      <td><select class="form-select form-select-sm" data-campo="statusCategory">${opciones}</select></td>
+                      <td>${ambito}</td>`;
+      tbody.appendChild(tr);
+    });
+  table.appendChild(tbody);
+  resultado.appendChild(table);
+
+  // Se guardan los valores editados en el propio estado para no perderlos al filtrar
+  tbody.addEventListener("input", guardarEdicion);
+  tbody.addEventListener("change", guardarEdicion);
+}
+
+// Guarda en memoria el valor editado y marca la fila como modificada
+function guardarEdicion(e) {
+  const campo = e.target.dataset.campo;
+  const tr = e.target.closest("tr");
+  if (!campo || !tr) return;
+  const estado = estados.find(st => st.id === tr.dataset.id);
+  estado.editado = estado.editado || { name: estado.name, description: estado.description || "", statusCategory: estado.statusCategory };
+  estado.editado[campo] = e.target.value;
+  tr.classList.add("table-warning");
+  document.getElementById("aplicar").disabled = true;
+}
+
+// Cambios pendientes en el formato que espera /bulkeditstatuses
+function cambiosPendientes() {
+  return estados
+    .filter(e => e.editado)
+    .map(e => ({ id: e.id, ...e.editado }));
+}
+
+// Pinta el diff devuelto por el backend
+function renderDiff(resultado) {
+  const diff = document.getElementById("diff");
+  const filas = resultado.diffs.filter(d => d.action !== "unchanged");
+  const resumen = resultado.dryRun
+    ? `${filas.filter(d => d.action === "update").length} estados a actualizar, ${resultado.invalid} inválidos.`
+    : `${resultado.updated} estados actualizados, ${resultado.failed} con error, ${resultado.invalid} inválidos.`;
+  diff.innerHTML = `<p>${resumen}</p>` + (filas.length === 0 ? "" :
+    '<ul>' + filas.map(d => {
+      const clase = d.action === "invalid" || d.result === "error" ? "text-danger" : "";
+      const detalle = d.changes.length ? escapar(d.changes.join("; ")) : "";
+      return `<li class="${clase}"><strong>${escapar(d.before.name || d.id)}</strong> (${escapar(d.id)}): ${detalle} ${escapar(d.message)} ${resultado.dryRun ? "" : `[${escapar(d.result)}]`}</li>`;
+    }).join("") + '</ul>');
+}
+
+// Envía los cambios al backend en modo dry-run o real
+async function enviarCambios(dryRun) {
+  const res = await fetch("/bulkeditstatuses", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ changes: cambiosPendientes(), dryRun })
+  });
+  if (!res.ok) {
+    document.getElementById("diff").textContent = await res.text();
+    return null;
+  }
+  const resultado = await res.json();
+  renderDiff(resultado);
+  return resultado;
+}
+
+// Carga los estados del snapshot
+async function cargarEstados() {
+  const res = await fetch("/getjson?key=estados");
+  if (!res.ok) {
+    document.getElementById("resultado").textContent = await res.text();
+    return;
+  }
+  estados = await res.json();
+  estados.sort((a, b) => a.name.localeCompare(b.name));
+  renderStatesTable();
+}
+
+// Función de inicialización para states.html
+export async function initStates() {
+  const aplicar = document.getElementById("aplicar");
+  document.getElementById("filtro").addEventListener("input", renderStatesTable);
+
+  document.getElementById("revisar").addEventListener("click", async () => {
+    if (cambiosPendientes().length === 0) {
+      document.getElementById("diff").textContent = "No hay cambios pendientes.";
+      return;
+    }
+    try {
+      const resultado = await enviarCambios(true);
+      aplicar.disabled = !resultado || !resultado.diffs.some(d => d.action === "update");
+    } catch (error) {
+      console.error("Error al revisar los cambios:", error);
+      alert("Error al revisar los cambios: " + error);
+    }
+  });
+
+  aplicar.addEventListener("click", async () => {
+    if (!confirm("¿Enviar a Jira los cambios revisados?")) return;
+    aplicar.disabled = true;
+    try {
+      const resultado = await enviarCambios(false);
+      if (resultado) await cargarEstados();
+    } catch (error) {
+      console.error("Error al aplicar los cambios:", error);
+      alert("Error al aplicar los cambios: " + error);
+    }
+  });
+
+  try {
+    await cargarEstados();
+  } catch (error) {
+    console.error("Error al cargar los estados:", error);
+    alert("Error al cargar los estados: " + error);
+  }
+}
@@ -0,0 +1,136 @@
+// Pinta la tabla con los estados sin uso y su evidencia
+function renderUnusedStates(estados) {
+  const resultado = document.getElementById("resultado");
+  resultado.innerHTML = "";
+
+  const resumen = document.createElement("p");
+  const seguros = estados.filter(e => e.safeToDelete).length;
+  resumen.textContent = `${estados.length} estados sin workflow, ${seguros} seguros de borrar.`;
+  resultado.appendChild(resumen);
+
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>
+    <th></th>
+    <th>ID</th>
+    <th>Nombre</th>
+    <th>Categoría</th>
+    <th>Ámbito</th>
+    <th>Seguro de borrar</th>
+    <th>Evidencia</th>
+  </tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  estados.forEach(estado => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td><input class="form-check-input candidato" type="checkbox" value="${estado.id}" data-seguro="${estado.safeToDelete}"></td>
+                    <td>${estado.id}</td>
+                    <td>${estado.name}</td>
+                    <td>${estado.statusCategory || ""}</td>
+                    <td>${estado.scope}</td>
+                    <td>${estado.safeToDelete ? "Sí" : "No"}</td>
+                    <td><ul>${estado.evidence.map(e => `<li>${e}</li>`).join("")}</ul></td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  resultado.appendChild(table);
+}
+
+// IDs de los estados marcados en la tabla
+function seleccionados() {
+  return [...document.querySelectorAll(".candidato:checked")].map(c => c.value);
+}
+
+// Envía los IDs a un endpoint de borrado y devuelve la respuesta JSON
+async function enviarIDs(url, ids, confirm = false) {
+  const res = await fetch(url, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ ids, confirm })
+  });
+  if (!res.ok) {
+    document.getElementById("confirmacion").textContent = await res.text();
+    return null;
+  }
+  return res.json();
+}
+
+// Lista de comprobaciones (o resultados) de cada estado
+function listaComprobaciones(comprobaciones) {
+  return '<ul>' + comprobaciones.map(c => {
+    const clase = c.deletable && c.result !== "error" ? "" : "text-danger";
+    const resultado = c.result && c.result !== "pending" ? ` [${c.result}]` : "";
+    return `<li class="${clase}">${c.name || "?"} (${c.id})${resultado}${c.reason ? ": " + c.reason : ""}</li>`;
+  }).join("") + '</ul>';
+}
+
+// Muestra la lista verificada en vivo y el botón de confirmación
+function renderConfirmacion(comprobaciones) {
+  const confirmacion = document.getElementById("confirmacion");
+  const borrables = comprobaciones.filter(c => c.deletable);
+  confirmacion.innerHTML = `<div class="alert alert-warning">
+      <p>${borrables.length} de ${comprobaciones.length} estados siguen sin uso en Jira. El resto se omitirá.</p>
+      ${listaComprobaciones(comprobaciones)}
+      <button type="button" id="confirmarBorrado" class="btn btn-danger" ${borrables.length === 0 ? "disabled" : ""}>Borrar ${borrables.length} estados</button>
+    </div>`;
+
+  document.getElementById("confirmarBorrado").addEventListener("click", async () => {
+    if (!confirm(`¿Borrar definitivamente ${borrables.length} estados de Jira?`)) return;
+    document.getElementById("confirmarBorrado").disabled = true;
+    try {
+      // Se vuelve a verificar en el servidor justo antes de borrar
+      const resultado = await enviarIDs("/deletestatuses", borrables.map(c => c.id), true);
+      if (!resultado) return;
+      confirmacion.innerHTML = `<div class="alert alert-info">
+          <p>${resultado.deleted} borrados, ${resultado.skipped} omitidos, ${resultado.failed} con error.</p>
+          ${listaComprobaciones(resultado.statuses)}
+        </div>`;
+      await cargarEstadosSinUso();
+    } catch (error) {
+      console.error("Error al borrar los estados:", error);
+      alert("Error al borrar los estados: " + error);
+    }
+  });
+}
+
+// Carga el informe de estados sin uso
+async function cargarEstadosSinUso() {
+  const res = await fetch("/getunusedstates");
+  if (!res.ok) {
+    document.getElementById("resultado").textContent = await res.text();
+    return;
+  }
+  renderUnusedStates(await res.json());
+}
+
+// Función de inicialización para unused_states.html
+export async function initUnusedStates() {
+  document.getElementById("seleccionarSeguros").addEventListener("click", () => {
+    document.querySelectorAll(".candidato").forEach(c => { c.checked = c.dataset.seguro === "true"; });
+  });
+
+  document.getElementById("verificar").addEventListener("click", async () => {
+    const ids = seleccionados();
+    if (ids.length === 0) {
+      document.getElementById("confirmacion").textContent = "No hay estados seleccionados.";
+      return;
+    }
+    document.getElementById("confirmacion").textContent = "Verificando en Jira...";
+    try {
+      const comprobaciones = await enviarIDs("/verifydeletestatuses", ids);
+      if (comprobaciones) renderConfirmacion(comprobaciones);
+    } catch (error) {
+      console.error("Error al verificar los estados:", error);
+      alert("Error al verificar los estados: " + error);
+    }
+  });
+
+  try {
+    await cargarEstadosSinUso();
+  } catch (error) {
+    console.error("Error al cargar los estados sin uso:", error);
+    alert("Error al cargar los estados sin uso: " + error);
+  }
+}
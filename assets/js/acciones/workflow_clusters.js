@@ -0,0 +1,65 @@
+// Pinta los grupos de workflows similares
+function renderClusters(informe) {
+  const resultado = document.getElementById("resultado");
+  resultado.innerHTML = "";
+
+  const resumen = document.createElement("p");
+  resumen.textContent = `${informe.clusters.length} grupos con similitud ≥ ${informe.threshold}.`;
+  if (!informe.projectsAvailable) {
+    resumen.textContent += " El snapshot no tiene el cálculo de workflows por proyecto; descárgalo en Data para ver los proyectos.";
+  }
+  resultado.appendChild(resumen);
+
+  informe.clusters.forEach((grupo, index) => {
+    const heading = document.createElement("h4");
+    heading.textContent = `Grupo ${index + 1}: ${grupo.members.length} workflows (similitud mínima entre dos miembros cualesquiera ${grupo.minScore})`;
+    resultado.appendChild(heading);
+
+    const table = document.createElement("table");
+    table.classList.add("table", "table-striped");
+    table.innerHTML = `<thead><tr>
+      <th>Workflow</th>
+      <th>Proyectos</th>
+    </tr></thead>`;
+    const tbody = document.createElement("tbody");
+    grupo.members.forEach(miembro => {
+      const base = miembro.workflow === grupo.base ? " <span class=\"badge bg-success\">base</span>" : "";
+      const tr = document.createElement("tr");
+      tr.innerHTML = `<td>${miembro.workflow}${base}</td>
+                      <td>${miembro.projects.join(", ")}</td>`;
+      tbody.appendChild(tr);
+    });
+    table.appendChild(tbody);
+    resultado.appendChild(table);
+
+    const pares = document.createElement("details");
+    pares.innerHTML = `<summary>Similitud por pares</summary><ul>` +
+      grupo.pairs.map(p => `<li>${p.a} ↔ ${p.b}: ${p.score}</li>`).join("") + `</ul>`;
+    resultado.appendChild(pares);
+  });
+}
+
+// Carga los grupos con el umbral indicado
+async function loadClusters() {
+  const umbral = document.getElementById("umbral").value;
+  try {
+    const res = await fetch(`/getworkflowclusters?umbral=${umbral}`);
+    if (!res.ok) {
+      document.getElementById("resultado").textContent = await res.text();
+      return;
+    }
+    renderClusters(await res.json());
+  } catch (error) {
+    console.error("Error al agrupar workflows:", error);
+    alert("Error al agrupar workflows: " + error);
+  }
+}
+
+// Función de inicialización para workflow_clusters.html
+export async function initWorkflowClusters() {
+  document.getElementById("clustersForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    await loadClusters();
+  });
+  await loadClusters();
+}
@@ -0,0 +1,30 @@
+// Muestra el SVG del workflow elegido y actualiza los enlaces de descarga y el texto Mermaid
+async function showWorkflow(nombre) {
+  const base = `/exportworkflow?name=${encodeURIComponent(nombre)}`;
+  document.getElementById("diagrama").innerHTML = `<img src="${base}&format=svg" alt="${nombre}">`;
+  document.getElementById("descargarSvg").href = `${base}&format=svg&download=1`;
+  document.getElementById("descargarDot").href = `${base}&format=dot&download=1`;
+  document.getElementById("descargarMermaid").href = `${base}&format=mermaid&download=1`;
+
+  const res = await fetch(`${base}&format=mermaid`);
+  document.getElementById("mermaid").value = res.ok ? await res.text() : "";
+}
+
+// Función de inicialización para workflow_diagram.html
+export async function initWorkflowDiagram() {
+  const select = document.getElementById("workflow");
+  try {
+    const res = await fetch("/getworkflownames");
+    if (!res.ok) {
+      document.getElementById("diagrama").textContent = await res.text();
+      return;
+    }
+    const nombres = await res.json();
+    select.innerHTML = nombres.map(n => `<option value="${n}">${n}</option>`).join("");
+    select.addEventListener("change", () => showWorkflow(select.value));
+    if (nombres.length > 0) await showWorkflow(nombres[0]);
+  } catch (error) {
+    console.error("Error al cargar los workflows:", error);
+    alert("Error al cargar los workflows: " + error);
+  }
+}
@@ -0,0 +1,92 @@
+// Rellena un select de conexiones con las conexiones guardadas, marcando la actual
+export async function fillConnectionSelect(select) {
+  const res = await fetch("/getconnections");
+  if (!res.ok) return;
+  const data = await res.json();
+  select.innerHTML = (data.connections || []).map((conn, index) =>
+    `<option value="${index}" ${index === data.current ? "selected" : ""}>${conn.domain}</option>`
+  ).join("");
+}
+
+// Rellena el select de workflows con los del snapshot de la conexión elegida
+async function fillWorkflowSelect(conexionSelect, workflowSelect) {
+  workflowSelect.innerHTML = "";
+  const res = await fetch(`/getworkflownames?conexion=${conexionSelect.value}`);
+  if (!res.ok) {
+    workflowSelect.innerHTML = `<option value="">Sin workflows en el snapshot</option>`;
+    return;
+  }
+  const nombres = await res.json();
+  workflowSelect.innerHTML = nombres.map(n => `<option value="${n}">${n}</option>`).join("");
+}
+
+// Lista de transiciones del diff como "Nombre: origen → destino"
+function listaTransiciones(transiciones, lado) {
+  if (transiciones.length === 0) return "<p>Ninguna</p>";
+  return '<ul>' + transiciones.map(t => {
+    const from = lado === "A" ? t.fromA : t.fromB;
+    const to = lado === "A" ? t.toA : t.toB;
+    const origen = (from && from.length > 0) ? from.join(", ") : (t.type === "global" ? "Cualquier estado" : "Inicio");
+    return `<li><strong>${t.name}</strong>: ${origen} → ${to}</li>`;
+  }).join("") + '</ul>';
+}
+
+// Pinta el resultado de la comparación
+function renderDiff(diff) {
+  const resultado = document.getElementById("resultado");
+  const redirigidas = diff.transitionsRetargeted.length === 0 ? "<p>Ninguna</p>" :
+    '<ul>' + diff.transitionsRetargeted.map(t => {
+      const origenA = (t.fromA || []).join(", ") || "Inicio";
+      const origenB = (t.fromB || []).join(", ") || "Inicio";
+      return `<li><strong>${t.name}</strong>: ${origenA} → ${t.toA} <em>pasa a</em> ${origenB} → ${t.toB}</li>`;
+    }).join("") + '</ul>';
+  const reglas = diff.rulesChanged.length === 0 ? "<p>Ninguna</p>" :
+    '<ul>' + diff.rulesChanged.map(t => `<li><strong>${t.name}</strong> (${(t.fromA || []).join(", ") || "Inicio"} → ${t.toA}): ${t.changes.join(", ")}</li>`).join("") + '</ul>';
+
+  resultado.innerHTML = `
+    <h4>${diff.workflowA} (${diff.domainA}) → ${diff.workflowB} (${diff.domainB})</h4>
+    <h5>Estados añadidos</h5><p>${diff.statusesAdded.join(", ") || "Ninguno"}</p>
+    <h5>Estados eliminados</h5><p>${diff.statusesRemoved.join(", ") || "Ninguno"}</p>
+    <h5>Transiciones añadidas</h5>${listaTransiciones(diff.transitionsAdded, "B")}
+    <h5>Transiciones eliminadas</h5>${listaTransiciones(diff.transitionsRemoved, "A")}
+    <h5>Transiciones redirigidas</h5>${redirigidas}
+    <h5>Reglas modificadas</h5>
+    ${diff.rulesByType ? '<p class="text-muted">Los workflows son de sitios distintos: las reglas se comparan solo por tipo, porque su configuración guarda IDs propios de cada sitio.</p>' : ""}
+    ${reglas}`;
+}
+
+// Función de inicialización para workflow_diff.html
+export async function initWorkflowDiff() {
+  const conexionA = document.getElementById("conexionA");
+  const conexionB = document.getElementById("conexionB");
+  const workflowA = document.getElementById("workflowA");
+  const workflowB = document.getElementById("workflowB");
+
+  await fillConnectionSelect(conexionA);
+  await fillConnectionSelect(conexionB);
+  await fillWorkflowSelect(conexionA, workflowA);
+  await fillWorkflowSelect(conexionB, workflowB);
+  conexionA.addEventListener("change", () => fillWorkflowSelect(conexionA, workflowA));
+  conexionB.addEventListener("change", () => fillWorkflowSelect(conexionB, workflowB));
+
+  document.getElementById("diffForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    const params = new URLSearchParams({
+      a: workflowA.value,
+      b: workflowB.value,
+      conexionA: conexionA.value,
+      conexionB: conexionB.value
+    });
+    try {
+      const res = await fetch(`/getworkflowdiff?${params}`);
+      if (!res.ok) {
+        document.getElementById("resultado").textContent = await res.text();
+        return;
+      }
+      renderDiff(await res.json());
+    } catch (error) {
+      console.error("Error al comparar workflows:", error);
+      alert("Error al comparar workflows: " + error);
+    }
+  });
+}
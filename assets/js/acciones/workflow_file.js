@@ -0,0 +1,72 @@
+// Pinta el resultado de la validación (y del envío)
+function renderWorkflowFile(resultado) {
+  const contenedor = document.getElementById("resultado");
+  const accion = resultado.action === "update" ? "actualizará" : "creará";
+  let estado;
+  if (!resultado.valid) {
+    estado = `<p class="text-danger">El workflow ${resultado.workflow} no es válido.</p>`;
+  } else if (resultado.applied) {
+    estado = `<p class="text-success">Workflow ${resultado.workflow} enviado a Jira (${resultado.action}).</p>`;
+  } else if (resultado.message) {
+    estado = `<p class="text-danger">Error al enviar ${resultado.workflow}: ${resultado.message}</p>`;
+  } else {
+    estado = `<p>Validación correcta: se ${accion} el workflow ${resultado.workflow}.</p>`;
+  }
+  const lista = (titulo, elementos, clase) => elementos.length === 0 ? "" :
+    `<h5>${titulo}</h5><ul class="${clase}">` + elementos.map(e => `<li>${e}</li>`).join("") + '</ul>';
+  contenedor.innerHTML = estado +
+    lista("Errores", resultado.errors, "text-danger") +
+    lista("Avisos", resultado.warnings, "text-warning");
+}
+
+// Envía la definición al backend para validarla o aplicarla
+async function enviarWorkflow(apply) {
+  const res = await fetch("/workflowfile", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ content: document.getElementById("contenido").value, apply })
+  });
+  if (!res.ok) {
+    document.getElementById("resultado").textContent = await res.text();
+    return null;
+  }
+  const resultado = await res.json();
+  renderWorkflowFile(resultado);
+  return resultado;
+}
+
+// Función de inicialización para workflow_file.html
+export function initWorkflowFile() {
+  const contenido = document.getElementById("contenido");
+  const aplicar = document.getElementById("aplicar");
+
+  document.getElementById("ficheroWorkflow").addEventListener("change", async (e) => {
+    const fichero = e.target.files[0];
+    if (fichero) contenido.value = await fichero.text();
+    aplicar.disabled = true;
+  });
+  // Cualquier cambio obliga a validar de nuevo
+  contenido.addEventListener("input", () => { aplicar.disabled = true; });
+
+  document.getElementById("workflowForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    try {
+      const resultado = await enviarWorkflow(false);
+      aplicar.disabled = !resultado || !resultado.valid;
+    } catch (error) {
+      console.error("Error al validar el workflow:", error);
+      alert("Error al validar el workflow: " + error);
+    }
+  });
+
+  aplicar.addEventListener("click", async () => {
+    if (!confirm("¿Enviar el workflow a Jira?")) return;
+    aplicar.disabled = true;
+    try {
+      await enviarWorkflow(true);
+    } catch (error) {
+      console.error("Error al enviar el workflow:", error);
+      alert("Error al enviar el workflow: " + error);
+    }
+  });
+}
@@ -0,0 +1,58 @@
+let analisis = [];
+
+// Lista de estados como "Nombre (ID)"
+function listaEstados(estados) {
+  return estados.map(e => `${e.name || "?"} (${e.id})`).join(", ");
+}
+
+// Pinta la tabla con los problemas encontrados en cada workflow
+function renderWorkflowLint() {
+  const resultado = document.getElementById("resultado");
+  resultado.innerHTML = "";
+  const soloProblemas = document.getElementById("soloProblemas").checked;
+  const workflows = soloProblemas ? analisis.filter(wf => wf.problems > 0) : analisis;
+
+  const resumen = document.createElement("p");
+  resumen.textContent = `${analisis.filter(wf => wf.problems > 0).length} de ${analisis.length} workflows con problemas.`;
+  resultado.appendChild(resumen);
+
+  const table = document.createElement("table");
+  table.classList.add("table", "table-striped");
+  const thead = document.createElement("thead");
+  thead.innerHTML = `<tr>
+    <th>Workflow</th>
+    <th>Transición inicial</th>
+    <th>Inalcanzables</th>
+    <th>Sin salida (no Done)</th>
+  </tr>`;
+  table.appendChild(thead);
+
+  const tbody = document.createElement("tbody");
+  workflows.forEach(wf => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = `<td>${wf.workflow}</td>
+                    <td>${wf.hasInitialTransition ? "Sí" : "<span class=\"text-danger\">No</span>"}</td>
+                    <td>${listaEstados(wf.unreachable)}</td>
+                    <td>${listaEstados(wf.deadEnds)}</td>`;
+    tbody.appendChild(tr);
+  });
+  table.appendChild(tbody);
+  resultado.appendChild(table);
+}
+
+// Función de inicialización para workflow_lint.html
+export async function initWorkflowLint() {
+  document.getElementById("soloProblemas").addEventListener("change", renderWorkflowLint);
+  try {
+    const res = await fetch("/getworkflowlint");
+    if (!res.ok) {
+      document.getElementById("resultado").textContent = await res.text();
+      return;
+    }
+    analisis = await res.json();
+    renderWorkflowLint();
+  } catch (error) {
+    console.error("Error al cargar el análisis de workflows:", error);
+    alert("Error al cargar el análisis de workflows: " + error);
+  }
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Alta masiva de estados desde un CSV, con modo dry-run
+// ----------------------------------------------------------------
+
+// Nombres de categoría que se aceptan en el CSV (ya normalizados) y su valor en la API
+var categoriasCSV = map[string]string{
+	"todo":        "TODO",
+	"to do":       "TODO",
+	"por hacer":   "TODO",
+	"in_progress": "IN_PROGRESS",
+	"in progress": "IN_PROGRESS",
+	"en curso":    "IN_PROGRESS",
+	"en progreso": "IN_PROGRESS",
+	"done":        "DONE",
+	"hecho":       "DONE",
+	"finalizado":  "DONE",
+}
+
+// normalizarCategoria traduce la categoría escrita en el CSV al valor que espera Jira.
+func normalizarCategoria(categoria string) (string, bool) {
+	valor, ok := categoriasCSV[strings.ToLower(strings.TrimSpace(categoria))]
+	return valor, ok
+}
+
+// leerCSVEstados lee las filas "nombre,descripción,categoría" del CSV. La cabecera es opcional
+// y el separador puede ser coma o punto y coma.
+func leerCSVEstados(contenido string) ([]StatusCreateRow, error) {
+	separador := ','
+	if primera, _, _ := strings.Cut(contenido, "\n"); strings.Count(primera, ";") > strings.Count(primera, ",") {
+		separador = ';'
+	}
+	reader := csv.NewReader(strings.NewReader(contenido))
+	reader.Comma = separador
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var filas []StatusCreateRow
+	for numero := 1; ; numero++ {
+		registro, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo el CSV en la fila %d: %w", numero, err)
+		}
+		if numero == 1 && len(registro) > 0 {
+			if cabecera := strings.ToLower(strings.TrimSpace(registro[0])); cabecera == "name" || cabecera == "nombre" {
+				continue
+			}
+		}
+		for len(registro) < 3 {
+			registro = append(registro, "")
+		}
+		if strings.TrimSpace(strings.Join(registro, "")) == "" {
+			continue
+		}
+		filas = append(filas, StatusCreateRow{
+			Row:            numero,
+			Name:           strings.TrimSpace(registro[0]),
+			Description:    strings.TrimSpace(registro[1]),
+			StatusCategory: strings.TrimSpace(registro[2]),
+		})
+	}
+	return filas, nil
+}
+
+// planificarAltaEstados decide qué hacer con cada fila comparándola con los estados globales del
+// snapshot: crear, ya existe (mismo nombre sin distinguir mayúsculas) o inválida. Las filas
+// repetidas dentro del propio CSV solo se crean una vez.
+func planificarAltaEstados(filas []StatusCreateRow, estados []JiraStatus) {
+	existentes := make(map[string]JiraStatus)
+	parecidos := make(map[string]JiraStatus)
+	for _, st := range estados {
+		if st.Scope != nil && st.Scope.Type != "" && st.Scope.Type != "GLOBAL" {
+			continue
+		}
+		existentes[strings.ToLower(st.Name)] = st
+		parecidos[claveNombreEstado(st.Name)] = st
+	}
+
+	enCSV := make(map[string]int)
+	for i := range filas {
+		fila := &filas[i]
+		fila.Result = "skipped"
+
+		categoria, ok := normalizarCategoria(fila.StatusCategory)
+		switch {
+		case fila.Name == "":
+			fila.Action, fila.Message = "invalid", "Falta el nombre"
+			continue
+		case len([]rune(fila.Name)) > 255:
+			fila.Action, fila.Message = "invalid", "El nombre supera los 255 caracteres"
+			continue
+		case !ok:
+			fila.Action, fila.Message = "invalid", fmt.Sprintf("Categoría %q no válida (TODO, IN_PROGRESS o DONE)", fila.StatusCategory)
+			continue
+		}
+		fila.StatusCategory = categoria
+
+		if st, ok := existentes[strings.ToLower(fila.Name)]; ok {
+			fila.Action, fila.ID = "exists", st.ID
+			fila.Message = "Ya existe el estado " + st.Name
+			if st.StatusCategory != categoria {
+				fila.Message += fmt.Sprintf(" con categoría %s", st.StatusCategory)
+			}
+			continue
+		}
+		if anterior, ok := enCSV[strings.ToLower(fila.Name)]; ok {
+			fila.Action, fila.Message = "invalid", fmt.Sprintf("Repetido en la fila %d", anterior)
+			continue
+		}
+		enCSV[strings.ToLower(fila.Name)] = fila.Row
+
+		fila.Action, fila.Result = "create", "pending"
+		if st, ok := parecidos[claveNombreEstado(fila.Name)]; ok {
+			fila.Message = "Parecido al estado existente " + st.Name
+		}
+	}
+}
+
+// altaMasivaEstados planifica el CSV contra el snapshot de la conexión activa y, si no es dry-run,
+// crea los estados que faltan y los añade al snapshot.
+func altaMasivaEstados(peticion StatusBulkCreateRequest) (StatusBulkCreateResult, error) {
+	resultado := StatusBulkCreateResult{DryRun: peticion.DryRun}
+
+	filas, err := leerCSVEstados(peticion.CSV)
+	if err != nil {
+		return resultado, err
+	}
+	if len(filas) == 0 {
+		return resultado, fmt.Errorf("el CSV no contiene filas")
+	}
+	conn, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		return resultado, err
+	}
+	if _, ok := snapshot["estados"]; !ok {
+		return resultado, fmt.Errorf("el snapshot no contiene estados; ejecuta antes la descarga de estados")
+	}
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		return resultado, err
+	}
+
+	planificarAltaEstados(filas, estados)
+
+	var nuevos []JiraStatus
+	for _, fila := range filas {
+		if fila.Action == "create" {
+			nuevos = append(nuevos, JiraStatus{Name: fila.Name, Description: fila.Description, StatusCategory: fila.StatusCategory})
+		}
+	}
+
+	if !peticion.DryRun && len(nuevos) > 0 {
+		client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+		abrirLoteDiario(client)
+		creados, errCrear := crearEstadosJira(client, nuevos)
+		cerrarLoteDiario(client)
+
+		porNombre := make(map[string]JiraStatus, len(creados))
+		for _, st := range creados {
+			porNombre[strings.ToLower(st.Name)] = st
+		}
+		for i := range filas {
+			if filas[i].Action != "create" {
+				continue
+			}
+			if st, ok := porNombre[strings.ToLower(filas[i].Name)]; ok {
+				filas[i].Result, filas[i].ID = "created", st.ID
+			} else {
+				filas[i].Result = "error"
+				if errCrear != nil {
+					filas[i].Message = errCrear.Error()
+				}
+			}
+		}
+
+		if len(creados) > 0 {
+			if err := guardarEstadosSnapshot(conn, creados, nil); err != nil {
+				log.Println("Error al actualizar los estados del snapshot:", err)
+			}
+		}
+	}
+
+	for _, fila := range filas {
+		switch {
+		case fila.Action == "exists":
+			resultado.Existing++
+		case fila.Action == "invalid":
+			resultado.Invalid++
+		case fila.Result == "created":
+			resultado.Created++
+		case fila.Result == "error":
+			resultado.Failed++
+		}
+	}
+	resultado.Rows = filas
+	return resultado, nil
+}
+
+// handleBulkCreateStatuses recibe el CSV y devuelve el resultado fila a fila. Con "dryRun" solo
+// devuelve la previsualización sin escribir en Jira.
+func handleBulkCreateStatuses(w http.ResponseWriter, r *http.Request) {
+	var peticion StatusBulkCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := altaMasivaEstados(peticion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanificarAltaEstados(t *testing.T) {
+	estados := []JiraStatus{
+		{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "2", Name: "In Progress", StatusCategory: "IN_PROGRESS"},
+		{ID: "3", Name: "Done", StatusCategory: "DONE"},
+		{ID: "4", Name: "QA", StatusCategory: "IN_PROGRESS", Scope: &JiraScope{Type: "PROJECT", Project: &JiraScopeProject{ID: "100"}}},
+	}
+	casos := []struct {
+		nombre    string
+		fila      StatusCreateRow
+		accion    string
+		resultado string
+		id        string
+		categoria string
+		mensaje   string
+	}{
+		{nombre: "estado nuevo", fila: StatusCreateRow{Name: "Review", StatusCategory: "in progress"}, accion: "create", resultado: "pending", categoria: "IN_PROGRESS"},
+		{nombre: "ya existe sin distinguir mayúsculas", fila: StatusCreateRow{Name: "to do", StatusCategory: "TODO"}, accion: "exists", resultado: "skipped", id: "1", mensaje: "Ya existe el estado To Do"},
+		{nombre: "ya existe con otra categoría", fila: StatusCreateRow{Name: "Done", StatusCategory: "TODO"}, accion: "exists", resultado: "skipped", id: "3", mensaje: "con categoría DONE"},
+		{nombre: "sin nombre", fila: StatusCreateRow{StatusCategory: "TODO"}, accion: "invalid", resultado: "skipped", mensaje: "Falta el nombre"},
+		{nombre: "nombre demasiado largo", fila: StatusCreateRow{Name: strings.Repeat("x", 256), StatusCategory: "TODO"}, accion: "invalid", resultado: "skipped", mensaje: "255 caracteres"},
+		{nombre: "categoría no válida", fila: StatusCreateRow{Name: "Nuevo", StatusCategory: "WAITING"}, accion: "invalid", resultado: "skipped", mensaje: "Categoría \"WAITING\" no válida"},
+		{nombre: "parecido a uno existente", fila: StatusCreateRow{Name: "En curso", StatusCategory: "en curso"}, accion: "create", resultado: "pending", categoria: "IN_PROGRESS", mensaje: "Parecido al estado existente In Progress"},
+		{nombre: "los estados de proyecto no cuentan", fila: StatusCreateRow{Name: "QA", StatusCategory: "IN_PROGRESS"}, accion: "create", resultado: "pending", categoria: "IN_PROGRESS"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			filas := []StatusCreateRow{caso.fila}
+			planificarAltaEstados(filas, estados)
+			fila := filas[0]
+			if fila.Action != caso.accion || fila.Result != caso.resultado || fila.ID != caso.id {
+				t.Errorf("acción %q, resultado %q, ID %q; se esperaba %q, %q, %q", fila.Action, fila.Result, fila.ID, caso.accion, caso.resultado, caso.id)
+			}
+			if caso.categoria != "" && fila.StatusCategory != caso.categoria {
+				t.Errorf("categoría %q, se esperaba %q", fila.StatusCategory, caso.categoria)
+			}
+			if !strings.Contains(fila.Message, caso.mensaje) {
+				t.Errorf("mensaje %q, se esperaba que contuviera %q", fila.Message, caso.mensaje)
+			}
+		})
+	}
+}
+
+func TestPlanificarAltaEstadosRepetidos(t *testing.T) {
+	filas := []StatusCreateRow{
+		{Row: 2, Name: "Review", StatusCategory: "IN_PROGRESS"},
+		{Row: 3, Name: "REVIEW", StatusCategory: "IN_PROGRESS"},
+		{Row: 4, Name: "Done", StatusCategory: "DONE"},
+		{Row: 5, Name: "done", StatusCategory: "DONE"},
+	}
+	planificarAltaEstados(filas, []JiraStatus{{ID: "3", Name: "Done", StatusCategory: "DONE"}})
+
+	acciones := []string{"create", "invalid", "exists", "exists"}
+	for i, fila := range filas {
+		if fila.Action != acciones[i] {
+			t.Errorf("fila %d: acción %q, se esperaba %q", fila.Row, fila.Action, acciones[i])
+		}
+	}
+	if filas[1].Message != "Repetido en la fila 2" {
+		t.Errorf("mensaje de la fila repetida = %q", filas[1].Message)
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Análisis sobre el snapshot guardado (no hacen peticiones a Jira)
+// ----------------------------------------------------------------
+
+// cargarEstadosYWorkflows lee del snapshot de la conexión activa las secciones de estados y workflows,
+// que son la base de la mayoría de análisis.
+func cargarEstadosYWorkflows() ([]JiraStatus, []JiraWorkflow, error) {
+	_, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := snapshot["estados"]; !ok {
+		return nil, nil, fmt.Errorf("el snapshot no contiene estados; ejecuta antes la descarga de estados")
+	}
+	if _, ok := snapshot["workflows"]; !ok {
+		return nil, nil, fmt.Errorf("el snapshot no contiene workflows; ejecuta antes la descarga de workflows")
+	}
+
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		return nil, nil, err
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		return nil, nil, err
+	}
+	return estados, workflows, nil
+}
+
+// estadosDeWorkflow devuelve los IDs de estado que usa un workflow, tanto en su lista de
+// estados como en el origen o destino de sus transiciones.
+func estadosDeWorkflow(wf JiraWorkflow) map[string]bool {
+	ids := make(map[string]bool)
+	for _, st := range wf.Statuses {
+		ids[st.ID] = true
+	}
+	for _, t := range wf.Transitions {
+		for _, from := range t.From {
+			ids[from] = true
+		}
+		if t.To != "" {
+			ids[t.To] = true
+		}
+	}
+	return ids
+}
+
+// detectarEstadosSinUso cruza los estados con los workflows y devuelve los que no aparecen en ninguno.
+// Un estado solo se considera seguro de borrar si además Jira no le conoce usos en proyectos ni workflows.
+func detectarEstadosSinUso(estados []JiraStatus, workflows []JiraWorkflow) []UnusedStatus {
+	usados := make(map[string]bool)
+	for _, wf := range workflows {
+		for id := range estadosDeWorkflow(wf) {
+			usados[id] = true
+		}
+	}
+
+	resultado := []UnusedStatus{}
+	for _, st := range estados {
+		if usados[st.ID] {
+			continue
+		}
+		sinUso := UnusedStatus{
+			ID:                 st.ID,
+			Name:               st.Name,
+			StatusCategory:     st.StatusCategory,
+			Scope:              "GLOBAL",
+			UsageCount:         len(st.Usages),
+			WorkflowUsageCount: len(st.WorkflowUsages),
+			Evidence:           []string{fmt.Sprintf("No aparece en ninguno de los %d workflows del snapshot", len(workflows))},
+		}
+		if st.Scope != nil && st.Scope.Type != "" {
+			sinUso.Scope = st.Scope.Type
+		}
+
+		if sinUso.UsageCount == 0 {
+			sinUso.Evidence = append(sinUso.Evidence, "Ningún proyecto ni tipo de incidencia lo usa")
+		} else {
+			sinUso.Evidence = append(sinUso.Evidence, fmt.Sprintf("Jira indica que lo usan %d proyectos", sinUso.UsageCount))
+		}
+		if sinUso.WorkflowUsageCount == 0 {
+			sinUso.Evidence = append(sinUso.Evidence, "Jira no le asocia ningún workflow")
+		} else {
+			// Puede ocurrir con borradores o workflows que no se descargaron en el snapshot
+			sinUso.Evidence = append(sinUso.Evidence, fmt.Sprintf("Jira le asocia %d workflows", sinUso.WorkflowUsageCount))
+		}
+		sinUso.SafeToDelete = sinUso.UsageCount == 0 && sinUso.WorkflowUsageCount == 0
+		resultado = append(resultado, sinUso)
+	}
+
+	sort.Slice(resultado, func(i, j int) bool {
+		return strings.ToLower(resultado[i].Name) < strings.ToLower(resultado[j].Name)
+	})
+	return resultado
+}
+
+// handleUnusedStates devuelve el informe de estados sin uso en JSON o, con ?format=csv, como CSV.
+func handleUnusedStates(w http.ResponseWriter, r *http.Request) {
+	estados, workflows, err := cargarEstadosYWorkflows()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	informe := detectarEstadosSinUso(estados, workflows)
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=estados_sin_uso.csv")
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"id", "nombre", "categoria", "ambito", "usos_proyecto", "usos_workflow", "seguro_borrar", "evidencia"})
+		for _, st := range informe {
+			writer.Write([]string{
+				st.ID,
+				st.Name,
+				st.StatusCategory,
+				st.Scope,
+				strconv.Itoa(st.UsageCount),
+				strconv.Itoa(st.WorkflowUsageCount),
+				strconv.FormatBool(st.SafeToDelete),
+				strings.Join(st.Evidence, "; "),
+			})
+		}
+		writer.Flush()
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(informe)
+}
+
+// Sustituye vocales acentuadas y otros caracteres para comparar nombres sin acentos
+var quitarAcentos = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u", "ç", "c",
+	"-", " ", "_", " ", ".", " ", "/", " ",
+)
+
+// Variantes de idioma y ortografía de nombres de estado (ya normalizados) y su forma común. Solo
+// se incluyen traducciones directas: estados parecidos pero distintos, como "Backlog" y "To Do" o
+// "Review" e "In Review", no son el mismo estado y no se agrupan.
+var sinonimosEstado = map[string]string{
+	"en curso":    "in progress",
+	"en progreso": "in progress",
+	"en proceso":  "in progress",
+	"por hacer":   "to do",
+	"hecho":       "done",
+	"cerrado":     "closed",
+	"abierto":     "open",
+	"en revision": "in review",
+	"resuelto":    "resolved",
+	"bloqueado":   "blocked",
+	"cancelado":   "cancelled",
+	"canceled":    "cancelled",
+	"reabierto":   "reopened",
+	"en pruebas":  "in testing",
+	"en espera":   "on hold",
+	"rechazado":   "rejected",
+	"desplegado":  "deployed",
+	"en analisis": "in analysis",
+	"aprobado":    "approved",
+}
+
+// claveNombreEstado normaliza un nombre de estado: minúsculas, sin acentos ni separadores,
+// traducciones y variantes ortográficas unificadas y sin espacios, de modo que "In-Progress", "in progress" y "En curso"
+// comparten clave.
+func claveNombreEstado(nombre string) string {
+	normalizado := strings.Join(strings.Fields(quitarAcentos.Replace(strings.ToLower(nombre))), " ")
+	if sinonimo, ok := sinonimosEstado[normalizado]; ok {
+		normalizado = sinonimo
+	}
+	return strings.ReplaceAll(normalizado, " ", "")
+}
+
+// detectarEstadosDuplicados agrupa los estados con la misma clave normalizada y propone como
+// canónico el que usan más workflows (en empate: global antes que de proyecto y luego el ID más bajo).
+func detectarEstadosDuplicados(estados []JiraStatus, workflows []JiraWorkflow) []DuplicateStatusGroup {
+	workflowsPorEstado := make(map[string][]string)
+	for _, wf := range workflows {
+		for id := range estadosDeWorkflow(wf) {
+			workflowsPorEstado[id] = append(workflowsPorEstado[id], wf.ID.Name)
+		}
+	}
+
+	porClave := make(map[string][]StatusVariant)
+	for _, st := range estados {
+		variante := StatusVariant{
+			ID:             st.ID,
+			Name:           st.Name,
+			StatusCategory: st.StatusCategory,
+			Scope:          "GLOBAL",
+			Workflows:      workflowsPorEstado[st.ID],
+		}
+		if st.Scope != nil && st.Scope.Type != "" {
+			variante.Scope = st.Scope.Type
+		}
+		if variante.Workflows == nil {
+			variante.Workflows = []string{}
+		}
+		sort.Strings(variante.Workflows)
+		clave := claveNombreEstado(st.Name)
+		porClave[clave] = append(porClave[clave], variante)
+	}
+
+	grupos := []DuplicateStatusGroup{}
+	for clave, variantes := range porClave {
+		if len(variantes) < 2 {
+			continue
+		}
+		sort.Slice(variantes, func(i, j int) bool {
+			a, b := variantes[i], variantes[j]
+			if len(a.Workflows) != len(b.Workflows) {
+				return len(a.Workflows) > len(b.Workflows)
+			}
+			if (a.Scope == "GLOBAL") != (b.Scope == "GLOBAL") {
+				return a.Scope == "GLOBAL"
+			}
+			idA, _ := strconv.Atoi(a.ID)
+			idB, _ := strconv.Atoi(b.ID)
+			return idA < idB
+		})
+		grupos = append(grupos, DuplicateStatusGroup{
+			Key:           clave,
+			CanonicalID:   variantes[0].ID,
+			CanonicalName: variantes[0].Name,
+			Variants:      variantes,
+		})
+	}
+
+	sort.Slice(grupos, func(i, j int) bool {
+		if len(grupos[i].Variants) != len(grupos[j].Variants) {
+			return len(grupos[i].Variants) > len(grupos[j].Variants)
+		}
+		return grupos[i].Key < grupos[j].Key
+	})
+	return grupos
+}
+
+// handleDuplicateStates devuelve los grupos de estados duplicados o casi duplicados.
+func handleDuplicateStates(w http.ResponseWriter, r *http.Request) {
+	estados, workflows, err := cargarEstadosYWorkflows()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detectarEstadosDuplicados(estados, workflows))
+}
+
+// ----------------------------------------------------------------
+// Coherencia entre el nombre de los estados y su categoría
+// ----------------------------------------------------------------
+
+// Palabras (ya normalizadas) que delatan la categoría que debería tener un estado.
+// Se comprueban como palabras o frases completas dentro del nombre.
+var palabrasCategoria = []struct {
+	categoria string
+	palabras  []string
+}{
+	{"DONE", []string{"done", "closed", "resolved", "finished", "complete", "completed", "cancelled", "canceled", "rejected", "hecho", "cerrado", "resuelto", "finalizado", "terminado", "completado", "cancelado", "rechazado", "descartado"}},
+	{"IN_PROGRESS", []string{"in progress", "doing", "in review", "in testing", "en curso", "en progreso", "en proceso", "en revision", "en pruebas", "en analisis"}},
+	{"TODO", []string{"to do", "backlog", "open", "new", "por hacer", "pendiente", "abierto", "nuevo"}},
+}
+
+// categoriaEsperada devuelve la categoría que sugiere el nombre del estado y la palabra que la
+// delata, o "" si el nombre no contiene ninguna palabra conocida.
+func categoriaEsperada(nombre string) (string, string) {
+	normalizado := " " + strings.Join(strings.Fields(quitarAcentos.Replace(strings.ToLower(nombre))), " ") + " "
+	for _, grupo := range palabrasCategoria {
+		for _, palabra := range grupo.palabras {
+			if strings.Contains(normalizado, " "+palabra+" ") {
+				return grupo.categoria, palabra
+			}
+		}
+	}
+	return "", ""
+}
+
+// detectarCategoriasIncoherentes devuelve los estados cuyo nombre contradice su categoría y los
+// workflows con estados finales (sin transiciones de salida) que no están en la categoría Done.
+func detectarCategoriasIncoherentes(estados []JiraStatus, workflows []JiraWorkflow) CategoryConsistencyReport {
+	informe := CategoryConsistencyReport{
+		Statuses:  []CategoryMismatch{},
+		Workflows: []WorkflowFinalCategories{},
+	}
+
+	workflowsPorEstado := make(map[string][]string)
+	for _, wf := range workflows {
+		for id := range estadosDeWorkflow(wf) {
+			workflowsPorEstado[id] = append(workflowsPorEstado[id], wf.ID.Name)
+		}
+	}
+
+	for _, st := range estados {
+		esperada, palabra := categoriaEsperada(st.Name)
+		if esperada == "" || st.StatusCategory == "" || esperada == st.StatusCategory {
+			continue
+		}
+		incoherente := CategoryMismatch{
+			ID:               st.ID,
+			Name:             st.Name,
+			StatusCategory:   st.StatusCategory,
+			ExpectedCategory: esperada,
+			Keyword:          palabra,
+			Workflows:        workflowsPorEstado[st.ID],
+		}
+		if incoherente.Workflows == nil {
+			incoherente.Workflows = []string{}
+		}
+		sort.Strings(incoherente.Workflows)
+		informe.Statuses = append(informe.Statuses, incoherente)
+	}
+	sort.Slice(informe.Statuses, func(i, j int) bool {
+		return strings.ToLower(informe.Statuses[i].Name) < strings.ToLower(informe.Statuses[j].Name)
+	})
+
+	categorias := categoriasEstados(estados)
+	for _, wf := range workflows {
+		nombres := nombresEstadosWorkflow(wf)
+		finales := WorkflowFinalCategories{
+			Workflow:    wf.ID.Name,
+			FinalStates: []LintStatus{},
+			NotDone:     []LintStatus{},
+		}
+		for _, id := range estadosSinSalida(wf) {
+			estado := LintStatus{ID: id, Name: nombres[id], StatusCategory: categorias[id]}
+			finales.FinalStates = append(finales.FinalStates, estado)
+			if categorias[id] != "DONE" {
+				finales.NotDone = append(finales.NotDone, estado)
+			}
+		}
+		if len(finales.NotDone) > 0 {
+			informe.Workflows = append(informe.Workflows, finales)
+		}
+	}
+	sort.Slice(informe.Workflows, func(i, j int) bool {
+		return informe.Workflows[i].Workflow < informe.Workflows[j].Workflow
+	})
+	return informe
+}
+
+// handleCategoryConsistency devuelve los estados y workflows con categorías incoherentes.
+func handleCategoryConsistency(w http.ResponseWriter, r *http.Request) {
+	estados, workflows, err := cargarEstadosYWorkflows()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detectarCategoriasIncoherentes(estados, workflows))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Informe de proyectos para la revisión trimestral de higiene
+// ----------------------------------------------------------------
+
+// agruparProyectos agrupa los proyectos por categoría, separa los que no tienen categoría
+// y cuenta los proyectos de cada responsable.
+func agruparProyectos(proyectos []JiraProject) ProjectReport {
+	informe := ProjectReport{
+		Total:           len(proyectos),
+		ByCategory:      []ProjectCategoryGroup{},
+		WithoutCategory: []JiraProject{},
+		ByLead:          []ProjectLeadCount{},
+	}
+
+	ordenados := append([]JiraProject{}, proyectos...)
+	sort.Slice(ordenados, func(i, j int) bool { return ordenados[i].Key < ordenados[j].Key })
+
+	porCategoria := make(map[string][]JiraProject)
+	porResponsable := make(map[string]*ProjectLeadCount)
+	for _, p := range ordenados {
+		if p.Archived {
+			informe.Archived++
+		}
+		if p.Simplified || p.Style == "next-gen" {
+			informe.TeamManaged++
+		}
+
+		if categoria := nombreCategoria(p); categoria != "" {
+			porCategoria[categoria] = append(porCategoria[categoria], p)
+		} else {
+			informe.WithoutCategory = append(informe.WithoutCategory, p)
+		}
+
+		clave, nombre := "", "Sin responsable"
+		if p.Lead != nil {
+			clave, nombre = p.Lead.AccountID, p.Lead.DisplayName
+		}
+		responsable, ok := porResponsable[clave]
+		if !ok {
+			responsable = &ProjectLeadCount{AccountID: clave, Lead: nombre, Projects: []string{}}
+			porResponsable[clave] = responsable
+		}
+		responsable.Count++
+		responsable.Projects = append(responsable.Projects, p.Key)
+	}
+
+	for categoria, lista := range porCategoria {
+		informe.ByCategory = append(informe.ByCategory, ProjectCategoryGroup{Category: categoria, Projects: lista})
+	}
+	sort.Slice(informe.ByCategory, func(i, j int) bool {
+		return strings.ToLower(informe.ByCategory[i].Category) < strings.ToLower(informe.ByCategory[j].Category)
+	})
+
+	for _, responsable := range porResponsable {
+		informe.ByLead = append(informe.ByLead, *responsable)
+	}
+	sort.Slice(informe.ByLead, func(i, j int) bool {
+		if informe.ByLead[i].Count != informe.ByLead[j].Count {
+			return informe.ByLead[i].Count > informe.ByLead[j].Count
+		}
+		return informe.ByLead[i].Lead < informe.ByLead[j].Lead
+	})
+	return informe
+}
+
+// handleProjectReport devuelve el informe de proyectos del snapshot de la conexión activa,
+// incluidos los archivados si se descargaron.
+func handleProjectReport(w http.ResponseWriter, r *http.Request) {
+	_, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if _, ok := snapshot["proyectos"]; !ok {
+		http.Error(w, "el snapshot no contiene proyectos; ejecuta antes la descarga de proyectos", http.StatusNotFound)
+		return
+	}
+	var proyectos, archivados []JiraProject
+	if err := leerSeccion(snapshot, "proyectos", &proyectos); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Los archivados están en su propia sección si se descargaron
+	if err := leerSeccion(snapshot, "proyectosArchivados", &archivados); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	proyectos = append(proyectos, archivados...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agruparProyectos(proyectos))
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectarEstadosSinUso(t *testing.T) {
+	estados := []JiraStatus{
+		{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "2", Name: "solo como destino", StatusCategory: "IN_PROGRESS"},
+		{ID: "3", Name: "Huérfano", StatusCategory: "TODO"},
+		{ID: "4", Name: "archivado", StatusCategory: "DONE", Usages: []JiraStatusUsage{{}}},
+		{ID: "5", Name: "En borrador", StatusCategory: "TODO", WorkflowUsages: []JiraStatusWorkflowUsage{{WorkflowName: "Borrador"}}},
+		{ID: "6", Name: "De proyecto", StatusCategory: "TODO", Scope: &JiraScope{Type: "PROJECT"}},
+	}
+	// El estado 2 solo aparece como destino de una transición, no en la lista de estados
+	workflows := []JiraWorkflow{{
+		ID:          WorkflowID{Name: "Simple"},
+		Statuses:    []WorkflowStatus{{ID: "1"}},
+		Transitions: []Transition{{Name: "Start", From: []string{"1"}, To: "2"}},
+	}}
+
+	informe := detectarEstadosSinUso(estados, workflows)
+
+	var nombres []string
+	seguros := map[string]bool{}
+	for _, st := range informe {
+		nombres = append(nombres, st.Name)
+		seguros[st.Name] = st.SafeToDelete
+	}
+	// Ordenados por nombre sin distinguir mayúsculas
+	if esperados := []string{"archivado", "De proyecto", "En borrador", "Huérfano"}; !reflect.DeepEqual(nombres, esperados) {
+		t.Fatalf("estados sin uso = %v, se esperaba %v", nombres, esperados)
+	}
+	casos := map[string]bool{"Huérfano": true, "De proyecto": true, "archivado": false, "En borrador": false}
+	for nombre, seguro := range casos {
+		if seguros[nombre] != seguro {
+			t.Errorf("%s: SafeToDelete = %v, se esperaba %v", nombre, seguros[nombre], seguro)
+		}
+	}
+	for _, st := range informe {
+		switch st.Name {
+		case "De proyecto":
+			if st.Scope != "PROJECT" {
+				t.Errorf("ámbito de %s = %q", st.Name, st.Scope)
+			}
+		case "archivado":
+			if st.UsageCount != 1 || !contieneTexto(st.Evidence, "lo usan 1 proyectos") {
+				t.Errorf("evidencia de %s: %v", st.Name, st.Evidence)
+			}
+		case "En borrador":
+			if !contieneTexto(st.Evidence, "le asocia 1 workflows") {
+				t.Errorf("evidencia de %s: %v", st.Name, st.Evidence)
+			}
+		}
+		if !contieneTexto(st.Evidence, "ninguno de los 1 workflows") {
+			t.Errorf("falta la evidencia de los workflows en %s: %v", st.Name, st.Evidence)
+		}
+	}
+}
+
+func TestClaveNombreEstado(t *testing.T) {
+	casos := []struct {
+		a, b    string
+		iguales bool
+	}{
+		{"In Progress", "in-progress", true},
+		{"In Progress", "En curso", true},
+		{"  Done ", "HECHO", true},
+		{"En revisión", "In_Review", true},
+		{"Cancelado", "Canceled", true},
+		{"Backlog", "To Do", false},
+		{"Review", "In Review", false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.a+" / "+caso.b, func(t *testing.T) {
+			if got := claveNombreEstado(caso.a) == claveNombreEstado(caso.b); got != caso.iguales {
+				t.Errorf("claves %q y %q: iguales = %v, se esperaba %v", claveNombreEstado(caso.a), claveNombreEstado(caso.b), got, caso.iguales)
+			}
+		})
+	}
+}
+
+func TestDetectarEstadosDuplicados(t *testing.T) {
+	estados := []JiraStatus{
+		{ID: "30", Name: "En curso", StatusCategory: "IN_PROGRESS"},
+		{ID: "12", Name: "In-Progress", StatusCategory: "IN_PROGRESS", Scope: &JiraScope{Type: "PROJECT"}},
+		{ID: "20", Name: "in progress", StatusCategory: "IN_PROGRESS"},
+		{ID: "3", Name: "Done", StatusCategory: "DONE"},
+		{ID: "10", Name: "DONE", StatusCategory: "DONE"},
+		{ID: "9", Name: "Backlog", StatusCategory: "TODO"},
+	}
+	workflows := []JiraWorkflow{
+		{ID: WorkflowID{Name: "B"}, Statuses: []WorkflowStatus{{ID: "30"}, {ID: "10"}}},
+		{ID: WorkflowID{Name: "A"}, Statuses: []WorkflowStatus{{ID: "30"}}},
+	}
+
+	grupos := detectarEstadosDuplicados(estados, workflows)
+	if len(grupos) != 2 {
+		t.Fatalf("se esperaban 2 grupos y hay %d: %+v", len(grupos), grupos)
+	}
+
+	// El grupo con más variantes va primero y el canónico es el que usan más workflows
+	progreso := grupos[0]
+	if progreso.Key != "inprogress" || progreso.CanonicalID != "30" || progreso.CanonicalName != "En curso" {
+		t.Errorf("grupo de in progress: %+v", progreso)
+	}
+	var orden []string
+	for _, v := range progreso.Variants {
+		orden = append(orden, v.ID)
+	}
+	// Con los mismos workflows, el global va antes que el de proyecto
+	if !reflect.DeepEqual(orden, []string{"30", "20", "12"}) {
+		t.Errorf("orden de variantes = %v", orden)
+	}
+	if !reflect.DeepEqual(progreso.Variants[0].Workflows, []string{"A", "B"}) || len(progreso.Variants[1].Workflows) != 0 {
+		t.Errorf("workflows por variante: %+v", progreso.Variants)
+	}
+
+	hecho := grupos[1]
+	if hecho.CanonicalID != "10" {
+		t.Errorf("canónico de done = %s, se esperaba 10 por ser el único en uso", hecho.CanonicalID)
+	}
+	// Sin diferencias de uso ni de ámbito decide el ID más bajo en número, no en texto
+	sinUso := detectarEstadosDuplicados(estados[3:5], nil)
+	if len(sinUso) != 1 || sinUso[0].CanonicalID != "3" {
+		t.Errorf("canónico sin uso = %+v, se esperaba el 3", sinUso)
+	}
+}
+
+func TestCategoriaEsperada(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		categoria string
+	}{
+		{"Done", "DONE"},
+		{"Cerrado", "DONE"},
+		{"Won't Do - Cancelled", "DONE"},
+		{"En curso", "IN_PROGRESS"},
+		{"in-review", "IN_PROGRESS"},
+		{"Backlog", "TODO"},
+		{"Pendiente de cliente", "TODO"},
+		// Las palabras se buscan completas: "Undone" no contiene "done"
+		{"Undone", ""},
+		{"Bloqueado", ""},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if got, _ := categoriaEsperada(caso.nombre); got != caso.categoria {
+				t.Errorf("categoriaEsperada(%q) = %q, se esperaba %q", caso.nombre, got, caso.categoria)
+			}
+		})
+	}
+}
+
+func TestDetectarCategoriasIncoherentes(t *testing.T) {
+	estados := []JiraStatus{
+		{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "2", Name: "Closed", StatusCategory: "IN_PROGRESS"},
+		{ID: "3", Name: "Hecho", StatusCategory: "TODO"},
+		{ID: "4", Name: "Done", StatusCategory: "DONE"},
+		{ID: "5", Name: "Esperando", StatusCategory: "IN_PROGRESS"},
+		{ID: "6", Name: "Open", StatusCategory: ""},
+	}
+	workflows := []JiraWorkflow{
+		{
+			ID:       WorkflowID{Name: "Bien"},
+			Statuses: []WorkflowStatus{{ID: "1", Name: "To Do"}, {ID: "4", Name: "Done"}},
+			Transitions: []Transition{
+				{Type: "initial", To: "1"},
+				{Type: "directed", From: []string{"1"}, To: "4"},
+			},
+		},
+		{
+			ID:       WorkflowID{Name: "Atascado"},
+			Statuses: []WorkflowStatus{{ID: "1", Name: "To Do"}, {ID: "2", Name: "Closed"}, {ID: "5", Name: "Esperando"}, {ID: "4", Name: "Done"}},
+			Transitions: []Transition{
+				{Type: "initial", To: "1"},
+				{Type: "directed", From: []string{"1"}, To: "2"},
+				{Type: "directed", From: []string{"1"}, To: "5"},
+				{Type: "directed", From: []string{"1"}, To: "4"},
+			},
+		},
+	}
+
+	informe := detectarCategoriasIncoherentes(estados, workflows)
+
+	var incoherentes []string
+	for _, st := range informe.Statuses {
+		incoherentes = append(incoherentes, st.Name+"→"+st.ExpectedCategory)
+	}
+	// Sin categoría no se puede juzgar, y Esperando no tiene ninguna palabra conocida
+	if esperados := []string{"Closed→DONE", "Hecho→DONE"}; !reflect.DeepEqual(incoherentes, esperados) {
+		t.Errorf("estados incoherentes = %v, se esperaba %v", incoherentes, esperados)
+	}
+	if len(informe.Statuses) > 0 && (informe.Statuses[0].Keyword != "closed" || !reflect.DeepEqual(informe.Statuses[0].Workflows, []string{"Atascado"})) {
+		t.Errorf("detalle de Closed: %+v", informe.Statuses[0])
+	}
+
+	if len(informe.Workflows) != 1 || informe.Workflows[0].Workflow != "Atascado" {
+		t.Fatalf("workflows con finales fuera de Done: %+v", informe.Workflows)
+	}
+	finales := informe.Workflows[0]
+	if got := idsLint(finales.FinalStates); !reflect.DeepEqual(got, []string{"2", "4", "5"}) {
+		t.Errorf("estados finales = %v", got)
+	}
+	if got := idsLint(finales.NotDone); !reflect.DeepEqual(got, []string{"2", "5"}) {
+		t.Errorf("finales fuera de Done = %v", got)
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Análisis del grafo de estados y transiciones de cada workflow
+// ----------------------------------------------------------------
+
+// nombresEstadosWorkflow devuelve el nombre de cada estado del workflow por ID.
+func nombresEstadosWorkflow(wf JiraWorkflow) map[string]string {
+	nombres := make(map[string]string, len(wf.Statuses))
+	for _, st := range wf.Statuses {
+		nombres[st.ID] = st.Name
+	}
+	return nombres
+}
+
+// estadosAlcanzables recorre el workflow desde el destino de sus transiciones iniciales.
+// Las transiciones globales se pueden lanzar desde cualquier estado, así que su destino es
+// alcanzable en cuanto lo es cualquier otro estado. Devuelve false si no hay transición inicial.
+func estadosAlcanzables(wf JiraWorkflow) (map[string]bool, bool) {
+	salidas := make(map[string][]string)
+	var iniciales, globales []string
+	for _, t := range wf.Transitions {
+		switch t.Type {
+		case "initial":
+			iniciales = append(iniciales, t.To)
+		case "global":
+			globales = append(globales, t.To)
+		default:
+			for _, from := range t.From {
+				salidas[from] = append(salidas[from], t.To)
+			}
+		}
+	}
+	alcanzados := make(map[string]bool)
+	if len(iniciales) == 0 {
+		return alcanzados, false
+	}
+
+	pendientes := append([]string{}, iniciales...)
+	pendientes = append(pendientes, globales...)
+	for len(pendientes) > 0 {
+		id := pendientes[0]
+		pendientes = pendientes[1:]
+		if alcanzados[id] {
+			continue
+		}
+		alcanzados[id] = true
+		pendientes = append(pendientes, salidas[id]...)
+	}
+	return alcanzados, true
+}
+
+// estadosSinSalida devuelve los IDs de los estados del workflow desde los que no sale ninguna
+// transición (ni dirigida ni global hacia otro estado), ordenados.
+func estadosSinSalida(wf JiraWorkflow) []string {
+	conSalida := make(map[string]bool)
+	var globales []string
+	for _, t := range wf.Transitions {
+		switch t.Type {
+		case "initial":
+			// La transición inicial no sale de ningún estado
+		case "global":
+			globales = append(globales, t.To)
+		default:
+			for _, from := range t.From {
+				conSalida[from] = true
+			}
+		}
+	}
+
+	var resultado []string
+	for id := range estadosDeWorkflow(wf) {
+		if conSalida[id] {
+			continue
+		}
+		// Una transición global sirve de salida a todos los estados salvo a su propio destino
+		tieneGlobal := false
+		for _, destino := range globales {
+			if destino != id {
+				tieneGlobal = true
+				break
+			}
+		}
+		if !tieneGlobal {
+			resultado = append(resultado, id)
+		}
+	}
+	sort.Strings(resultado)
+	return resultado
+}
+
+// analizarGrafoWorkflow busca estados inalcanzables desde la transición inicial y estados sin
+// salida que no están en la categoría Done. "categorias" es la categoría de cada estado por ID.
+func analizarGrafoWorkflow(wf JiraWorkflow, categorias map[string]string) WorkflowLint {
+	nombres := nombresEstadosWorkflow(wf)
+	lint := WorkflowLint{
+		Workflow:    wf.ID.Name,
+		Unreachable: []LintStatus{},
+		DeadEnds:    []LintStatus{},
+	}
+
+	alcanzados, tieneInicial := estadosAlcanzables(wf)
+	lint.HasInitialTransition = tieneInicial
+	if tieneInicial {
+		var ids []string
+		for id := range estadosDeWorkflow(wf) {
+			if !alcanzados[id] {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			lint.Unreachable = append(lint.Unreachable, LintStatus{ID: id, Name: nombres[id], StatusCategory: categorias[id]})
+		}
+	}
+
+	for _, id := range estadosSinSalida(wf) {
+		if categorias[id] == "DONE" {
+			continue
+		}
+		lint.DeadEnds = append(lint.DeadEnds, LintStatus{ID: id, Name: nombres[id], StatusCategory: categorias[id]})
+	}
+
+	lint.Problems = len(lint.Unreachable) + len(lint.DeadEnds)
+	if !tieneInicial {
+		lint.Problems++
+	}
+	return lint
+}
+
+// categoriasEstados devuelve la categoría de cada estado por ID.
+func categoriasEstados(estados []JiraStatus) map[string]string {
+	categorias := make(map[string]string, len(estados))
+	for _, st := range estados {
+		categorias[st.ID] = st.StatusCategory
+	}
+	return categorias
+}
+
+// handleWorkflowLint devuelve el análisis de todos los workflows del snapshot,
+// o solo del indicado con ?name=.
+func handleWorkflowLint(w http.ResponseWriter, r *http.Request) {
+	estados, workflows, err := cargarEstadosYWorkflows()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	categorias := categoriasEstados(estados)
+	nombre := r.URL.Query().Get("name")
+
+	resultado := []WorkflowLint{}
+	for _, wf := range workflows {
+		if nombre != "" && wf.ID.Name != nombre {
+			continue
+		}
+		resultado = append(resultado, analizarGrafoWorkflow(wf, categorias))
+	}
+	if nombre != "" && len(resultado) == 0 {
+		http.Error(w, "No se encontró el workflow "+nombre, http.StatusNotFound)
+		return
+	}
+
+	// Primero los workflows con más problemas
+	sort.SliceStable(resultado, func(i, j int) bool {
+		return resultado[i].Problems > resultado[j].Problems
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
+
+// ----------------------------------------------------------------
+// Similitud entre workflows para encontrar candidatos a consolidar
+// ----------------------------------------------------------------
+
+// Umbral de similitud por defecto para considerar dos workflows casi idénticos
+const umbralSimilitudDefecto = 0.9
+
+// firmaWorkflow devuelve el conjunto de estados (por nombre) y el de transiciones
+// (origen → destino por nombre) de un workflow, que es lo que se compara entre workflows.
+func firmaWorkflow(wf JiraWorkflow) (map[string]bool, map[string]bool) {
+	estados := make(map[string]bool)
+	for _, st := range wf.Statuses {
+		estados[strings.ToLower(st.Name)] = true
+	}
+	transiciones := make(map[string]bool)
+	for _, t := range nombrarTransiciones(wf) {
+		origen := strings.ToLower(strings.Join(t.from, ","))
+		switch t.original.Type {
+		case "initial":
+			origen = "^"
+		case "global":
+			origen = "*"
+		}
+		transiciones[origen+"→"+strings.ToLower(t.to)] = true
+	}
+	return estados, transiciones
+}
+
+// jaccard calcula |A∩B| / |A∪B|; dos conjuntos vacíos se consideran iguales.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	comunes := 0
+	for clave := range a {
+		if b[clave] {
+			comunes++
+		}
+	}
+	return float64(comunes) / float64(len(a)+len(b)-comunes)
+}
+
+// agruparWorkflowsSimilares puntúa cada par de workflows (media de la similitud de estados y de
+// transiciones) y une en un mismo grupo los pares que superan el umbral. Como los grupos se forman
+// encadenando pares, dos miembros pueden parecerse menos que el umbral: MinScore es la similitud
+// del par de miembros menos parecido del grupo, no la del peor par enlazado. "proyectos" indica
+// los proyectos que usan cada workflow.
+func agruparWorkflowsSimilares(workflows []JiraWorkflow, proyectos map[string][]string, umbral float64) []WorkflowCluster {
+	type firma struct{ estados, transiciones map[string]bool }
+	firmas := make([]firma, len(workflows))
+	for i, wf := range workflows {
+		firmas[i].estados, firmas[i].transiciones = firmaWorkflow(wf)
+	}
+
+	// Union-find sobre los índices de los workflows
+	padre := make([]int, len(workflows))
+	for i := range padre {
+		padre[i] = i
+	}
+	var raiz func(int) int
+	raiz = func(i int) int {
+		if padre[i] != i {
+			padre[i] = raiz(padre[i])
+		}
+		return padre[i]
+	}
+
+	var pares []WorkflowSimilarity
+	var indicesPares [][2]int
+	scores := make([][]float64, len(workflows))
+	for i := range scores {
+		scores[i] = make([]float64, len(workflows))
+	}
+	for i := 0; i < len(workflows); i++ {
+		for j := i + 1; j < len(workflows); j++ {
+			score := (jaccard(firmas[i].estados, firmas[j].estados) + jaccard(firmas[i].transiciones, firmas[j].transiciones)) / 2
+			enUmbral := score >= umbral
+			score = math.Round(score*1000) / 1000
+			scores[i][j], scores[j][i] = score, score
+			if !enUmbral {
+				continue
+			}
+			pares = append(pares, WorkflowSimilarity{A: workflows[i].ID.Name, B: workflows[j].ID.Name, Score: score})
+			indicesPares = append(indicesPares, [2]int{i, j})
+			padre[raiz(i)] = raiz(j)
+		}
+	}
+
+	porRaiz := make(map[int]*WorkflowCluster)
+	indicesGrupo := make(map[int][]int)
+	var orden []int
+	for i, wf := range workflows {
+		r := raiz(i)
+		if porRaiz[r] == nil {
+			porRaiz[r] = &WorkflowCluster{MinScore: 1}
+			orden = append(orden, r)
+		}
+		// Similitud mínima contra todos los miembros anteriores del grupo
+		for _, j := range indicesGrupo[r] {
+			porRaiz[r].MinScore = math.Min(porRaiz[r].MinScore, scores[i][j])
+		}
+		indicesGrupo[r] = append(indicesGrupo[r], i)
+		miembro := ClusterMember{Workflow: wf.ID.Name, Projects: proyectos[wf.ID.Name]}
+		if miembro.Projects == nil {
+			miembro.Projects = []string{}
+		}
+		porRaiz[r].Members = append(porRaiz[r].Members, miembro)
+	}
+	for k, par := range pares {
+		grupo := porRaiz[raiz(indicesPares[k][0])]
+		grupo.Pairs = append(grupo.Pairs, par)
+	}
+
+	grupos := []WorkflowCluster{}
+	for _, r := range orden {
+		grupo := porRaiz[r]
+		if len(grupo.Members) < 2 {
+			continue
+		}
+		// El miembro con más proyectos es el candidato natural a quedarse como workflow compartido
+		sort.SliceStable(grupo.Members, func(i, j int) bool {
+			return len(grupo.Members[i].Projects) > len(grupo.Members[j].Projects)
+		})
+		grupo.Base = grupo.Members[0].Workflow
+		sort.Slice(grupo.Pairs, func(i, j int) bool {
+			return grupo.Pairs[i].Score > grupo.Pairs[j].Score
+		})
+		grupos = append(grupos, *grupo)
+	}
+	sort.SliceStable(grupos, func(i, j int) bool {
+		return len(grupos[i].Members) > len(grupos[j].Members)
+	})
+	return grupos
+}
+
+// proyectosPorWorkflow devuelve, a partir de la sección workflowsProyectos, las claves de los
+// proyectos que usan cada workflow.
+func proyectosPorWorkflow(workflowsProyectos []JiraProjectWorkflows) map[string][]string {
+	vistos := make(map[string]map[string]bool)
+	resultado := make(map[string][]string)
+	for _, p := range workflowsProyectos {
+		for _, t := range p.IssueTypes {
+			if t.Workflow == "" {
+				continue
+			}
+			if vistos[t.Workflow] == nil {
+				vistos[t.Workflow] = make(map[string]bool)
+			}
+			if !vistos[t.Workflow][p.ProjectKey] {
+				vistos[t.Workflow][p.ProjectKey] = true
+				resultado[t.Workflow] = append(resultado[t.Workflow], p.ProjectKey)
+			}
+		}
+	}
+	return resultado
+}
+
+// handleWorkflowClusters devuelve los grupos de workflows similares (?umbral=0.9 por defecto).
+func handleWorkflowClusters(w http.ResponseWriter, r *http.Request) {
+	umbral := umbralSimilitudDefecto
+	if valor := r.URL.Query().Get("umbral"); valor != "" {
+		u, err := strconv.ParseFloat(valor, 64)
+		if err != nil || u <= 0 || u > 1 {
+			http.Error(w, "El umbral debe ser un número entre 0 y 1", http.StatusBadRequest)
+			return
+		}
+		umbral = u
+	}
+
+	_, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var workflowsProyectos []JiraProjectWorkflows
+	if err := leerSeccion(snapshot, "workflowsProyectos", &workflowsProyectos); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, hayProyectos := snapshot["workflowsProyectos"]
+
+	informe := WorkflowClusterReport{
+		Threshold:         umbral,
+		ProjectsAvailable: hayProyectos,
+		Clusters:          agruparWorkflowsSimilares(workflows, proyectosPorWorkflow(workflowsProyectos), umbral),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(informe)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// idsLint devuelve los IDs de una lista de estados del análisis, en orden.
+func idsLint(estados []LintStatus) []string {
+	ids := []string{}
+	for _, st := range estados {
+		ids = append(ids, st.ID)
+	}
+	return ids
+}
+
+func TestAnalizarGrafoWorkflow(t *testing.T) {
+	categorias := map[string]string{"1": "TODO", "2": "IN_PROGRESS", "3": "DONE", "4": "IN_PROGRESS", "5": "TODO"}
+	estados := []WorkflowStatus{{ID: "1", Name: "To Do"}, {ID: "2", Name: "In Progress"}, {ID: "3", Name: "Done"}, {ID: "4", Name: "Blocked"}}
+
+	casos := []struct {
+		nombre       string
+		transiciones []Transition
+		inicial      bool
+		inalcanzable []string
+		sinSalida    []string
+		problemas    int
+	}{
+		{
+			nombre: "lineal con un estado suelto",
+			transiciones: []Transition{
+				{Type: "initial", To: "1"},
+				{Type: "directed", From: []string{"1"}, To: "2"},
+				{Type: "directed", From: []string{"2"}, To: "3"},
+			},
+			inicial:      true,
+			inalcanzable: []string{"4"},
+			sinSalida:    []string{"4"},
+			problemas:    2,
+		},
+		{
+			nombre: "la transición global da entrada y salida",
+			transiciones: []Transition{
+				{Type: "initial", To: "1"},
+				{Type: "directed", From: []string{"1"}, To: "3"},
+				{Type: "global", To: "4"},
+				{Type: "directed", From: []string{"4"}, To: "2"},
+			},
+			inicial:      true,
+			inalcanzable: []string{},
+			// Todos salen por la global hacia Blocked, y Blocked sale hacia In Progress
+			sinSalida: []string{},
+			problemas: 0,
+		},
+		{
+			nombre: "el destino de la única global no tiene salida",
+			transiciones: []Transition{
+				{Type: "initial", To: "1"},
+				{Type: "directed", From: []string{"1", "2"}, To: "3"},
+				{Type: "directed", From: []string{"3"}, To: "2"},
+				{Type: "global", To: "4"},
+			},
+			inicial:      true,
+			inalcanzable: []string{},
+			sinSalida:    []string{"4"},
+			problemas:    1,
+		},
+		{
+			nombre: "sin transición inicial",
+			transiciones: []Transition{
+				{Type: "directed", From: []string{"1"}, To: "2"},
+				{Type: "directed", From: []string{"2", "4"}, To: "3"},
+				{Type: "directed", From: []string{"3"}, To: "1"},
+			},
+			inicial:      false,
+			inalcanzable: []string{},
+			sinSalida:    []string{},
+			problemas:    1,
+		},
+		{
+			nombre: "estado solo referenciado en una transición",
+			transiciones: []Transition{
+				{Type: "initial", To: "1"},
+				{Type: "directed", From: []string{"1"}, To: "5"},
+				{Type: "directed", From: []string{"2", "4"}, To: "3"},
+			},
+			inicial:      true,
+			inalcanzable: []string{"2", "3", "4"},
+			sinSalida:    []string{"5"},
+			problemas:    4,
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			wf := JiraWorkflow{ID: WorkflowID{Name: caso.nombre}, Statuses: estados, Transitions: caso.transiciones}
+			lint := analizarGrafoWorkflow(wf, categorias)
+			if lint.HasInitialTransition != caso.inicial {
+				t.Errorf("HasInitialTransition = %v", lint.HasInitialTransition)
+			}
+			if got := idsLint(lint.Unreachable); !reflect.DeepEqual(got, caso.inalcanzable) {
+				t.Errorf("inalcanzables = %v, se esperaba %v", got, caso.inalcanzable)
+			}
+			if got := idsLint(lint.DeadEnds); !reflect.DeepEqual(got, caso.sinSalida) {
+				t.Errorf("sin salida = %v, se esperaba %v", got, caso.sinSalida)
+			}
+			if lint.Problems != caso.problemas {
+				t.Errorf("problemas = %d, se esperaba %d", lint.Problems, caso.problemas)
+			}
+		})
+	}
+}
+
+// workflowSimilitud construye un workflow con los estados 1-4 y las transiciones indicadas
+// como "origen>destino" ("^" es la inicial).
+func workflowSimilitud(nombre string, transiciones ...string) JiraWorkflow {
+	wf := JiraWorkflow{
+		ID:       WorkflowID{Name: nombre},
+		Statuses: []WorkflowStatus{{ID: "1", Name: "To Do"}, {ID: "2", Name: "In Progress"}, {ID: "3", Name: "Review"}, {ID: "4", Name: "Done"}},
+	}
+	for _, tr := range transiciones {
+		partes := strings.Split(tr, ">")
+		t := Transition{Name: tr, Type: "directed", From: []string{partes[0]}, To: partes[1]}
+		if partes[0] == "^" {
+			t.Type, t.From = "initial", nil
+		}
+		wf.Transitions = append(wf.Transitions, t)
+	}
+	return wf
+}
+
+func TestAgruparWorkflowsSimilares(t *testing.T) {
+	base := []string{"^>1", "1>2", "2>3", "3>4"}
+	workflows := []JiraWorkflow{
+		workflowSimilitud("Uno", base...),
+		workflowSimilitud("Dos", append(base, "4>1")...),
+		workflowSimilitud("Tres", append(base, "4>1", "2>4")...),
+		{ID: WorkflowID{Name: "Distinto"}, Statuses: []WorkflowStatus{{ID: "9", Name: "Abierto"}}, Transitions: []Transition{{Type: "initial", To: "9"}}},
+	}
+	proyectos := map[string][]string{"Tres": {"P1", "P2"}, "Uno": {"P3"}}
+
+	// Uno-Dos: (1 + 4/5) / 2 = 0.9; Dos-Tres: (1 + 5/6) / 2 ≈ 0.917; Uno-Tres: (1 + 4/6) / 2 ≈ 0.833
+	grupos := agruparWorkflowsSimilares(workflows, proyectos, 0.85)
+	if len(grupos) != 1 {
+		t.Fatalf("se esperaba un grupo y hay %d: %+v", len(grupos), grupos)
+	}
+	grupo := grupos[0]
+	var miembros []string
+	for _, m := range grupo.Members {
+		miembros = append(miembros, m.Workflow)
+	}
+	// El miembro con más proyectos va primero y es la base
+	if grupo.Base != "Tres" || !reflect.DeepEqual(miembros, []string{"Tres", "Uno", "Dos"}) {
+		t.Errorf("base %s, miembros %v", grupo.Base, miembros)
+	}
+	if grupo.Members[2].Projects == nil || len(grupo.Members[2].Projects) != 0 {
+		t.Errorf("un miembro sin proyectos debe tener la lista vacía: %#v", grupo.Members[2].Projects)
+	}
+	// Uno y Tres quedan en el grupo a través de Dos aunque no superan el umbral entre ellos
+	if grupo.MinScore != 0.833 {
+		t.Errorf("MinScore = %v, se esperaba 0.833", grupo.MinScore)
+	}
+	if len(grupo.Pairs) != 2 || grupo.Pairs[0].Score != 0.917 || grupo.Pairs[1].Score != 0.9 {
+		t.Errorf("pares = %+v", grupo.Pairs)
+	}
+
+	if grupos := agruparWorkflowsSimilares(workflows, proyectos, 0.95); len(grupos) != 0 {
+		t.Errorf("con umbral 0.95 no debería haber grupos: %+v", grupos)
+	}
+}
+
+func TestProyectosPorWorkflow(t *testing.T) {
+	asignaciones := []JiraProjectWorkflows{
+		{ProjectKey: "APP", IssueTypes: []JiraIssueTypeWorkflow{{Workflow: "Simple"}, {Workflow: "Simple"}, {Workflow: "Bugs"}}},
+		{ProjectKey: "WEB", IssueTypes: []JiraIssueTypeWorkflow{{Workflow: "Simple"}, {Workflow: ""}}},
+	}
+	esperado := map[string][]string{"Simple": {"APP", "WEB"}, "Bugs": {"APP"}}
+	if got := proyectosPorWorkflow(asignaciones); !reflect.DeepEqual(got, esperado) {
+		t.Errorf("proyectos por workflow = %v, se esperaba %v", got, esperado)
+	}
+}
@@ -12,25 +12,52 @@ import (
 	"github.com/go-resty/resty/v2"
 )
 
-// 2. Función actualizada para obtener los estados usando el endpoint /rest/api/3/statuses/search
+// 2. Función para obtener todos los estados usando el endpoint paginado /rest/api/3/statuses/search,
+// incluyendo los proyectos, tipos de incidencia y workflows que usan cada estado
 func obtenerEstadosJira(client *resty.Client) ([]JiraStatus, error) {
-	resp, err := client.R().Get("/rest/api/3/statuses/search")
+	params := url.Values{}
+	params.Add("expand", "usages,workflowUsages")
+
+	estados, err := obtenerPaginado[JiraStatus](client, "/rest/api/3/statuses/search", params, "estados")
 	if err != nil {
-		return nil, fmt.Errorf("error en petición a Jira (estados): %w", err)
-	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("error en la petición (estados): %d - %s", resp.StatusCode(), resp.Status())
+		return nil, err
 	}
 
-	var resBody JiraStatusSearchResponse
-	if err := json.Unmarshal(resp.Body(), &resBody); err != nil {
-		return nil, fmt.Errorf("error al parsear JSON (estados): %w", err)
+	log.Println("Total estados descargados:", len(estados))
+	return estados, nil
+}
+
+// obtenerEstadosPorIDJira descarga solo los estados indicados, con sus usos, para refrescar el
+// snapshot tras una escritura sin volver a recorrer todos los estados.
+func obtenerEstadosPorIDJira(client *resty.Client, ids []string) ([]JiraStatus, error) {
+	var estados []JiraStatus
+	for _, bloque := range agruparIDs(ids, maxEstadosPorPeticion) {
+		params := url.Values{"id": bloque}
+		params.Add("expand", "usages,workflowUsages")
+
+		var resBody []JiraStatus
+		if err := obtenerJSON(client, "/rest/api/3/statuses", params, "estados por ID", &resBody); err != nil {
+			return nil, err
+		}
+		estados = append(estados, resBody...)
 	}
-	return resBody.Values, nil
+	return estados, nil
 }
 
-// 3. Función para obtener todos los proyectos de Jira
+// 3. Función para obtener todos los proyectos activos de Jira
 func obtenerProyectosJira(client *resty.Client) ([]JiraProject, error) {
+	return buscarProyectosJira(client, "live")
+}
+
+// obtenerProyectosArchivadosJira descarga solo los proyectos archivados. Se guardan aparte porque
+// los endpoints por proyecto (permisos, esquemas...) suelen fallar con ellos.
+func obtenerProyectosArchivadosJira(client *resty.Client) ([]JiraProject, error) {
+	return buscarProyectosJira(client, "archived")
+}
+
+// buscarProyectosJira descarga los proyectos con el estado indicado (live o archived), con los
+// datos de responsable, descripción y URL.
+func buscarProyectosJira(client *resty.Client, estado string) ([]JiraProject, error) {
 	var allProjects []JiraProject
 	startAt := 0
 	maxResults := 50
@@ -40,6 +67,8 @@ func obtenerProyectosJira(client *resty.Client) ([]JiraProject, error) {
 		resp, err := client.R().
 			SetQueryParam("startAt", strconv.Itoa(startAt)).
 			SetQueryParam("maxResults", strconv.Itoa(maxResults)).
+			SetQueryParam("expand", "description,lead,url").
+			SetQueryParam("status", estado).
 			Get("/rest/api/2/project/search")
 		if err != nil {
 			return nil, fmt.Errorf("error en petición a Jira (proyectos): %w", err)
@@ -64,14 +93,35 @@ func obtenerProyectosJira(client *resty.Client) ([]JiraProject, error) {
 	return allProjects, nil
 }
 
+// obtenerCategoriasProyectoJira descarga todas las categorías de proyecto (no es paginado).
+func obtenerCategoriasProyectoJira(client *resty.Client) ([]ProjectCategory, error) {
+	var categorias []ProjectCategory
+	if err := obtenerJSON(client, "/rest/api/3/projectCategory", nil, "categorías de proyecto", &categorias); err != nil {
+		return nil, err
+	}
+	log.Println("Total categorías de proyecto descargadas:", len(categorias))
+	return categorias, nil
+}
+
+// obtenerCategoriaProyectoJira descarga una categoría de proyecto por ID.
+func obtenerCategoriaProyectoJira(client *resty.Client, id string) (ProjectCategory, error) {
+	var categoria ProjectCategory
+	err := obtenerJSON(client, "/rest/api/3/projectCategory/"+url.PathEscape(id), nil, "categoría de proyecto", &categoria)
+	return categoria, err
+}
+
 // Función para obtener todos los workflows y sus transiciones de Jira usando la API v3
 func obtenerWorkflowsJira(client *resty.Client) ([]JiraWorkflow, error) {
+	return buscarWorkflowsJira(client, nil)
+}
+
+// buscarWorkflowsJira descarga los workflows con sus transiciones; si se pasan nombres, solo esos.
+func buscarWorkflowsJira(client *resty.Client, workflowNames []string) ([]JiraWorkflow, error) {
 	var allWorkflows []JiraWorkflow
 	startAt := 0
 	maxResults := 50
 
 	// Parámetros opcionales (ajusta estos valores según tus necesidades)
-	workflowNames := []string{} // Ejemplo: []string{"Workflow1", "Workflow2"}
 	expand := "transitions,transitions.rules,transitions.properties,statuses,statuses.properties,default,schemes,projects,hasDraftWorkflow,operations"
 	queryString := "" // Cadena de búsqueda opcional
 	orderBy := "name" // Ordena por nombre (puede ser "created", "updated", etc.)
@@ -123,15 +173,621 @@ func obtenerWorkflowsJira(client *resty.Client) ([]JiraWorkflow, error) {
 	return allWorkflows, nil
 }
 
-// Función principal que ejecuta la consulta a Jira y agrupa todos los datos
-func ejecutarConsultaJira(domain, correo, token string, incluirProyectos, incluirWorkflows, incluirEstados bool) (map[string]interface{}, error) {
+// leerWorkflowsJira descarga con POST /rest/api/3/workflows la definición completa de los
+// workflows indicados por ID o por nombre, en el formato que aceptan la creación y la actualización.
+func leerWorkflowsJira(client *resty.Client, ids, nombres []string) (JiraWorkflowsReadResponse, error) {
+	var resBody JiraWorkflowsReadResponse
+	cuerpo := map[string][]string{}
+	if len(ids) > 0 {
+		cuerpo["workflowIds"] = ids
+	}
+	if len(nombres) > 0 {
+		cuerpo["workflowNames"] = nombres
+	}
+	err := enviarJSON(client, http.MethodPost, "/rest/api/3/workflows", nil, cuerpo, "leer workflow", &resBody)
+	return resBody, err
+}
+
+// obtenerReferenciaWorkflowJira devuelve el ID y la versión actual de un workflow, que hacen falta
+// para actualizarlo. Devuelve nil si no existe ningún workflow con ese nombre.
+func obtenerReferenciaWorkflowJira(client *resty.Client, nombre string) (*JiraWorkflowRef, error) {
+	resBody, err := leerWorkflowsJira(client, nil, []string{nombre})
+	if err != nil {
+		return nil, err
+	}
+	for _, wf := range resBody.Workflows {
+		if wf.Name == nombre && wf.Version != nil {
+			return &JiraWorkflowRef{ID: wf.ID, Name: wf.Name, Version: *wf.Version}, nil
+		}
+	}
+	return nil, nil
+}
+
+// obtenerWorkflowVivoJira devuelve la definición actual de un workflow, con las referencias de
+// estado de sus transiciones traducidas a IDs, o nil si no existe ningún workflow con ese nombre.
+func obtenerWorkflowVivoJira(client *resty.Client, nombre string) (*JiraWorkflowWrite, error) {
+	resBody, err := leerWorkflowsJira(client, nil, []string{nombre})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(resBody.Statuses))
+	for _, st := range resBody.Statuses {
+		ids[st.StatusReference] = st.ID
+	}
+	idDe := func(referencia string) string {
+		if id, ok := ids[referencia]; ok && id != "" {
+			return id
+		}
+		return referencia
+	}
+	for _, wf := range resBody.Workflows {
+		if wf.Name != nombre || wf.Version == nil {
+			continue
+		}
+		for i := range wf.Transitions {
+			t := &wf.Transitions[i]
+			t.ToStatusReference = idDe(t.ToStatusReference)
+			for j := range t.Links {
+				t.Links[j].FromStatusReference = idDe(t.Links[j].FromStatusReference)
+			}
+		}
+		return &wf, nil
+	}
+	return nil, nil
+}
+
+// obtenerPaginado recorre todas las páginas de un endpoint de Jira que responde con isLast/values.
+// "etiqueta" solo se usa para identificar la sección en los mensajes de error.
+func obtenerPaginado[T any](client *resty.Client, path string, params url.Values, etiqueta string) ([]T, error) {
+	var todos []T
+	startAt := 0
+	maxResults := 50
+
+	hasMore := true
+	for hasMore {
+		query := url.Values{}
+		for k, v := range params {
+			query[k] = v
+		}
+		query.Set("startAt", strconv.Itoa(startAt))
+		query.Set("maxResults", strconv.Itoa(maxResults))
+
+		resp, err := client.R().
+			SetQueryParamsFromValues(query).
+			Get(path)
+		if err != nil {
+			return nil, fmt.Errorf("error en petición a Jira (%s): %w", etiqueta, err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("error en la petición (%s): %d - %s", etiqueta, resp.StatusCode(), resp.Status())
+		}
+
+		var resBody JiraPaginatedResponse[T]
+		if err := json.Unmarshal(resp.Body(), &resBody); err != nil {
+			return nil, fmt.Errorf("error al parsear JSON (%s): %w", etiqueta, err)
+		}
+
+		todos = append(todos, resBody.Values...)
+		// Si una página llega vacía paramos para no entrar en un bucle infinito
+		hasMore = !resBody.IsLast && len(resBody.Values) > 0
+		if hasMore {
+			startAt += len(resBody.Values)
+		}
+	}
+	return todos, nil
+}
+
+// obtenerJSON hace una petición GET sin paginar y deserializa la respuesta en dest.
+func obtenerJSON(client *resty.Client, path string, params url.Values, etiqueta string, dest interface{}) error {
+	resp, err := client.R().
+		SetQueryParamsFromValues(params).
+		Get(path)
+	if err != nil {
+		return fmt.Errorf("error en petición a Jira (%s): %w", etiqueta, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("error en la petición (%s): %d - %s", etiqueta, resp.StatusCode(), resp.Status())
+	}
+	if err := json.Unmarshal(resp.Body(), dest); err != nil {
+		return fmt.Errorf("error al parsear JSON (%s): %w", etiqueta, err)
+	}
+	return nil
+}
+
+// agruparIDs divide una lista de IDs en bloques de como mucho "tam" elementos,
+// ya que varios endpoints de Jira limitan cuántos projectId admiten por petición.
+func agruparIDs(ids []string, tam int) [][]string {
+	var bloques [][]string
+	for len(ids) > tam {
+		bloques = append(bloques, ids[:tam])
+		ids = ids[tam:]
+	}
+	if len(ids) > 0 {
+		bloques = append(bloques, ids)
+	}
+	return bloques
+}
+
+// obtenerTiposIncidenciaJira descarga todos los tipos de incidencia visibles para el usuario.
+func obtenerTiposIncidenciaJira(client *resty.Client) ([]JiraIssueType, error) {
+	var tipos []JiraIssueType
+	if err := obtenerJSON(client, "/rest/api/3/issuetype", nil, "tipos de incidencia", &tipos); err != nil {
+		return nil, err
+	}
+	log.Println("Total tipos de incidencia descargados:", len(tipos))
+	return tipos, nil
+}
+
+// obtenerEsquemasTiposIncidenciaJira descarga los esquemas de tipos de incidencia y los completa con
+// los tipos que contiene cada uno y los proyectos (de la lista recibida) que lo tienen asignado.
+func obtenerEsquemasTiposIncidenciaJira(client *resty.Client, proyectos []JiraProject) ([]JiraIssueTypeScheme, error) {
+	esquemas, err := obtenerPaginado[JiraIssueTypeScheme](client, "/rest/api/3/issuetypescheme", nil, "esquemas de tipos de incidencia")
+	if err != nil {
+		return nil, err
+	}
+
+	// Índice por ID para ir rellenando tipos y proyectos de cada esquema
+	porID := make(map[string]*JiraIssueTypeScheme, len(esquemas))
+	for i := range esquemas {
+		esquemas[i].IssueTypeIDs = []string{}
+		esquemas[i].ProjectIDs = []string{}
+		porID[esquemas[i].ID] = &esquemas[i]
+	}
+
+	mapeos, err := obtenerPaginado[JiraIssueTypeSchemeMapping](client, "/rest/api/3/issuetypescheme/mapping", nil, "mapeo de esquemas de tipos de incidencia")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mapeos {
+		if esquema, ok := porID[m.IssueTypeSchemeID]; ok {
+			esquema.IssueTypeIDs = append(esquema.IssueTypeIDs, m.IssueTypeID)
+		}
+	}
+
+	// El endpoint de asociación con proyectos exige los IDs de proyecto (máximo 100 por petición)
+	var projectIDs []string
+	for _, p := range proyectos {
+		projectIDs = append(projectIDs, p.ID)
+	}
+	for _, bloque := range agruparIDs(projectIDs, 100) {
+		params := url.Values{}
+		for _, id := range bloque {
+			params.Add("projectId", id)
+		}
+		asociaciones, err := obtenerPaginado[JiraIssueTypeSchemeProjects](client, "/rest/api/3/issuetypescheme/project", params, "esquemas de tipos de incidencia por proyecto")
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range asociaciones {
+			if esquema, ok := porID[a.IssueTypeScheme.ID]; ok {
+				esquema.ProjectIDs = append(esquema.ProjectIDs, a.ProjectIDs...)
+			}
+		}
+	}
+
+	log.Println("Total esquemas de tipos de incidencia descargados:", len(esquemas))
+	return esquemas, nil
+}
+
+// esCampoConOpciones indica si el campo personalizado es de selección y por tanto tiene opciones.
+func esCampoConOpciones(schema *JiraFieldSchema) bool {
+	if schema == nil {
+		return false
+	}
+	switch schema.Custom {
+	case "com.atlassian.jira.plugin.system.customfieldtypes:select",
+		"com.atlassian.jira.plugin.system.customfieldtypes:multiselect",
+		"com.atlassian.jira.plugin.system.customfieldtypes:cascadingselect",
+		"com.atlassian.jira.plugin.system.customfieldtypes:radiobuttons",
+		"com.atlassian.jira.plugin.system.customfieldtypes:multicheckboxes":
+		return true
+	}
+	return false
+}
+
+// obtenerCamposJira descarga todos los campos y, para los personalizados, sus contextos con los
+// proyectos y tipos de incidencia a los que aplican y las opciones de los campos de selección.
+func obtenerCamposJira(client *resty.Client) ([]JiraField, error) {
+	campos, err := obtenerPaginado[JiraField](client, "/rest/api/3/field/search", nil, "campos")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range campos {
+		campo := &campos[i]
+		if campo.Schema == nil || campo.Schema.Custom == "" {
+			continue
+		}
+		// Algunos campos bloqueados o de apps no permiten leer contextos; se registran y se sigue
+		contextos, err := obtenerContextosCampoJira(client, campo)
+		if err != nil {
+			log.Printf("No se pudieron obtener los contextos del campo %s: %v", campo.ID, err)
+			continue
+		}
+		campo.Contexts = contextos
+	}
+
+	log.Println("Total campos descargados:", len(campos))
+	return campos, nil
+}
+
+// obtenerContextosCampoJira descarga los contextos de un campo personalizado y sus asociaciones.
+func obtenerContextosCampoJira(client *resty.Client, campo *JiraField) ([]JiraFieldContext, error) {
+	base := "/rest/api/3/field/" + url.PathEscape(campo.ID) + "/context"
+
+	contextos, err := obtenerPaginado[JiraFieldContext](client, base, nil, "contextos de "+campo.ID)
+	if err != nil {
+		return nil, err
+	}
+	porID := make(map[string]*JiraFieldContext, len(contextos))
+	for i := range contextos {
+		porID[contextos[i].ID] = &contextos[i]
+	}
+
+	mapeoProyectos, err := obtenerPaginado[JiraFieldContextProjectMapping](client, base+"/projectmapping", nil, "proyectos de contextos de "+campo.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mapeoProyectos {
+		if ctx, ok := porID[m.ContextID]; ok && m.ProjectID != "" {
+			ctx.ProjectIDs = append(ctx.ProjectIDs, m.ProjectID)
+		}
+	}
+
+	mapeoTipos, err := obtenerPaginado[JiraFieldContextIssueTypeMapping](client, base+"/issuetypemapping", nil, "tipos de contextos de "+campo.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mapeoTipos {
+		if ctx, ok := porID[m.ContextID]; ok && m.IssueTypeID != "" {
+			ctx.IssueTypeIDs = append(ctx.IssueTypeIDs, m.IssueTypeID)
+		}
+	}
+
+	if esCampoConOpciones(campo.Schema) {
+		for i := range contextos {
+			opciones, err := obtenerPaginado[JiraFieldOption](client, base+"/"+url.PathEscape(contextos[i].ID)+"/option", nil, "opciones de "+campo.ID)
+			if err != nil {
+				return nil, err
+			}
+			contextos[i].Options = opciones
+		}
+	}
+	return contextos, nil
+}
+
+// obtenerPantallasJira descarga todas las pantallas con sus pestañas y los campos de cada pestaña.
+func obtenerPantallasJira(client *resty.Client) ([]JiraScreen, error) {
+	pantallas, err := obtenerPaginado[JiraScreen](client, "/rest/api/3/screens", nil, "pantallas")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pantallas {
+		pantalla := &pantallas[i]
+		base := "/rest/api/3/screens/" + strconv.FormatInt(pantalla.ID, 10) + "/tabs"
+
+		// Si una pantalla no deja leer sus pestañas se registra y se sigue con las demás
+		var pestanas []JiraScreenTab
+		if err := obtenerJSON(client, base, nil, "pestañas de pantalla", &pestanas); err != nil {
+			log.Printf("No se pudieron obtener las pestañas de la pantalla %d: %v", pantalla.ID, err)
+			continue
+		}
+		for j := range pestanas {
+			path := base + "/" + strconv.FormatInt(pestanas[j].ID, 10) + "/fields"
+			if err := obtenerJSON(client, path, nil, "campos de pestaña", &pestanas[j].Fields); err != nil {
+				log.Printf("No se pudieron obtener los campos de la pestaña %d de la pantalla %d: %v", pestanas[j].ID, pantalla.ID, err)
+			}
+		}
+		pantalla.Tabs = pestanas
+	}
+
+	log.Println("Total pantallas descargadas:", len(pantallas))
+	return pantallas, nil
+}
+
+// obtenerEsquemasPantallasJira descarga los esquemas de pantallas.
+func obtenerEsquemasPantallasJira(client *resty.Client) ([]JiraScreenScheme, error) {
+	esquemas, err := obtenerPaginado[JiraScreenScheme](client, "/rest/api/3/screenscheme", nil, "esquemas de pantallas")
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Total esquemas de pantallas descargados:", len(esquemas))
+	return esquemas, nil
+}
+
+// obtenerEsquemasPantallasTiposJira descarga los esquemas de pantallas por tipo de incidencia con
+// su mapeo tipo → esquema de pantallas y los proyectos (de la lista recibida) que los usan.
+func obtenerEsquemasPantallasTiposJira(client *resty.Client, proyectos []JiraProject) ([]JiraIssueTypeScreenScheme, error) {
+	esquemas, err := obtenerPaginado[JiraIssueTypeScreenScheme](client, "/rest/api/3/issuetypescreenscheme", nil, "esquemas de pantallas por tipo")
+	if err != nil {
+		return nil, err
+	}
+
+	porID := make(map[string]*JiraIssueTypeScreenScheme, len(esquemas))
+	for i := range esquemas {
+		esquemas[i].Mappings = []JiraIssueTypeScreenSchemeMapping{}
+		esquemas[i].ProjectIDs = []string{}
+		porID[esquemas[i].ID] = &esquemas[i]
+	}
+
+	mapeos, err := obtenerPaginado[JiraIssueTypeScreenSchemeMapping](client, "/rest/api/3/issuetypescreenscheme/mapping", nil, "mapeo de esquemas de pantallas por tipo")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mapeos {
+		if esquema, ok := porID[m.IssueTypeScreenSchemeID]; ok {
+			esquema.Mappings = append(esquema.Mappings, JiraIssueTypeScreenSchemeMapping{
+				IssueTypeID:    m.IssueTypeID,
+				ScreenSchemeID: m.ScreenSchemeID,
+			})
+		}
+	}
+
+	var projectIDs []string
+	for _, p := range proyectos {
+		projectIDs = append(projectIDs, p.ID)
+	}
+	for _, bloque := range agruparIDs(projectIDs, 100) {
+		params := url.Values{}
+		for _, id := range bloque {
+			params.Add("projectId", id)
+		}
+		asociaciones, err := obtenerPaginado[JiraIssueTypeScreenSchemeProjects](client, "/rest/api/3/issuetypescreenscheme/project", params, "esquemas de pantallas por proyecto")
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range asociaciones {
+			if esquema, ok := porID[a.IssueTypeScreenScheme.ID]; ok {
+				esquema.ProjectIDs = append(esquema.ProjectIDs, a.ProjectIDs...)
+			}
+		}
+	}
+
+	log.Println("Total esquemas de pantallas por tipo descargados:", len(esquemas))
+	return esquemas, nil
+}
+
+// obtenerEsquemasPermisosJira descarga los esquemas de permisos con sus concesiones y
+// rellena el titular legible de cada una.
+func obtenerEsquemasPermisosJira(client *resty.Client) ([]JiraPermissionScheme, error) {
+	params := url.Values{}
+	params.Add("expand", "permissions,user,group,projectRole,field")
+
+	var resBody JiraPermissionSchemesResponse
+	if err := obtenerJSON(client, "/rest/api/3/permissionscheme", params, "esquemas de permisos", &resBody); err != nil {
+		return nil, err
+	}
+
+	esquemas := resBody.PermissionSchemes
+	for i := range esquemas {
+		for j := range esquemas[i].Permissions {
+			esquemas[i].Permissions[j].Titular = resolverTitularPermiso(esquemas[i].Permissions[j].Holder)
+		}
+	}
+
+	log.Println("Total esquemas de permisos descargados:", len(esquemas))
+	return esquemas, nil
+}
+
+// resolverTitularPermiso traduce el holder de una concesión a un texto legible.
+func resolverTitularPermiso(holder JiraPermissionHolder) string {
+	switch holder.Type {
+	case "group":
+		if holder.Group != nil {
+			return "Grupo: " + holder.Group.Name
+		}
+		if holder.Parameter == "" && holder.Value == "" {
+			return "Cualquier usuario conectado"
+		}
+		return "Grupo: " + holder.Parameter
+	case "projectRole":
+		if holder.ProjectRole != nil {
+			return "Rol de proyecto: " + holder.ProjectRole.Name
+		}
+		return "Rol de proyecto: " + holder.Parameter
+	case "user":
+		if holder.User != nil {
+			return "Usuario: " + holder.User.DisplayName
+		}
+		return "Usuario: " + holder.Parameter
+	case "applicationRole":
+		if holder.Parameter == "" {
+			return "Cualquier usuario con acceso a la aplicación"
+		}
+		return "Rol de aplicación: " + holder.Parameter
+	case "anyone":
+		return "Cualquiera (incluidos anónimos)"
+	case "projectLead":
+		return "Responsable del proyecto"
+	case "reporter":
+		return "Informador"
+	case "assignee":
+		return "Persona asignada"
+	case "userCustomField", "groupCustomField":
+		if holder.Field != nil {
+			return "Campo: " + holder.Field.Name
+		}
+		return "Campo: " + holder.Parameter
+	}
+	if holder.Parameter != "" {
+		return holder.Type + ": " + holder.Parameter
+	}
+	return holder.Type
+}
+
+// obtenerPermisosProyectosJira consulta el esquema de permisos de cada proyecto y agrupa,
+// por permiso, los titulares que lo tienen concedido. Los proyectos cuyo esquema no se puede
+// leer se devuelven con Error relleno.
+func obtenerPermisosProyectosJira(client *resty.Client, proyectos []JiraProject, esquemas []JiraPermissionScheme) ([]JiraProjectPermissions, error) {
+	porID := make(map[int64]JiraPermissionScheme, len(esquemas))
+	for _, e := range esquemas {
+		porID[e.ID] = e
+	}
+
+	var permisos []JiraProjectPermissions
+	for _, p := range proyectos {
+		var asignado struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		proyecto := JiraProjectPermissions{
+			ProjectID:   p.ID,
+			ProjectKey:  p.Key,
+			ProjectName: p.Name,
+			Permissions: make(map[string][]string),
+		}
+		// Un proyecto cuyo esquema no se puede leer se guarda con el error y no corta la descarga
+		path := "/rest/api/3/project/" + url.PathEscape(p.Key) + "/permissionscheme"
+		if err := obtenerJSON(client, path, nil, "esquema de permisos de "+p.Key, &asignado); err != nil {
+			log.Println("Error leyendo el esquema de permisos:", err)
+			proyecto.Error = err.Error()
+			permisos = append(permisos, proyecto)
+			continue
+		}
+		proyecto.SchemeID = asignado.ID
+		proyecto.SchemeName = asignado.Name
+		for _, grant := range porID[asignado.ID].Permissions {
+			proyecto.Permissions[grant.Permission] = append(proyecto.Permissions[grant.Permission], grant.Titular)
+		}
+		permisos = append(permisos, proyecto)
+	}
+	return permisos, nil
+}
+
+// obtenerEsquemasWorkflowJira descarga los esquemas de workflows y los proyectos
+// (de la lista recibida) que tiene asignado cada uno.
+func obtenerEsquemasWorkflowJira(client *resty.Client, proyectos []JiraProject) ([]JiraWorkflowScheme, error) {
+	esquemas, err := obtenerPaginado[JiraWorkflowScheme](client, "/rest/api/3/workflowscheme", nil, "esquemas de workflows")
+	if err != nil {
+		return nil, err
+	}
+
+	// Índice por clave; se guardan posiciones porque la lista crece con los esquemas que solo
+	// aparecen en las asignaciones
+	porClave := make(map[string]int, len(esquemas))
+	for i := range esquemas {
+		esquemas[i].ProjectIDs = []string{}
+		porClave[claveEsquemaWorkflow(esquemas[i])] = i
+	}
+
+	var projectIDs []string
+	for _, p := range proyectos {
+		projectIDs = append(projectIDs, p.ID)
+	}
+	for _, bloque := range agruparIDs(projectIDs, 100) {
+		params := url.Values{}
+		for _, id := range bloque {
+			params.Add("projectId", id)
+		}
+		var resBody JiraWorkflowSchemeProjectsResponse
+		if err := obtenerJSON(client, "/rest/api/3/workflowscheme/project", params, "esquemas de workflows por proyecto", &resBody); err != nil {
+			return nil, err
+		}
+		for _, a := range resBody.Values {
+			// El esquema por defecto puede venir sin ID o no estar en el listado: se toma de la
+			// propia respuesta para no perder los proyectos que lo usan
+			clave := claveEsquemaWorkflow(a.WorkflowScheme)
+			i, ok := porClave[clave]
+			if !ok {
+				nuevo := a.WorkflowScheme
+				nuevo.ProjectIDs = []string{}
+				esquemas = append(esquemas, nuevo)
+				i = len(esquemas) - 1
+				porClave[clave] = i
+			}
+			esquemas[i].ProjectIDs = append(esquemas[i].ProjectIDs, a.ProjectIDs...)
+		}
+	}
+
+	log.Println("Total esquemas de workflows descargados:", len(esquemas))
+	return esquemas, nil
+}
+
+// claveEsquemaWorkflow identifica un esquema de workflows por su ID o, si no lo trae (el esquema
+// por defecto), por su nombre.
+func claveEsquemaWorkflow(esquema JiraWorkflowScheme) string {
+	if esquema.ID != 0 {
+		return strconv.FormatInt(esquema.ID, 10)
+	}
+	return "nombre:" + esquema.Name
+}
+
+// calcularWorkflowsProyectos resuelve, para cada proyecto y cada tipo de incidencia de su esquema de
+// tipos, el workflow efectivo según su esquema de workflows y los estados de ese workflow.
+// Los proyectos team-managed no tienen esquema de workflows y se devuelven con el workflow vacío.
+func calcularWorkflowsProyectos(proyectos []JiraProject, tipos []JiraIssueType, esquemasTipos []JiraIssueTypeScheme, esquemasWorkflow []JiraWorkflowScheme, workflows []JiraWorkflow) []JiraProjectWorkflows {
+	nombresTipo := make(map[string]string, len(tipos))
+	for _, t := range tipos {
+		nombresTipo[t.ID] = t.Name
+	}
+	estadosWorkflow := make(map[string][]string, len(workflows))
+	for _, wf := range workflows {
+		nombres := []string{}
+		for _, st := range wf.Statuses {
+			nombres = append(nombres, st.Name)
+		}
+		estadosWorkflow[wf.ID.Name] = nombres
+	}
+	tiposProyecto := make(map[string][]string)
+	for _, e := range esquemasTipos {
+		for _, projectID := range e.ProjectIDs {
+			tiposProyecto[projectID] = e.IssueTypeIDs
+		}
+	}
+	esquemaProyecto := make(map[string]JiraWorkflowScheme)
+	for _, e := range esquemasWorkflow {
+		for _, projectID := range e.ProjectIDs {
+			esquemaProyecto[projectID] = e
+		}
+	}
+
+	var resultado []JiraProjectWorkflows
+	for _, p := range proyectos {
+		esquema, tieneEsquema := esquemaProyecto[p.ID]
+		proyecto := JiraProjectWorkflows{
+			ProjectID:   p.ID,
+			ProjectKey:  p.Key,
+			ProjectName: p.Name,
+			SchemeID:    esquema.ID,
+			SchemeName:  esquema.Name,
+			IssueTypes:  []JiraIssueTypeWorkflow{},
+		}
+		for _, tipoID := range tiposProyecto[p.ID] {
+			workflow := ""
+			if tieneEsquema {
+				// Orden de Jira: mapeo explícito del tipo, workflow por defecto del esquema y,
+				// si el esquema no define ninguno, el workflow de sistema "jira"
+				workflow = esquema.IssueTypeMappings[tipoID]
+				if workflow == "" {
+					workflow = esquema.DefaultWorkflow
+				}
+				if workflow == "" {
+					workflow = "jira"
+				}
+			}
+			proyecto.IssueTypes = append(proyecto.IssueTypes, JiraIssueTypeWorkflow{
+				IssueTypeID:   tipoID,
+				IssueTypeName: nombresTipo[tipoID],
+				Workflow:      workflow,
+				Statuses:      estadosWorkflow[workflow],
+			})
+		}
+		resultado = append(resultado, proyecto)
+	}
+	return resultado
+}
+
+// Función principal que ejecuta la consulta a Jira y agrupa todos los datos.
+// "opciones" indica qué secciones hay que descargar.
+func ejecutarConsultaJira(domain, correo, token string, opciones RequestData) (map[string]interface{}, error) {
 	client := conectarAJira(domain, correo, token)
 
 	// Creamos el JSON maestro donde se guardarán todos los datos
 	maestro := make(map[string]interface{})
 
 	// Consultamos estados si se requiere
-	if incluirEstados {
+	if opciones.Estados {
 		estados, err := obtenerEstadosJira(client)
 		if err != nil {
 			return nil, err
@@ -139,24 +795,126 @@ func ejecutarConsultaJira(domain, correo, token string, incluirProyectos, inclui
 		maestro["estados"] = estados
 	}
 
-	// Consultamos proyectos si se requiere
-	if incluirProyectos {
-		proyectos, err := obtenerProyectosJira(client)
+	// Los proyectos se descargan una sola vez aunque los necesiten varias secciones
+	var proyectos []JiraProject
+	if opciones.Proyectos || opciones.TiposIncidencia || opciones.Pantallas || opciones.Permisos || opciones.WorkflowsProyectos {
+		var err error
+		proyectos, err = obtenerProyectosJira(client)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	// Consultamos proyectos si se requiere
+	if opciones.Proyectos {
 		maestro["proyectos"] = proyectos
 	}
 
-	// Consultamos workflows si se requiere
-	if incluirWorkflows {
-		workflows, err := obtenerWorkflowsJira(client)
+	// Los archivados solo los usa el informe de proyectos
+	if opciones.ProyectosArchivados {
+		archivados, err := obtenerProyectosArchivadosJira(client)
 		if err != nil {
 			return nil, err
 		}
+		maestro["proyectosArchivados"] = archivados
+	}
+
+	// Consultamos workflows si se requiere (también hacen falta para el mapeo por proyecto)
+	var workflows []JiraWorkflow
+	if opciones.Workflows || opciones.WorkflowsProyectos {
+		var err error
+		workflows, err = obtenerWorkflowsJira(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opciones.Workflows {
 		maestro["workflows"] = workflows
 	}
 
+	// Consultamos el catálogo de categorías de proyecto si se requiere
+	if opciones.CategoriasProyecto {
+		categorias, err := obtenerCategoriasProyectoJira(client)
+		if err != nil {
+			return nil, err
+		}
+		maestro["categoriasProyecto"] = categorias
+	}
+
+	// Consultamos tipos de incidencia y sus esquemas si se requiere
+	var tipos []JiraIssueType
+	var esquemasTipos []JiraIssueTypeScheme
+	if opciones.TiposIncidencia || opciones.WorkflowsProyectos {
+		var err error
+		tipos, err = obtenerTiposIncidenciaJira(client)
+		if err != nil {
+			return nil, err
+		}
+		esquemasTipos, err = obtenerEsquemasTiposIncidenciaJira(client, proyectos)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opciones.TiposIncidencia {
+		maestro["tiposIncidencia"] = tipos
+		maestro["esquemasTiposIncidencia"] = esquemasTipos
+	}
+
+	// Consultamos esquemas de workflows y calculamos el workflow de cada proyecto y tipo si se requiere
+	if opciones.WorkflowsProyectos {
+		esquemasWorkflow, err := obtenerEsquemasWorkflowJira(client, proyectos)
+		if err != nil {
+			return nil, err
+		}
+		maestro["esquemasWorkflows"] = esquemasWorkflow
+		maestro["workflowsProyectos"] = calcularWorkflowsProyectos(proyectos, tipos, esquemasTipos, esquemasWorkflow, workflows)
+	}
+
+	// Consultamos el catálogo de campos si se requiere
+	if opciones.Campos {
+		campos, err := obtenerCamposJira(client)
+		if err != nil {
+			return nil, err
+		}
+		maestro["campos"] = campos
+	}
+
+	// Consultamos pantallas y sus esquemas si se requiere
+	if opciones.Pantallas {
+		pantallas, err := obtenerPantallasJira(client)
+		if err != nil {
+			return nil, err
+		}
+		maestro["pantallas"] = pantallas
+
+		esquemasPantallas, err := obtenerEsquemasPantallasJira(client)
+		if err != nil {
+			return nil, err
+		}
+		maestro["esquemasPantallas"] = esquemasPantallas
+
+		esquemasTipos, err := obtenerEsquemasPantallasTiposJira(client, proyectos)
+		if err != nil {
+			return nil, err
+		}
+		maestro["esquemasPantallasTipos"] = esquemasTipos
+	}
+
+	// Consultamos esquemas de permisos y su asignación a proyectos si se requiere
+	if opciones.Permisos {
+		esquemas, err := obtenerEsquemasPermisosJira(client)
+		if err != nil {
+			return nil, err
+		}
+		maestro["esquemasPermisos"] = esquemas
+
+		permisosProyectos, err := obtenerPermisosProyectosJira(client, proyectos, esquemas)
+		if err != nil {
+			return nil, err
+		}
+		maestro["permisosProyectos"] = permisosProyectos
+	}
+
 	return maestro, nil
 }
 
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ----------------------------------------------------------------
+// Borrado guardado de estados sin uso: se vuelve a comprobar en vivo que cada estado no
+// tiene workflows ni usos justo antes de borrarlo
+// ----------------------------------------------------------------
+
+// borradosDirPath devuelve la carpeta donde se registran los borrados de un dominio.
+func borradosDirPath(domain string) string {
+	return filepath.Join(jsonDirPath, "borrados", strings.TrimSuffix(generateFileName(domain), ".json"))
+}
+
+// verificarBorradoEstados descarga de Jira los estados indicados con sus usos y decide cuáles
+// se pueden borrar. Devuelve también los estados leídos para refrescar el snapshot.
+func verificarBorradoEstados(client *resty.Client, ids []string) ([]StatusDeleteCheck, []JiraStatus, error) {
+	vivos, err := obtenerEstadosPorIDJira(client, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	porID := make(map[string]JiraStatus, len(vivos))
+	for _, st := range vivos {
+		porID[st.ID] = st
+	}
+
+	comprobaciones := make([]StatusDeleteCheck, 0, len(ids))
+	vistos := make(map[string]bool)
+	for _, id := range ids {
+		if vistos[id] {
+			continue
+		}
+		vistos[id] = true
+
+		comprobacion := StatusDeleteCheck{ID: id, Result: "pending"}
+		st, ok := porID[id]
+		if !ok {
+			comprobacion.Reason = "Ya no existe en Jira"
+			comprobaciones = append(comprobaciones, comprobacion)
+			continue
+		}
+		comprobacion.Name = st.Name
+		comprobacion.StatusCategory = st.StatusCategory
+		comprobacion.UsageCount = len(st.Usages)
+		comprobacion.WorkflowUsageCount = len(st.WorkflowUsages)
+
+		var motivos []string
+		if comprobacion.WorkflowUsageCount > 0 {
+			var nombres []string
+			for _, wf := range st.WorkflowUsages {
+				nombres = append(nombres, wf.WorkflowName)
+			}
+			motivos = append(motivos, fmt.Sprintf("Lo usan %d workflows: %s", comprobacion.WorkflowUsageCount, strings.Join(nombres, ", ")))
+		}
+		if comprobacion.UsageCount > 0 {
+			motivos = append(motivos, fmt.Sprintf("Lo usan %d proyectos", comprobacion.UsageCount))
+		}
+		comprobacion.Deletable = len(motivos) == 0
+		comprobacion.Reason = strings.Join(motivos, "; ")
+		comprobaciones = append(comprobaciones, comprobacion)
+	}
+	return comprobaciones, vivos, nil
+}
+
+// borrarEstadosGuardado vuelve a verificar los estados, borra solo los que siguen sin uso, actualiza
+// el snapshot y registra el resultado en borrados/<dominio>/.
+func borrarEstadosGuardado(conn Credentials, ids []string) (StatusDeleteResult, error) {
+	resultado := StatusDeleteResult{ExecutedAt: time.Now().UTC(), Domain: conn.Domain}
+	client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+	abrirLoteDiario(client)
+	defer cerrarLoteDiario(client)
+
+	comprobaciones, vivos, err := verificarBorradoEstados(client, ids)
+	if err != nil {
+		return resultado, err
+	}
+
+	var aBorrar []string
+	for _, c := range comprobaciones {
+		if c.Deletable {
+			aBorrar = append(aBorrar, c.ID)
+		}
+	}
+	borrados, errBorrar := borrarEstadosJira(client, aBorrar)
+	borradoOK := make(map[string]bool, len(borrados))
+	for _, id := range borrados {
+		borradoOK[id] = true
+	}
+
+	// Los estados que no se borran se refrescan en el snapshot con los usos recién leídos
+	var refrescados []JiraStatus
+	for _, st := range vivos {
+		if !borradoOK[st.ID] {
+			refrescados = append(refrescados, st)
+		}
+	}
+	var desaparecidos []string
+	for i := range comprobaciones {
+		c := &comprobaciones[i]
+		switch {
+		case borradoOK[c.ID]:
+			c.Result = "deleted"
+			resultado.Deleted++
+			desaparecidos = append(desaparecidos, c.ID)
+		case c.Deletable:
+			c.Result = "error"
+			if errBorrar != nil {
+				c.Reason = errBorrar.Error()
+			}
+			resultado.Failed++
+		default:
+			c.Result = "skipped"
+			resultado.Skipped++
+			if c.Name == "" {
+				desaparecidos = append(desaparecidos, c.ID)
+			}
+		}
+	}
+	resultado.Statuses = comprobaciones
+
+	if err := guardarEstadosSnapshot(conn, refrescados, desaparecidos); err != nil {
+		log.Println("Error al actualizar los estados del snapshot:", err)
+	}
+	if err := registrarBorrado(resultado); err != nil {
+		log.Println("Error al registrar el borrado de estados:", err)
+	}
+	return resultado, nil
+}
+
+// registrarBorrado guarda el resultado de un borrado en borrados/<dominio>/<fecha>.json.
+func registrarBorrado(resultado StatusDeleteResult) error {
+	dir := borradosDirPath(resultado.Domain)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creando la carpeta de borrados: %w", err)
+	}
+	jsonBytes, err := json.MarshalIndent(resultado, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formateando JSON: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, resultado.ExecutedAt.Format(snapshotIDFormat)+".json"), jsonBytes, 0644)
+}
+
+// decodificarPeticionBorrado lee la lista de IDs candidatos del cuerpo de la petición.
+func decodificarPeticionBorrado(r *http.Request) (StatusDeleteRequest, error) {
+	var peticion StatusDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		return peticion, fmt.Errorf("error al decodificar JSON: %w", err)
+	}
+	if len(peticion.IDs) == 0 {
+		return peticion, fmt.Errorf("no se ha seleccionado ningún estado")
+	}
+	return peticion, nil
+}
+
+// handleVerifyDeleteStatuses comprueba en vivo los estados seleccionados y devuelve la lista final
+// para confirmar, sin borrar nada.
+func handleVerifyDeleteStatuses(w http.ResponseWriter, r *http.Request) {
+	peticion, err := decodificarPeticionBorrado(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conn, err := getCredentials()
+	if err != nil {
+		http.Error(w, "No hay conexión activa: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+	comprobaciones, _, err := verificarBorradoEstados(client, peticion.IDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comprobaciones)
+}
+
+// handleDeleteStatuses borra los estados confirmados que siguen sin uso y devuelve el resultado.
+func handleDeleteStatuses(w http.ResponseWriter, r *http.Request) {
+	peticion, err := decodificarPeticionBorrado(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !peticion.Confirm {
+		http.Error(w, "El borrado requiere confirmación", http.StatusBadRequest)
+		return
+	}
+	conn, err := getCredentials()
+	if err != nil {
+		http.Error(w, "No hay conexión activa: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := borrarEstadosGuardado(conn, peticion.IDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
@@ -1,5 +1,10 @@
 package main
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Estructura para recibir datos desde el formulario
 type RequestData struct {
 	Domain    string `json:"domain"`
@@ -8,6 +13,29 @@ type RequestData struct {
 	Proyectos bool   `json:"proyectos"`
 	Workflows bool   `json:"workflows"`
 	Estados   bool   `json:"estados"` // flag opcional para estados
+	// TiposIncidencia descarga los tipos de incidencia y los esquemas de tipos de incidencia
+	TiposIncidencia bool `json:"tiposIncidencia"`
+	// Campos descarga el catálogo de campos con sus contextos y opciones
+	Campos bool `json:"campos"`
+	// Pantallas descarga pantallas, esquemas de pantallas y esquemas de pantallas por tipo
+	Pantallas bool `json:"pantallas"`
+	// Permisos descarga los esquemas de permisos y resuelve quién tiene cada permiso por proyecto
+	Permisos bool `json:"permisos"`
+	// WorkflowsProyectos calcula el workflow efectivo de cada proyecto y tipo de incidencia
+	WorkflowsProyectos bool `json:"workflowsProyectos"`
+	// CategoriasProyecto descarga el catálogo de categorías de proyecto
+	CategoriasProyecto bool `json:"categoriasProyecto"`
+	// ProyectosArchivados descarga los proyectos archivados para el informe de proyectos
+	ProyectosArchivados bool `json:"proyectosArchivados"`
+}
+
+// Respuesta genérica de los endpoints paginados de Jira (isLast + values)
+type JiraPaginatedResponse[T any] struct {
+	IsLast     bool `json:"isLast"`
+	MaxResults int  `json:"maxResults"`
+	StartAt    int  `json:"startAt"`
+	Total      int  `json:"total"`
+	Values     []T  `json:"values"`
 }
 
 type JiraProjectSearchResponse struct {
@@ -15,12 +43,23 @@ type JiraProjectSearchResponse struct {
 	Values []JiraProject `json:"values"`
 }
 type JiraProject struct {
-	Key             string           `json:"key"`
-	Name            string           `json:"name"`
-	ProjectCategory *ProjectCategory `json:"projectCategory,omitempty"`
+	ID              string            `json:"id"`
+	Key             string            `json:"key"`
+	Name            string            `json:"name"`
+	ProjectCategory *ProjectCategory  `json:"projectCategory,omitempty"`
+	Lead            *JiraUser         `json:"lead,omitempty"`
+	ProjectTypeKey  string            `json:"projectTypeKey,omitempty"`
+	Style           string            `json:"style,omitempty"`      // classic (company-managed) o next-gen (team-managed)
+	Simplified      bool              `json:"simplified,omitempty"` // true en proyectos team-managed
+	Archived        bool              `json:"archived,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	URL             string            `json:"url,omitempty"`
+	AvatarURLs      map[string]string `json:"avatarUrls,omitempty"`
 }
 type ProjectCategory struct {
-	Name string `json:"name"`
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
 }
 
 type JiraWorkflowSearchResponse struct {
@@ -29,35 +68,906 @@ type JiraWorkflowSearchResponse struct {
 }
 
 type JiraWorkflow struct {
-	ID          WorkflowID   `json:"id"`
-	Transitions []Transition `json:"transitions"`
+	ID          WorkflowID       `json:"id"`
+	Description string           `json:"description,omitempty"`
+	Transitions []Transition     `json:"transitions"`
+	Statuses    []WorkflowStatus `json:"statuses"`
+}
+
+// Estado tal y como aparece dentro de un workflow (expand=statuses)
+type WorkflowStatus struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
 }
 
 type WorkflowID struct {
 	Name string `json:"name"`
 }
 
+// Transición de un workflow. Type vale initial, global o directed; From vacío en
+// las transiciones initial y global. From y To son IDs de estado.
 type Transition struct {
-	From []string `json:"from"`
-	To   string   `json:"to"`
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	From        []string               `json:"from"`
+	To          string                 `json:"to"`
+	Type        string                 `json:"type"`
+	Screen      *TransitionScreen      `json:"screen,omitempty"`
+	Rules       *TransitionRules       `json:"rules,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+type TransitionScreen struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Reglas de una transición. Jira devuelve las condiciones como árbol (conditionsTree);
+// Conditions solo viene relleno en respuestas antiguas.
+type TransitionRules struct {
+	Conditions     []WorkflowRule     `json:"conditions,omitempty"`
+	ConditionsTree *WorkflowCondition `json:"conditionsTree,omitempty"`
+	Validators     []WorkflowRule     `json:"validators,omitempty"`
+	PostFunctions  []WorkflowRule     `json:"postFunctions,omitempty"`
+}
+
+type WorkflowRule struct {
+	Type          string                 `json:"type"`
+	Configuration map[string]interface{} `json:"configuration,omitempty"`
+}
+
+// Nodo del árbol de condiciones: "simple" (Type + Configuration) o "compound" (Operator + Conditions)
+type WorkflowCondition struct {
+	NodeType      string                 `json:"nodeType"`
+	Operator      string                 `json:"operator,omitempty"`
+	Type          string                 `json:"type,omitempty"`
+	Configuration map[string]interface{} `json:"configuration,omitempty"`
+	Conditions    []WorkflowCondition    `json:"conditions,omitempty"`
+}
+
+// Cuerpo de POST /rest/api/3/workflows/create y /rest/api/3/workflows/update. Los estados y
+// transiciones se enlazan por statusReference; aquí se usa el ID del estado como referencia.
+type JiraWorkflowWriteRequest struct {
+	Scope     *JiraScope              `json:"scope,omitempty"`
+	Statuses  []JiraWorkflowStatusRef `json:"statuses"`
+	Workflows []JiraWorkflowWrite     `json:"workflows"`
+}
+
+type JiraWorkflowStatusRef struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	StatusCategory  string `json:"statusCategory"`
+	StatusReference string `json:"statusReference"`
+}
+
+// Workflow a crear (Name) o actualizar (ID y Version)
+type JiraWorkflowWrite struct {
+	ID          string                        `json:"id,omitempty"`
+	Name        string                        `json:"name,omitempty"`
+	Description string                        `json:"description"`
+	Version     *JiraWorkflowVersion          `json:"version,omitempty"`
+	Statuses    []JiraWorkflowWriteStatus     `json:"statuses"`
+	Transitions []JiraWorkflowWriteTransition `json:"transitions"`
+}
+
+type JiraWorkflowVersion struct {
+	ID            string `json:"id"`
+	VersionNumber int    `json:"versionNumber"`
+}
+
+type JiraWorkflowWriteStatus struct {
+	StatusReference string            `json:"statusReference"`
+	Properties      map[string]string `json:"properties"`
+}
+
+// Type vale INITIAL, GLOBAL o DIRECTED
+type JiraWorkflowWriteTransition struct {
+	ID                string                  `json:"id"`
+	Name              string                  `json:"name"`
+	Description       string                  `json:"description"`
+	Type              string                  `json:"type"`
+	ToStatusReference string                  `json:"toStatusReference"`
+	Links             []JiraTransitionLink    `json:"links"`
+	Conditions        *JiraConditionGroup     `json:"conditions,omitempty"`
+	Validators        []JiraWorkflowRuleWrite `json:"validators"`
+	Actions           []JiraWorkflowRuleWrite `json:"actions"`
+	TransitionScreen  *JiraWorkflowRuleWrite  `json:"transitionScreen,omitempty"`
+	Properties        map[string]string       `json:"properties"`
 }
 
-// Definición de la respuesta de búsqueda de estados en Jira
-type JiraStatusSearchResponse struct {
-	IsLast     bool         `json:"isLast"`
-	MaxResults int          `json:"maxResults"`
-	NextPage   string       `json:"nextPage"`
-	Self       string       `json:"self"`
-	StartAt    int          `json:"startAt"`
-	Total      int          `json:"total"`
-	Values     []JiraStatus `json:"values"`
+type JiraTransitionLink struct {
+	FromStatusReference string `json:"fromStatusReference"`
 }
 
-// Puedes ampliar JiraStatus si necesitas más campos; por ahora se mantienen los básicos.
+// Grupo de condiciones; Operation vale ALL o ANY
+type JiraConditionGroup struct {
+	Operation       string                  `json:"operation"`
+	Conditions      []JiraWorkflowRuleWrite `json:"conditions"`
+	ConditionGroups []JiraConditionGroup    `json:"conditionGroups"`
+}
+
+type JiraWorkflowRuleWrite struct {
+	RuleKey    string            `json:"ruleKey"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// Respuesta de POST /rest/api/3/workflows: definición completa de cada workflow en el mismo
+// formato que usan la creación y la actualización, junto con los estados que referencia
+type JiraWorkflowsReadResponse struct {
+	Statuses  []JiraWorkflowStatusRef `json:"statuses"`
+	Workflows []JiraWorkflowWrite     `json:"workflows"`
+}
+
+type JiraWorkflowRef struct {
+	ID      string              `json:"id"`
+	Name    string              `json:"name"`
+	Version JiraWorkflowVersion `json:"version"`
+}
+
+// Estado devuelto por /rest/api/3/statuses/search con expand=usages,workflowUsages.
+// StatusCategory vale TODO, IN_PROGRESS o DONE.
 type JiraStatus struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
+	ID             string                    `json:"id"`
+	Name           string                    `json:"name"`
+	Description    string                    `json:"description,omitempty"`
+	StatusCategory string                    `json:"statusCategory,omitempty"`
+	Scope          *JiraScope                `json:"scope,omitempty"`
+	Usages         []JiraStatusUsage         `json:"usages,omitempty"`
+	WorkflowUsages []JiraStatusWorkflowUsage `json:"workflowUsages,omitempty"`
+}
+
+// Cuerpo de POST /rest/api/3/statuses
+type JiraStatusCreateRequest struct {
+	Scope    JiraScope          `json:"scope"`
+	Statuses []JiraStatusCreate `json:"statuses"`
+}
+
+type JiraStatusCreate struct {
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	StatusCategory string `json:"statusCategory"`
+}
+
+// Proyecto y tipos de incidencia que usan un estado
+type JiraStatusUsage struct {
+	Project    JiraScopeProject `json:"project"`
+	IssueTypes []string         `json:"issueTypes"`
+}
+
+// Workflow que usa un estado
+type JiraStatusWorkflowUsage struct {
+	WorkflowID   string `json:"workflowId"`
+	WorkflowName string `json:"workflowName"`
+}
+
+// Ámbito de un objeto de Jira: global o de un proyecto concreto (team-managed)
+type JiraScope struct {
+	Type    string            `json:"type"`
+	Project *JiraScopeProject `json:"project,omitempty"`
+}
+
+type JiraScopeProject struct {
+	ID string `json:"id"`
+}
+
+// Tipo de incidencia devuelto por /rest/api/3/issuetype
+type JiraIssueType struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	Subtask        bool       `json:"subtask"`
+	HierarchyLevel int        `json:"hierarchyLevel"`
+	IconURL        string     `json:"iconUrl,omitempty"`
+	Scope          *JiraScope `json:"scope,omitempty"`
+}
+
+// Esquema de tipos de incidencia con los tipos que contiene y los proyectos que lo usan
+type JiraIssueTypeScheme struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	Description        string   `json:"description,omitempty"`
+	DefaultIssueTypeID string   `json:"defaultIssueTypeId,omitempty"`
+	IsDefault          bool     `json:"isDefault"`
+	IssueTypeIDs       []string `json:"issueTypeIds"`
+	ProjectIDs         []string `json:"projectIds"`
+}
+
+// Elemento de /rest/api/3/issuetypescheme/mapping
+type JiraIssueTypeSchemeMapping struct {
+	IssueTypeSchemeID string `json:"issueTypeSchemeId"`
+	IssueTypeID       string `json:"issueTypeId"`
+}
+
+// Elemento de /rest/api/3/issuetypescheme/project
+type JiraIssueTypeSchemeProjects struct {
+	IssueTypeScheme JiraIssueTypeScheme `json:"issueTypeScheme"`
+	ProjectIDs      []string            `json:"projectIds"`
+}
+
+// Campo devuelto por /rest/api/3/field/search; los contextos solo se rellenan en campos personalizados
+type JiraField struct {
+	ID          string             `json:"id"`
+	Key         string             `json:"key,omitempty"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Schema      *JiraFieldSchema   `json:"schema,omitempty"`
+	SearcherKey string             `json:"searcherKey,omitempty"`
+	IsLocked    bool               `json:"isLocked"`
+	Contexts    []JiraFieldContext `json:"contexts,omitempty"`
+}
+
+type JiraFieldSchema struct {
+	Type     string `json:"type"`
+	Items    string `json:"items,omitempty"`
+	System   string `json:"system,omitempty"`
+	Custom   string `json:"custom,omitempty"`
+	CustomID int64  `json:"customId,omitempty"`
+}
+
+// Contexto de un campo personalizado con los proyectos y tipos de incidencia a los que aplica
+type JiraFieldContext struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	IsGlobalContext bool              `json:"isGlobalContext"`
+	IsAnyIssueType  bool              `json:"isAnyIssueType"`
+	ProjectIDs      []string          `json:"projectIds,omitempty"`
+	IssueTypeIDs    []string          `json:"issueTypeIds,omitempty"`
+	Options         []JiraFieldOption `json:"options,omitempty"`
+}
+
+// Opción de un campo de selección; OptionID indica la opción padre en los selects en cascada
+type JiraFieldOption struct {
+	ID       string `json:"id"`
+	Value    string `json:"value"`
+	OptionID string `json:"optionId,omitempty"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Elemento de /rest/api/3/field/{fieldId}/context/projectmapping
+type JiraFieldContextProjectMapping struct {
+	ContextID       string `json:"contextId"`
+	ProjectID       string `json:"projectId,omitempty"`
+	IsGlobalContext bool   `json:"isGlobalContext"`
+}
+
+// Elemento de /rest/api/3/field/{fieldId}/context/issuetypemapping
+type JiraFieldContextIssueTypeMapping struct {
+	ContextID      string `json:"contextId"`
+	IssueTypeID    string `json:"issueTypeId,omitempty"`
+	IsAnyIssueType bool   `json:"isAnyIssueType"`
+}
+
+// Pantalla con sus pestañas y los campos de cada pestaña
+type JiraScreen struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Scope       *JiraScope      `json:"scope,omitempty"`
+	Tabs        []JiraScreenTab `json:"tabs,omitempty"`
+}
+
+type JiraScreenTab struct {
+	ID     int64             `json:"id"`
+	Name   string            `json:"name"`
+	Fields []JiraScreenField `json:"fields,omitempty"`
+}
+
+type JiraScreenField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Esquema de pantallas: pantalla usada en cada operación (default, create, edit, view)
+type JiraScreenScheme struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Screens     map[string]int64 `json:"screens"`
+}
+
+// Esquema de pantallas por tipo de incidencia con su mapeo y los proyectos que lo usan
+type JiraIssueTypeScreenScheme struct {
+	ID          string                             `json:"id"`
+	Name        string                             `json:"name"`
+	Description string                             `json:"description,omitempty"`
+	Mappings    []JiraIssueTypeScreenSchemeMapping `json:"mappings"`
+	ProjectIDs  []string                           `json:"projectIds"`
+}
+
+// Elemento de /rest/api/3/issuetypescreenscheme/mapping; IssueTypeID puede ser "default"
+type JiraIssueTypeScreenSchemeMapping struct {
+	IssueTypeScreenSchemeID string `json:"issueTypeScreenSchemeId,omitempty"`
+	IssueTypeID             string `json:"issueTypeId"`
+	ScreenSchemeID          string `json:"screenSchemeId"`
+}
+
+// Elemento de /rest/api/3/issuetypescreenscheme/project
+type JiraIssueTypeScreenSchemeProjects struct {
+	IssueTypeScreenScheme JiraIssueTypeScreenScheme `json:"issueTypeScreenScheme"`
+	ProjectIDs            []string                  `json:"projectIds"`
+}
+
+// Respuesta de /rest/api/3/permissionscheme
+type JiraPermissionSchemesResponse struct {
+	PermissionSchemes []JiraPermissionScheme `json:"permissionSchemes"`
+}
+
+type JiraPermissionScheme struct {
+	ID          int64                 `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Permissions []JiraPermissionGrant `json:"permissions"`
+}
+
+// Concesión de un permiso; Titular es la descripción legible del Holder calculada al descargar
+type JiraPermissionGrant struct {
+	ID         int64                `json:"id"`
+	Permission string               `json:"permission"`
+	Holder     JiraPermissionHolder `json:"holder"`
+	Titular    string               `json:"titular"`
+}
+
+type JiraPermissionHolder struct {
+	Type        string           `json:"type"`
+	Parameter   string           `json:"parameter,omitempty"`
+	Value       string           `json:"value,omitempty"`
+	User        *JiraUser        `json:"user,omitempty"`
+	Group       *JiraGroup       `json:"group,omitempty"`
+	ProjectRole *JiraProjectRole `json:"projectRole,omitempty"`
+	Field       *JiraField       `json:"field,omitempty"`
+}
+
+type JiraUser struct {
+	AccountID   string `json:"accountId"`
+	DisplayName string `json:"displayName"`
+}
+
+type JiraGroup struct {
+	Name    string `json:"name"`
+	GroupID string `json:"groupId,omitempty"`
+}
+
+type JiraProjectRole struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Permisos efectivos de un proyecto: permiso → titulares legibles
+type JiraProjectPermissions struct {
+	ProjectID   string              `json:"projectId"`
+	ProjectKey  string              `json:"projectKey"`
+	ProjectName string              `json:"projectName"`
+	SchemeID    int64               `json:"schemeId"`
+	SchemeName  string              `json:"schemeName"`
+	Permissions map[string][]string `json:"permissions"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// Esquema de workflows: workflow por tipo de incidencia y workflow por defecto
+type JiraWorkflowScheme struct {
+	ID                int64             `json:"id"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description,omitempty"`
+	DefaultWorkflow   string            `json:"defaultWorkflow"`
+	IssueTypeMappings map[string]string `json:"issueTypeMappings"`
+	ProjectIDs        []string          `json:"projectIds"`
+}
+
+// Respuesta de /rest/api/3/workflowscheme/project
+type JiraWorkflowSchemeProjectsResponse struct {
+	Values []struct {
+		ProjectIDs     []string           `json:"projectIds"`
+		WorkflowScheme JiraWorkflowScheme `json:"workflowScheme"`
+	} `json:"values"`
+}
+
+// Workflow efectivo de cada tipo de incidencia de un proyecto
+type JiraProjectWorkflows struct {
+	ProjectID   string                  `json:"projectId"`
+	ProjectKey  string                  `json:"projectKey"`
+	ProjectName string                  `json:"projectName"`
+	SchemeID    int64                   `json:"schemeId,omitempty"`
+	SchemeName  string                  `json:"schemeName"`
+	IssueTypes  []JiraIssueTypeWorkflow `json:"issueTypes"`
+}
+
+type JiraIssueTypeWorkflow struct {
+	IssueTypeID   string   `json:"issueTypeId"`
+	IssueTypeName string   `json:"issueTypeName"`
+	Workflow      string   `json:"workflow"`
+	Statuses      []string `json:"statuses"`
+}
+
+// Estado que ningún workflow del snapshot referencia, con la evidencia recogida
+type UnusedStatus struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	StatusCategory     string   `json:"statusCategory"`
+	Scope              string   `json:"scope"`
+	UsageCount         int      `json:"usageCount"`
+	WorkflowUsageCount int      `json:"workflowUsageCount"`
+	SafeToDelete       bool     `json:"safeToDelete"`
+	Evidence           []string `json:"evidence"`
+}
+
+// Grupo de estados cuyos nombres se consideran equivalentes, con el estado canónico propuesto
+type DuplicateStatusGroup struct {
+	Key           string          `json:"key"`
+	CanonicalID   string          `json:"canonicalId"`
+	CanonicalName string          `json:"canonicalName"`
+	Variants      []StatusVariant `json:"variants"`
+}
+
+// Variante de un grupo de duplicados y los workflows que la usan
+type StatusVariant struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	StatusCategory string   `json:"statusCategory"`
+	Scope          string   `json:"scope"`
+	Workflows      []string `json:"workflows"`
+}
+
+// Resultado del análisis del grafo de un workflow
+type WorkflowLint struct {
+	Workflow             string       `json:"workflow"`
+	HasInitialTransition bool         `json:"hasInitialTransition"`
+	Unreachable          []LintStatus `json:"unreachable"`
+	DeadEnds             []LintStatus `json:"deadEnds"`
+	Problems             int          `json:"problems"`
+}
+
+type LintStatus struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	StatusCategory string `json:"statusCategory,omitempty"`
+}
+
+// Informe de coherencia entre el nombre de los estados y su categoría
+type CategoryConsistencyReport struct {
+	Statuses  []CategoryMismatch        `json:"statuses"`
+	Workflows []WorkflowFinalCategories `json:"workflows"`
+}
+
+// Estado cuyo nombre sugiere una categoría distinta de la que tiene
+type CategoryMismatch struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	StatusCategory   string   `json:"statusCategory"`
+	ExpectedCategory string   `json:"expectedCategory"`
+	Keyword          string   `json:"keyword"`
+	Workflows        []string `json:"workflows"`
+}
+
+// Workflow con estados finales (sin salida) fuera de la categoría Done
+type WorkflowFinalCategories struct {
+	Workflow    string       `json:"workflow"`
+	FinalStates []LintStatus `json:"finalStates"`
+	NotDone     []LintStatus `json:"notDone"`
+}
+
+// Informe de proyectos por categoría y responsable
+type ProjectReport struct {
+	Total           int                    `json:"total"`
+	Archived        int                    `json:"archived"`
+	TeamManaged     int                    `json:"teamManaged"`
+	ByCategory      []ProjectCategoryGroup `json:"byCategory"`
+	WithoutCategory []JiraProject          `json:"withoutCategory"`
+	ByLead          []ProjectLeadCount     `json:"byLead"`
+}
+
+type ProjectCategoryGroup struct {
+	Category string        `json:"category"`
+	Projects []JiraProject `json:"projects"`
+}
+
+type ProjectLeadCount struct {
+	AccountID string   `json:"accountId"`
+	Lead      string   `json:"lead"`
+	Count     int      `json:"count"`
+	Projects  []string `json:"projects"`
+}
+
+// Petición de alta masiva de estados desde un CSV (nombre, descripción, categoría)
+type StatusBulkCreateRequest struct {
+	CSV    string `json:"csv"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// Resultado del alta masiva con el detalle de cada fila del CSV
+type StatusBulkCreateResult struct {
+	DryRun   bool              `json:"dryRun"`
+	Created  int               `json:"created"`
+	Existing int               `json:"existing"`
+	Invalid  int               `json:"invalid"`
+	Failed   int               `json:"failed"`
+	Rows     []StatusCreateRow `json:"rows"`
+}
+
+// Fila del CSV. Action es lo que se haría (create, exists, invalid) y Result lo que pasó
+// (pending en dry-run, created, skipped o error).
+type StatusCreateRow struct {
+	Row            int    `json:"row"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	StatusCategory string `json:"statusCategory"`
+	Action         string `json:"action"`
+	Result         string `json:"result"`
+	ID             string `json:"id,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
+// Petición de edición masiva de estados. Cada cambio lleva el ID y los valores nuevos de
+// nombre, descripción y categoría.
+type StatusBulkEditRequest struct {
+	Changes []StatusEditChange `json:"changes"`
+	DryRun  bool               `json:"dryRun"`
+}
+
+// Cambio de un estado en la edición masiva. Un campo que no se envía (null) se deja como está;
+// si se envía, sustituye al valor actual, así que una descripción vacía la borra.
+type StatusEditChange struct {
+	ID             string  `json:"id"`
+	Name           *string `json:"name,omitempty"`
+	Description    *string `json:"description,omitempty"`
+	StatusCategory *string `json:"statusCategory,omitempty"`
+}
+
+// Estado tal y como lo espera PUT /rest/api/3/statuses
+type JiraStatusUpdate struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	StatusCategory string `json:"statusCategory"`
+}
+
+type JiraStatusUpdateRequest struct {
+	Statuses []JiraStatusUpdate `json:"statuses"`
+}
+
+// Resultado de la edición masiva con el antes y el después de cada estado
+type StatusBulkEditResult struct {
+	DryRun  bool             `json:"dryRun"`
+	Updated int              `json:"updated"`
+	Invalid int              `json:"invalid"`
+	Failed  int              `json:"failed"`
+	Diffs   []StatusEditDiff `json:"diffs"`
+}
+
+// Action es update, unchanged o invalid; Result es pending en dry-run, updated, skipped o error.
+type StatusEditDiff struct {
+	ID      string           `json:"id"`
+	Before  JiraStatusUpdate `json:"before"`
+	After   JiraStatusUpdate `json:"after"`
+	Changes []string         `json:"changes"`
+	Action  string           `json:"action"`
+	Result  string           `json:"result"`
+	Message string           `json:"message,omitempty"`
+}
+
+// Petición de verificación o borrado de estados. El borrado exige Confirm.
+type StatusDeleteRequest struct {
+	IDs     []string `json:"ids"`
+	Confirm bool     `json:"confirm"`
+}
+
+// Comprobación en vivo de un estado candidato a borrar. Result es pending al verificar y
+// deleted, skipped o error tras el borrado.
+type StatusDeleteCheck struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	StatusCategory     string `json:"statusCategory,omitempty"`
+	UsageCount         int    `json:"usageCount"`
+	WorkflowUsageCount int    `json:"workflowUsageCount"`
+	Deletable          bool   `json:"deletable"`
+	Reason             string `json:"reason,omitempty"`
+	Result             string `json:"result"`
+}
+
+// Resultado de un borrado de estados; se guarda también en borrados/<dominio>/<id>.json
+type StatusDeleteResult struct {
+	ExecutedAt time.Time           `json:"executedAt"`
+	Domain     string              `json:"domain"`
+	Deleted    int                 `json:"deleted"`
+	Skipped    int                 `json:"skipped"`
+	Failed     int                 `json:"failed"`
+	Statuses   []StatusDeleteCheck `json:"statuses"`
+}
+
+// Petición para validar y, con Apply, crear o actualizar un workflow desde su definición
+type WorkflowFileRequest struct {
+	Content string `json:"content"`
+	Apply   bool   `json:"apply"`
+}
+
+// Resultado de validar (y aplicar) un workflow declarado en un fichero. Action es create o update.
+type WorkflowFileResult struct {
+	Workflow string   `json:"workflow"`
+	Action   string   `json:"action"`
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+	Applied  bool     `json:"applied"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// Estado deseado de la configuración de un sitio (fichero YAML o JSON). Lo que no aparece en el
+// fichero no se toca salvo que se active su borrado en Eliminar.
+type DesiredState struct {
+	Estados            []JiraStatusCreate `json:"estados"`
+	CategoriasProyecto []ProjectCategory  `json:"categoriasProyecto"`
+	Workflows          []JiraWorkflow     `json:"workflows"`
+	Eliminar           DesiredDeletes     `json:"eliminar"`
+}
+
+type DesiredDeletes struct {
+	Estados            bool `json:"estados"`
+	CategoriasProyecto bool `json:"categoriasProyecto"`
+}
+
+// Plan de cambios calculado contra el sitio en vivo. Fingerprint es la huella de la configuración
+// leída al planificar; si al aplicar no coincide, el plan se rechaza.
+type ConfigPlan struct {
+	ID          string       `json:"id"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	Domain      string       `json:"domain"`
+	Fingerprint string       `json:"fingerprint"`
+	Desired     DesiredState `json:"desired"`
+	Changes     []PlanChange `json:"changes"`
+	Errors      []string     `json:"errors"`
+	Warnings    []string     `json:"warnings"`
+	AppliedAt   *time.Time   `json:"appliedAt,omitempty"`
+}
+
+// Cambio de un objeto. ObjectType es estado, categoriaProyecto o workflow; Action es create,
+// update o delete; Result (tras aplicar) es applied, skipped o error.
+type PlanChange struct {
+	ObjectType string   `json:"objectType"`
+	Action     string   `json:"action"`
+	Name       string   `json:"name"`
+	ID         string   `json:"id,omitempty"`
+	Changes    []string `json:"changes"`
+	Result     string   `json:"result,omitempty"`
+	Message    string   `json:"message,omitempty"`
+}
+
+// Entrada del diario de escrituras. Cada llamada de escritura a Jira genera una entrada y las que
+// se hacen dentro de una misma operación comparten Batch. Operation es crearEstados,
+// actualizarEstados, borrarEstados, crearWorkflow, actualizarWorkflow, borrarWorkflow,
+// crearCategoriaProyecto, actualizarCategoriaProyecto o borrarCategoriaProyecto.
+type JournalEntry struct {
+	ID         string        `json:"id"`
+	Batch      string        `json:"batch"`
+	Domain     string        `json:"domain"`
+	ExecutedAt time.Time     `json:"executedAt"`
+	Operation  string        `json:"operation"`
+	ObjectType string        `json:"objectType"`
+	Items      []JournalItem `json:"items"`
+	Error      string        `json:"error,omitempty"`
+	RevertOf   string        `json:"revertOf,omitempty"`
+}
+
+// Objeto afectado por una escritura. Before es el objeto leído de Jira justo antes de escribir
+// (vacío en las altas) y After lo enviado o devuelto por Jira (vacío en los borrados).
+// RevertedBy es la entrada que deshizo el cambio.
+type JournalItem struct {
+	ID         string          `json:"id,omitempty"`
+	Name       string          `json:"name"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Applied    bool            `json:"applied"`
+	RevertedBy string          `json:"revertedBy,omitempty"`
+}
+
+// Petición para deshacer un lote entero (Batch), una entrada (Entry) o un solo objeto de una
+// entrada (Entry e Item, posición del objeto en la entrada)
+type JournalRevertRequest struct {
+	Batch string `json:"batch"`
+	Entry string `json:"entry"`
+	Item  *int   `json:"item,omitempty"`
+}
+
+// Resultado de deshacer cambios del diario; las escrituras de la reversión forman el lote Batch
+type JournalRevertResult struct {
+	Batch    string              `json:"batch"`
+	Reverted int                 `json:"reverted"`
+	Skipped  int                 `json:"skipped"`
+	Failed   int                 `json:"failed"`
+	Items    []JournalRevertItem `json:"items"`
+}
+
+// Result es reverted, skipped o error
+type JournalRevertItem struct {
+	Entry     string `json:"entry"`
+	Operation string `json:"operation"`
+	Name      string `json:"name"`
+	Result    string `json:"result"`
+	Message   string `json:"message,omitempty"`
+}
+
+type PlanRequest struct {
+	Content string `json:"content"`
+}
+
+type PlanApplyRequest struct {
+	ID      string `json:"id"`
+	Approve bool   `json:"approve"`
+}
+
+// Petición para copiar workflows del snapshot de una conexión guardada (Source) a otra (Target,
+// ambas por índice). Overrides asigna a un estado del origen (por nombre) el estado del destino
+// que debe usarse (por nombre o ID) en lugar del que tenga el mismo nombre.
+type MigrationRequest struct {
+	Source    int               `json:"source"`
+	Target    int               `json:"target"`
+	Workflows []string          `json:"workflows"`
+	Overrides map[string]string `json:"overrides"`
+	Apply     bool              `json:"apply"`
+}
+
+// Previsualización o resultado de una copia de workflows. Batch es el lote del diario con las
+// escrituras hechas en el destino.
+type MigrationResult struct {
+	SourceDomain string              `json:"sourceDomain"`
+	TargetDomain string              `json:"targetDomain"`
+	Applied      bool                `json:"applied"`
+	Batch        string              `json:"batch,omitempty"`
+	Statuses     []MigrationStatus   `json:"statuses"`
+	Workflows    []MigrationWorkflow `json:"workflows"`
+}
+
+// Correspondencia de un estado del origen. Action es map (mismo nombre), override (asignación
+// manual), create o error; Result es pending, mapped, created, skipped o error.
+type MigrationStatus struct {
+	SourceID       string   `json:"sourceId"`
+	SourceName     string   `json:"sourceName"`
+	StatusCategory string   `json:"statusCategory,omitempty"`
+	TargetID       string   `json:"targetId,omitempty"`
+	TargetName     string   `json:"targetName,omitempty"`
+	Action         string   `json:"action"`
+	Workflows      []string `json:"workflows"`
+	Result         string   `json:"result"`
+	Message        string   `json:"message,omitempty"`
+}
+
+// Workflow a copiar. Action es create o skip; Result es pending, created, skipped o error.
+type MigrationWorkflow struct {
+	Name     string   `json:"name"`
+	Action   string   `json:"action"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+	Result   string   `json:"result"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// Diferencias entre dos workflows. Los estados se comparan por nombre para que la comparación
+// funcione entre sitios distintos, donde los IDs no coinciden. Por lo mismo, entre sitios
+// distintos las reglas se comparan solo por tipo (RulesByType).
+type WorkflowDiff struct {
+	WorkflowA             string           `json:"workflowA"`
+	WorkflowB             string           `json:"workflowB"`
+	DomainA               string           `json:"domainA"`
+	DomainB               string           `json:"domainB"`
+	StatusesAdded         []string         `json:"statusesAdded"`
+	StatusesRemoved       []string         `json:"statusesRemoved"`
+	TransitionsAdded      []TransitionDiff `json:"transitionsAdded"`
+	TransitionsRemoved    []TransitionDiff `json:"transitionsRemoved"`
+	TransitionsRetargeted []TransitionDiff `json:"transitionsRetargeted"`
+	RulesChanged          []TransitionDiff `json:"rulesChanged"`
+	RulesByType           bool             `json:"rulesByType,omitempty"`
+}
+
+// Transición en un diff; los campos A/B son los valores de cada lado (nombres de estado)
+type TransitionDiff struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type,omitempty"`
+	FromA   []string `json:"fromA,omitempty"`
+	ToA     string   `json:"toA,omitempty"`
+	FromB   []string `json:"fromB,omitempty"`
+	ToB     string   `json:"toB,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// Comparación de la configuración de dos sitios (o de dos snapshots del historial de un sitio,
+// identificados por SnapshotA y SnapshotB). RulesByType indica que las reglas de los workflows
+// se compararon solo por tipo porque los sitios son distintos.
+type ConfigComparison struct {
+	DomainA           string         `json:"domainA"`
+	DomainB           string         `json:"domainB"`
+	SnapshotA         string         `json:"snapshotA,omitempty"`
+	SnapshotB         string         `json:"snapshotB,omitempty"`
+	Source            string         `json:"source"`
+	RulesByType       bool           `json:"rulesByType,omitempty"`
+	Statuses          *SectionDiff   `json:"statuses,omitempty"`
+	Projects          *SectionDiff   `json:"projects,omitempty"`
+	ProjectCategories *SectionDiff   `json:"projectCategories,omitempty"`
+	Workflows         *SectionDiff   `json:"workflows,omitempty"`
+	WorkflowChanges   []WorkflowDiff `json:"workflowChanges,omitempty"`
+	Skipped           []string       `json:"skipped,omitempty"`
+}
+
+// Diferencias de una sección: objetos que solo están en un lado y objetos modificados
+type SectionDiff struct {
+	OnlyInA []string       `json:"onlyInA"`
+	OnlyInB []string       `json:"onlyInB"`
+	Changed []ObjectChange `json:"changed"`
+}
+
+type ObjectChange struct {
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"`
+}
+
+// Metadatos de cada ejecución guardada en el historial de snapshots
+type SnapshotMetadata struct {
+	ID        string         `json:"id"`
+	FetchedAt time.Time      `json:"fetchedAt"`
+	Domain    string         `json:"domain"`
+	Correo    string         `json:"correo"`
+	Origin    string         `json:"origin,omitempty"`
+	Sections  []string       `json:"sections"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// Fichero de historial: metadatos y los datos descargados en esa ejecución
+type SnapshotFile struct {
+	Metadata SnapshotMetadata       `json:"metadata"`
+	Datos    map[string]interface{} `json:"datos"`
+}
+
+// Informe de agrupación de workflows similares
+type WorkflowClusterReport struct {
+	Threshold         float64           `json:"threshold"`
+	ProjectsAvailable bool              `json:"projectsAvailable"`
+	Clusters          []WorkflowCluster `json:"clusters"`
+}
+
+// Grupo de workflows casi idénticos; Base es el miembro propuesto para consolidar (el más usado)
+type WorkflowCluster struct {
+	Base     string               `json:"base"`
+	MinScore float64              `json:"minScore"`
+	Members  []ClusterMember      `json:"members"`
+	Pairs    []WorkflowSimilarity `json:"pairs"`
+}
+
+type ClusterMember struct {
+	Workflow string   `json:"workflow"`
+	Projects []string `json:"projects"`
+}
+
+// Similitud entre dos workflows (0 a 1)
+type WorkflowSimilarity struct {
+	A     string  `json:"a"`
+	B     string  `json:"b"`
+	Score float64 `json:"score"`
+}
+
+// Fichero YAML de reglas de administración
+type PolicyFile struct {
+	Reglas []PolicyRule `yaml:"reglas" json:"reglas"`
+}
+
+// Regla de administración. Según el tipo se usan Patron, Maximo o Nombres.
+type PolicyRule struct {
+	ID          string   `yaml:"id" json:"id"`
+	Tipo        string   `yaml:"tipo" json:"tipo"`
+	Severidad   string   `yaml:"severidad" json:"severidad"`
+	Descripcion string   `yaml:"descripcion,omitempty" json:"descripcion,omitempty"`
+	Patron      string   `yaml:"patron,omitempty" json:"patron,omitempty"`
+	Maximo      int      `yaml:"maximo,omitempty" json:"maximo,omitempty"`
+	Nombres     []string `yaml:"nombres,omitempty" json:"nombres,omitempty"`
+}
+
+// Incumplimiento de una regla por un objeto del snapshot
+type PolicyResult struct {
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	ObjectType string `json:"objectType"`
+	Object     string `json:"object"`
+	Message    string `json:"message"`
+	Link       string `json:"link"`
 }
 
 // ------------------------------------------------------------
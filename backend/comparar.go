@@ -0,0 +1,634 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Comparación de workflows (en la misma conexión o entre dos conexiones)
+// ----------------------------------------------------------------
+
+// transicionNombrada es una transición con sus estados ya traducidos a nombres.
+type transicionNombrada struct {
+	original Transition
+	from     []string
+	to       string
+}
+
+// firma identifica una transición por nombre, origen y destino.
+func (t transicionNombrada) firma() string {
+	return t.original.Name + "|" + strings.Join(t.from, ",") + "|" + t.to
+}
+
+// nombrarTransiciones traduce origen y destino de cada transición a nombres de estado.
+func nombrarTransiciones(wf JiraWorkflow) []transicionNombrada {
+	nombres := nombresEstadosWorkflow(wf)
+	nombre := func(id string) string {
+		if n, ok := nombres[id]; ok {
+			return n
+		}
+		return id
+	}
+
+	var resultado []transicionNombrada
+	for _, t := range wf.Transitions {
+		tn := transicionNombrada{original: t, to: nombre(t.To), from: []string{}}
+		for _, from := range t.From {
+			tn.from = append(tn.from, nombre(from))
+		}
+		sort.Strings(tn.from)
+		resultado = append(resultado, tn)
+	}
+	return resultado
+}
+
+// cambiosReglas describe qué partes de la configuración de dos transiciones emparejadas difieren.
+// Entre sitios distintos las reglas se comparan solo por tipo: su configuración guarda IDs de
+// campos, grupos o roles propios de cada sitio y casi nunca coincidiría.
+func cambiosReglas(a, b Transition, mismoSitio bool) []string {
+	var reglasA, reglasB TransitionRules
+	if a.Rules != nil {
+		reglasA = *a.Rules
+	}
+	if b.Rules != nil {
+		reglasB = *b.Rules
+	}
+
+	iguales := mismasReglas
+	arbolesIguales := func(x, y *WorkflowCondition) bool { return reflect.DeepEqual(x, y) }
+	if !mismoSitio {
+		iguales = mismosTiposReglas
+		arbolesIguales = func(x, y *WorkflowCondition) bool { return firmaCondicion(x) == firmaCondicion(y) }
+	}
+
+	var cambios []string
+	if !arbolesIguales(reglasA.ConditionsTree, reglasB.ConditionsTree) || !iguales(reglasA.Conditions, reglasB.Conditions) {
+		cambios = append(cambios, "condiciones")
+	}
+	if !iguales(reglasA.Validators, reglasB.Validators) {
+		cambios = append(cambios, "validadores")
+	}
+	if !iguales(reglasA.PostFunctions, reglasB.PostFunctions) {
+		cambios = append(cambios, "post functions")
+	}
+	if nombrePantalla(a.Screen) != nombrePantalla(b.Screen) {
+		cambios = append(cambios, fmt.Sprintf("pantalla: %q → %q", nombrePantalla(a.Screen), nombrePantalla(b.Screen)))
+	}
+	if !reflect.DeepEqual(a.Properties, b.Properties) && (len(a.Properties) > 0 || len(b.Properties) > 0) {
+		cambios = append(cambios, "propiedades")
+	}
+	if a.Description != b.Description {
+		cambios = append(cambios, "descripción")
+	}
+	return cambios
+}
+
+// mismasReglas compara dos listas de reglas por su JSON, tratando nil y vacía como iguales.
+func mismasReglas(a, b []WorkflowRule) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	jsonA, _ := json.Marshal(a)
+	jsonB, _ := json.Marshal(b)
+	return string(jsonA) == string(jsonB)
+}
+
+// mismosTiposReglas compara dos listas de reglas solo por el tipo de cada una, en orden.
+func mismosTiposReglas(a, b []WorkflowRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
+
+// firmaCondicion resume un árbol de condiciones con sus operadores y tipos, sin la configuración.
+func firmaCondicion(c *WorkflowCondition) string {
+	if c == nil {
+		return ""
+	}
+	if c.NodeType != "compound" {
+		return c.Type
+	}
+	partes := make([]string, len(c.Conditions))
+	for i := range c.Conditions {
+		partes[i] = firmaCondicion(&c.Conditions[i])
+	}
+	return c.Operator + "(" + strings.Join(partes, ",") + ")"
+}
+
+func nombrePantalla(s *TransitionScreen) string {
+	if s == nil {
+		return ""
+	}
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.ID
+}
+
+// compararWorkflows calcula las diferencias de b respecto a a. Las transiciones se emparejan
+// primero por nombre, origen y destino; las que quedan, solo por nombre (son las redirigidas).
+// Si los workflows son de sitios distintos las reglas se comparan solo por tipo.
+func compararWorkflows(a, b JiraWorkflow, mismoSitio bool) WorkflowDiff {
+	diff := WorkflowDiff{
+		WorkflowA:             a.ID.Name,
+		WorkflowB:             b.ID.Name,
+		RulesByType:           !mismoSitio,
+		StatusesAdded:         []string{},
+		StatusesRemoved:       []string{},
+		TransitionsAdded:      []TransitionDiff{},
+		TransitionsRemoved:    []TransitionDiff{},
+		TransitionsRetargeted: []TransitionDiff{},
+		RulesChanged:          []TransitionDiff{},
+	}
+
+	estadosA := make(map[string]bool)
+	for _, st := range a.Statuses {
+		estadosA[st.Name] = true
+	}
+	estadosB := make(map[string]bool)
+	for _, st := range b.Statuses {
+		estadosB[st.Name] = true
+	}
+	for nombre := range estadosB {
+		if !estadosA[nombre] {
+			diff.StatusesAdded = append(diff.StatusesAdded, nombre)
+		}
+	}
+	for nombre := range estadosA {
+		if !estadosB[nombre] {
+			diff.StatusesRemoved = append(diff.StatusesRemoved, nombre)
+		}
+	}
+	sort.Strings(diff.StatusesAdded)
+	sort.Strings(diff.StatusesRemoved)
+
+	transA := nombrarTransiciones(a)
+	transB := nombrarTransiciones(b)
+	usadasB := make([]bool, len(transB))
+	var pendientesA []transicionNombrada
+
+	compararPareja := func(ta, tb transicionNombrada) {
+		if cambios := cambiosReglas(ta.original, tb.original, mismoSitio); len(cambios) > 0 {
+			diff.RulesChanged = append(diff.RulesChanged, TransitionDiff{
+				Name:    ta.original.Name,
+				Type:    ta.original.Type,
+				FromA:   ta.from,
+				ToA:     ta.to,
+				Changes: cambios,
+			})
+		}
+	}
+
+	// Emparejado exacto
+	for _, ta := range transA {
+		encontrada := false
+		for j, tb := range transB {
+			if !usadasB[j] && ta.firma() == tb.firma() {
+				usadasB[j] = true
+				encontrada = true
+				compararPareja(ta, tb)
+				break
+			}
+		}
+		if !encontrada {
+			pendientesA = append(pendientesA, ta)
+		}
+	}
+
+	// Emparejado por nombre: misma transición con otro origen o destino
+	for _, ta := range pendientesA {
+		encontrada := false
+		for j, tb := range transB {
+			if !usadasB[j] && ta.original.Name == tb.original.Name {
+				usadasB[j] = true
+				encontrada = true
+				diff.TransitionsRetargeted = append(diff.TransitionsRetargeted, TransitionDiff{
+					Name:  ta.original.Name,
+					Type:  tb.original.Type,
+					FromA: ta.from,
+					ToA:   ta.to,
+					FromB: tb.from,
+					ToB:   tb.to,
+				})
+				compararPareja(ta, tb)
+				break
+			}
+		}
+		if !encontrada {
+			diff.TransitionsRemoved = append(diff.TransitionsRemoved, TransitionDiff{
+				Name:  ta.original.Name,
+				Type:  ta.original.Type,
+				FromA: ta.from,
+				ToA:   ta.to,
+			})
+		}
+	}
+
+	for j, tb := range transB {
+		if !usadasB[j] {
+			diff.TransitionsAdded = append(diff.TransitionsAdded, TransitionDiff{
+				Name:  tb.original.Name,
+				Type:  tb.original.Type,
+				FromB: tb.from,
+				ToB:   tb.to,
+			})
+		}
+	}
+	return diff
+}
+
+// buscarWorkflow devuelve el workflow con el nombre indicado de la lista.
+func buscarWorkflow(workflows []JiraWorkflow, nombre string) (JiraWorkflow, bool) {
+	for _, wf := range workflows {
+		if wf.ID.Name == nombre {
+			return wf, true
+		}
+	}
+	return JiraWorkflow{}, false
+}
+
+// cargarWorkflowsConexion lee la sección de workflows del snapshot de una conexión
+// (índice negativo = conexión activa).
+func cargarWorkflowsConexion(index int) (Credentials, []JiraWorkflow, error) {
+	conn, snapshot, err := cargarSnapshotConexion(index)
+	if err != nil {
+		return conn, nil, err
+	}
+	if _, ok := snapshot["workflows"]; !ok {
+		return conn, nil, fmt.Errorf("el snapshot de %s no contiene workflows", conn.Domain)
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		return conn, nil, err
+	}
+	return conn, workflows, nil
+}
+
+// handleWorkflowDiff compara el workflow "a" con el workflow "b". Con conexionA/conexionB se puede
+// tomar cada uno del snapshot de otra conexión guardada (por defecto, la activa).
+func handleWorkflowDiff(w http.ResponseWriter, r *http.Request) {
+	nombreA := r.URL.Query().Get("a")
+	nombreB := r.URL.Query().Get("b")
+	if nombreA == "" || nombreB == "" {
+		http.Error(w, "Faltan los parámetros 'a' y 'b'", http.StatusBadRequest)
+		return
+	}
+	indiceA, err := indiceConexionParam(r, "conexionA")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	indiceB, err := indiceConexionParam(r, "conexionB")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	connA, workflowsA, err := cargarWorkflowsConexion(indiceA)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	connB, workflowsB, err := cargarWorkflowsConexion(indiceB)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	wfA, ok := buscarWorkflow(workflowsA, nombreA)
+	if !ok {
+		http.Error(w, fmt.Sprintf("No se encontró el workflow %q en %s", nombreA, connA.Domain), http.StatusNotFound)
+		return
+	}
+	wfB, ok := buscarWorkflow(workflowsB, nombreB)
+	if !ok {
+		http.Error(w, fmt.Sprintf("No se encontró el workflow %q en %s", nombreB, connB.Domain), http.StatusNotFound)
+		return
+	}
+
+	diff := compararWorkflows(wfA, wfB, connA.Domain == connB.Domain)
+	diff.DomainA = connA.Domain
+	diff.DomainB = connB.Domain
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// handleWorkflowNames devuelve los nombres de los workflows del snapshot de una conexión
+// (?conexion=índice, por defecto la activa).
+func handleWorkflowNames(w http.ResponseWriter, r *http.Request) {
+	indice, err := indiceConexionParam(r, "conexion")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, workflows, err := cargarWorkflowsConexion(indice)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	nombres := []string{}
+	for _, wf := range workflows {
+		nombres = append(nombres, wf.ID.Name)
+	}
+	sort.Strings(nombres)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nombres)
+}
+
+// ----------------------------------------------------------------
+// Comparación de configuración completa entre dos conexiones
+// ----------------------------------------------------------------
+
+// nuevaSectionDiff crea un diff vacío con las listas inicializadas para que el JSON no lleve null.
+func nuevaSectionDiff() *SectionDiff {
+	return &SectionDiff{OnlyInA: []string{}, OnlyInB: []string{}, Changed: []ObjectChange{}}
+}
+
+// compararClaves rellena OnlyInA/OnlyInB a partir de dos conjuntos de claves y devuelve las comunes.
+func compararClaves[T any](diff *SectionDiff, a, b map[string]T) []string {
+	var comunes []string
+	for clave := range a {
+		if _, ok := b[clave]; ok {
+			comunes = append(comunes, clave)
+		} else {
+			diff.OnlyInA = append(diff.OnlyInA, clave)
+		}
+	}
+	for clave := range b {
+		if _, ok := a[clave]; !ok {
+			diff.OnlyInB = append(diff.OnlyInB, clave)
+		}
+	}
+	sort.Strings(diff.OnlyInA)
+	sort.Strings(diff.OnlyInB)
+	sort.Strings(comunes)
+	return comunes
+}
+
+// cambio formatea un cambio de valor "campo: a → b".
+func cambio(campo, a, b string) string {
+	return fmt.Sprintf("%s: %q → %q", campo, a, b)
+}
+
+// compararEstados compara estados por nombre (los IDs difieren entre sitios).
+func compararEstados(a, b []JiraStatus) *SectionDiff {
+	diff := nuevaSectionDiff()
+	porNombre := func(estados []JiraStatus) map[string]JiraStatus {
+		m := make(map[string]JiraStatus, len(estados))
+		for _, st := range estados {
+			m[st.Name] = st
+		}
+		return m
+	}
+	mapaA, mapaB := porNombre(a), porNombre(b)
+	for _, nombre := range compararClaves(diff, mapaA, mapaB) {
+		stA, stB := mapaA[nombre], mapaB[nombre]
+		var cambios []string
+		if stA.StatusCategory != stB.StatusCategory {
+			cambios = append(cambios, cambio("categoría", stA.StatusCategory, stB.StatusCategory))
+		}
+		if stA.Description != stB.Description {
+			cambios = append(cambios, cambio("descripción", stA.Description, stB.Description))
+		}
+		if len(cambios) > 0 {
+			diff.Changed = append(diff.Changed, ObjectChange{Name: nombre, Changes: cambios})
+		}
+	}
+	return diff
+}
+
+func nombreCategoria(p JiraProject) string {
+	if p.ProjectCategory == nil {
+		return ""
+	}
+	return p.ProjectCategory.Name
+}
+
+// compararProyectos compara proyectos por clave.
+func compararProyectos(a, b []JiraProject) *SectionDiff {
+	diff := nuevaSectionDiff()
+	porClave := func(proyectos []JiraProject) map[string]JiraProject {
+		m := make(map[string]JiraProject, len(proyectos))
+		for _, p := range proyectos {
+			m[p.Key] = p
+		}
+		return m
+	}
+	mapaA, mapaB := porClave(a), porClave(b)
+	for _, clave := range compararClaves(diff, mapaA, mapaB) {
+		pA, pB := mapaA[clave], mapaB[clave]
+		var cambios []string
+		if pA.Name != pB.Name {
+			cambios = append(cambios, cambio("nombre", pA.Name, pB.Name))
+		}
+		if nombreCategoria(pA) != nombreCategoria(pB) {
+			cambios = append(cambios, cambio("categoría", nombreCategoria(pA), nombreCategoria(pB)))
+		}
+		if len(cambios) > 0 {
+			diff.Changed = append(diff.Changed, ObjectChange{Name: clave, Changes: cambios})
+		}
+	}
+	return diff
+}
+
+// compararCategoriasProyecto compara el catálogo de categorías de proyecto por nombre, incluidas
+// las que ningún proyecto usa.
+func compararCategoriasProyecto(a, b []ProjectCategory) *SectionDiff {
+	diff := nuevaSectionDiff()
+	porNombre := func(categorias []ProjectCategory) map[string]ProjectCategory {
+		m := make(map[string]ProjectCategory, len(categorias))
+		for _, c := range categorias {
+			m[c.Name] = c
+		}
+		return m
+	}
+	mapaA, mapaB := porNombre(a), porNombre(b)
+	for _, nombre := range compararClaves(diff, mapaA, mapaB) {
+		if cA, cB := mapaA[nombre], mapaB[nombre]; cA.Description != cB.Description {
+			diff.Changed = append(diff.Changed, ObjectChange{Name: nombre, Changes: []string{cambio("descripción", cA.Description, cB.Description)}})
+		}
+	}
+	return diff
+}
+
+// compararListasWorkflows compara workflows por nombre y, para los comunes, calcula su diff.
+func compararListasWorkflows(a, b []JiraWorkflow, mismoSitio bool) (*SectionDiff, []WorkflowDiff) {
+	diff := nuevaSectionDiff()
+	porNombre := func(workflows []JiraWorkflow) map[string]JiraWorkflow {
+		m := make(map[string]JiraWorkflow, len(workflows))
+		for _, wf := range workflows {
+			m[wf.ID.Name] = wf
+		}
+		return m
+	}
+	mapaA, mapaB := porNombre(a), porNombre(b)
+	detalles := []WorkflowDiff{}
+	for _, nombre := range compararClaves(diff, mapaA, mapaB) {
+		wfDiff := compararWorkflows(mapaA[nombre], mapaB[nombre], mismoSitio)
+		if resumen := resumenWorkflowDiff(wfDiff); len(resumen) > 0 {
+			diff.Changed = append(diff.Changed, ObjectChange{Name: nombre, Changes: resumen})
+			detalles = append(detalles, wfDiff)
+		}
+	}
+	return diff, detalles
+}
+
+// resumenWorkflowDiff describe en pocas líneas un diff de workflows; vacío si no hay diferencias.
+func resumenWorkflowDiff(d WorkflowDiff) []string {
+	var resumen []string
+	contar := func(n int, texto string) {
+		if n > 0 {
+			resumen = append(resumen, fmt.Sprintf("%d %s", n, texto))
+		}
+	}
+	contar(len(d.StatusesAdded), "estados añadidos")
+	contar(len(d.StatusesRemoved), "estados eliminados")
+	contar(len(d.TransitionsAdded), "transiciones añadidas")
+	contar(len(d.TransitionsRemoved), "transiciones eliminadas")
+	contar(len(d.TransitionsRetargeted), "transiciones redirigidas")
+	if d.RulesByType {
+		contar(len(d.RulesChanged), "transiciones con tipos de reglas distintos")
+	} else {
+		contar(len(d.RulesChanged), "transiciones con reglas modificadas")
+	}
+	return resumen
+}
+
+// compararSnapshots compara las secciones de estados, proyectos, categorías de proyecto y
+// workflows de dos snapshots.
+// Las secciones que falten en alguno de los dos se anotan en Skipped. mismoSitio indica si ambos
+// son del mismo dominio, en cuyo caso se compara también la configuración de las reglas.
+func compararSnapshots(a, b map[string]interface{}, mismoSitio bool) (ConfigComparison, error) {
+	comparacion := ConfigComparison{RulesByType: !mismoSitio}
+	presente := func(key string) bool {
+		_, okA := a[key]
+		_, okB := b[key]
+		if !okA || !okB {
+			comparacion.Skipped = append(comparacion.Skipped, key)
+		}
+		return okA && okB
+	}
+
+	if presente("estados") {
+		var estadosA, estadosB []JiraStatus
+		if err := leerSeccion(a, "estados", &estadosA); err != nil {
+			return comparacion, err
+		}
+		if err := leerSeccion(b, "estados", &estadosB); err != nil {
+			return comparacion, err
+		}
+		comparacion.Statuses = compararEstados(estadosA, estadosB)
+	}
+
+	if presente("proyectos") {
+		var proyectosA, proyectosB []JiraProject
+		if err := leerSeccion(a, "proyectos", &proyectosA); err != nil {
+			return comparacion, err
+		}
+		if err := leerSeccion(b, "proyectos", &proyectosB); err != nil {
+			return comparacion, err
+		}
+		comparacion.Projects = compararProyectos(proyectosA, proyectosB)
+	}
+
+	if presente("categoriasProyecto") {
+		var categoriasA, categoriasB []ProjectCategory
+		if err := leerSeccion(a, "categoriasProyecto", &categoriasA); err != nil {
+			return comparacion, err
+		}
+		if err := leerSeccion(b, "categoriasProyecto", &categoriasB); err != nil {
+			return comparacion, err
+		}
+		comparacion.ProjectCategories = compararCategoriasProyecto(categoriasA, categoriasB)
+	}
+
+	if presente("workflows") {
+		var workflowsA, workflowsB []JiraWorkflow
+		if err := leerSeccion(a, "workflows", &workflowsA); err != nil {
+			return comparacion, err
+		}
+		if err := leerSeccion(b, "workflows", &workflowsB); err != nil {
+			return comparacion, err
+		}
+		comparacion.Workflows, comparacion.WorkflowChanges = compararListasWorkflows(workflowsA, workflowsB, mismoSitio)
+	}
+	return comparacion, nil
+}
+
+// obtenerConfiguracionConexion devuelve el snapshot guardado de una conexión o, con enVivo,
+// descarga de nuevo sus estados, proyectos, categorías de proyecto y workflows sin tocar el snapshot.
+func obtenerConfiguracionConexion(index int, enVivo bool) (Credentials, map[string]interface{}, error) {
+	if !enVivo {
+		return cargarSnapshotConexion(index)
+	}
+	conn, err := getCredentialsIndex(index)
+	if err != nil {
+		return conn, nil, err
+	}
+	opciones := RequestData{Estados: true, Proyectos: true, CategoriasProyecto: true, Workflows: true}
+	datos, err := ejecutarConsultaJira(conn.Domain, conn.Correo, conn.Token, opciones)
+	if err != nil {
+		return conn, nil, err
+	}
+	return conn, datos, nil
+}
+
+// handleCompareConnections compara la configuración de las conexiones "a" y "b" (índices).
+// Con fuente=live se descargan ambas en el momento; por defecto se usan los snapshots guardados.
+func handleCompareConnections(w http.ResponseWriter, r *http.Request) {
+	indiceA, err := indiceConexionParam(r, "a")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	indiceB, err := indiceConexionParam(r, "b")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if indiceA < 0 || indiceB < 0 {
+		http.Error(w, "Faltan los parámetros 'a' y 'b'", http.StatusBadRequest)
+		return
+	}
+	enVivo := r.URL.Query().Get("fuente") == "live"
+
+	connA, datosA, err := obtenerConfiguracionConexion(indiceA, enVivo)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error obteniendo la configuración de la conexión %d: %v", indiceA, err), http.StatusInternalServerError)
+		return
+	}
+	connB, datosB, err := obtenerConfiguracionConexion(indiceB, enVivo)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error obteniendo la configuración de la conexión %d: %v", indiceB, err), http.StatusInternalServerError)
+		return
+	}
+
+	comparacion, err := compararSnapshots(datosA, datosB, connA.Domain == connB.Domain)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	comparacion.DomainA = connA.Domain
+	comparacion.DomainB = connB.Domain
+	comparacion.Source = "snapshot"
+	if enVivo {
+		comparacion.Source = "live"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparacion)
+}
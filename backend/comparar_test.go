@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompararSnapshotsCategoriasProyecto(t *testing.T) {
+	// La categoría "Sin usar" no la tiene ningún proyecto, pero es parte del catálogo
+	a := map[string]interface{}{
+		"proyectos":          []JiraProject{{Key: "APP", Name: "App"}},
+		"categoriasProyecto": []ProjectCategory{{ID: "1", Name: "Interno"}, {ID: "2", Name: "Sin usar"}},
+	}
+	b := map[string]interface{}{
+		"proyectos":          []JiraProject{{Key: "APP", Name: "App"}},
+		"categoriasProyecto": []ProjectCategory{{ID: "7", Name: "Interno", Description: "Equipos"}, {ID: "8", Name: "Clientes"}},
+	}
+	comparacion, err := compararSnapshots(a, b, false)
+	if err != nil {
+		t.Fatalf("error comparando: %v", err)
+	}
+	diff := comparacion.ProjectCategories
+	if diff == nil {
+		t.Fatal("no se han comparado las categorías de proyecto")
+	}
+	if !reflect.DeepEqual(diff.OnlyInA, []string{"Sin usar"}) || !reflect.DeepEqual(diff.OnlyInB, []string{"Clientes"}) {
+		t.Errorf("categorías solo en A %v y solo en B %v", diff.OnlyInA, diff.OnlyInB)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Name != "Interno" {
+		t.Errorf("cambios = %+v, se esperaba la descripción de Interno", diff.Changed)
+	}
+
+	// Sin la sección en uno de los dos se anota como no comparada
+	delete(b, "categoriasProyecto")
+	comparacion, err = compararSnapshots(a, b, false)
+	if err != nil {
+		t.Fatalf("error comparando: %v", err)
+	}
+	if comparacion.ProjectCategories != nil || !contieneTexto(comparacion.Skipped, "categoriasProyecto") {
+		t.Errorf("categorías %+v, secciones sin comparar %v", comparacion.ProjectCategories, comparacion.Skipped)
+	}
+}
@@ -209,30 +209,44 @@ func handleConnectionStatus(w http.ResponseWriter, r *http.Request) {
 
 // getCredentials lee el fichero JSON y devuelve la conexión actual (según el índice "current").
 func getCredentials() (Credentials, error) {
-	var creds Credentials
 	jsonData, err := readJSONFile(jsonFilePath)
 	if err != nil {
-		return creds, fmt.Errorf("error leyendo fichero JSON: %w", err)
+		return Credentials{}, fmt.Errorf("error leyendo fichero JSON: %w", err)
+	}
+
+	// Leer el índice de la conexión actual
+	currentIndexFloat, ok := jsonData["current"].(float64)
+	if !ok {
+		return Credentials{}, fmt.Errorf("no se encontró el índice de la conexión actual")
 	}
+	return credencialesDeIndice(jsonData, int(currentIndexFloat))
+}
+
+// getCredentialsIndex devuelve la conexión almacenada en la posición indicada,
+// sea o no la conexión actual.
+func getCredentialsIndex(index int) (Credentials, error) {
+	jsonData, err := readJSONFile(jsonFilePath)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error leyendo fichero JSON: %w", err)
+	}
+	return credencialesDeIndice(jsonData, index)
+}
+
+// credencialesDeIndice extrae del JSON de conexiones la conexión de la posición indicada.
+func credencialesDeIndice(jsonData map[string]interface{}, index int) (Credentials, error) {
+	var creds Credentials
 
 	// Obtener el array de conexiones
 	conns, ok := jsonData["connections"].([]interface{})
 	if !ok || len(conns) == 0 {
 		return creds, fmt.Errorf("no hay conexiones almacenadas")
 	}
-
-	// Leer el índice de la conexión actual
-	currentIndexFloat, ok := jsonData["current"].(float64)
-	if !ok {
-		return creds, fmt.Errorf("no se encontró el índice de la conexión actual")
-	}
-	currentIndex := int(currentIndexFloat)
-	if currentIndex < 0 || currentIndex >= len(conns) {
-		return creds, fmt.Errorf("índice de conexión actual fuera de rango")
+	if index < 0 || index >= len(conns) {
+		return creds, fmt.Errorf("índice de conexión fuera de rango")
 	}
 
-	// Convertir la conexión actual a JSON y deserializarla en el struct Credentials
-	credBytes, err := json.Marshal(conns[currentIndex])
+	// Convertir la conexión a JSON y deserializarla en el struct Credentials
+	credBytes, err := json.Marshal(conns[index])
 	if err != nil {
 		return creds, fmt.Errorf("error convirtiendo credenciales: %w", err)
 	}
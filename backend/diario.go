@@ -0,0 +1,478 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ----------------------------------------------------------------
+// Diario de escrituras: cada cambio que la aplicación hace en Jira se registra junto con el
+// objeto tal y como estaba justo antes, para poder deshacerlo desde la página del diario
+// ----------------------------------------------------------------
+
+// Operaciones que se registran en el diario
+const (
+	opCrearEstados                = "crearEstados"
+	opActualizarEstados           = "actualizarEstados"
+	opBorrarEstados               = "borrarEstados"
+	opCrearWorkflow               = "crearWorkflow"
+	opActualizarWorkflow          = "actualizarWorkflow"
+	opBorrarWorkflow              = "borrarWorkflow"
+	opCrearCategoriaProyecto      = "crearCategoriaProyecto"
+	opActualizarCategoriaProyecto = "actualizarCategoriaProyecto"
+	opBorrarCategoriaProyecto     = "borrarCategoriaProyecto"
+)
+
+// contextoDiario agrupa las escrituras hechas con un mismo cliente de Jira. Cada operación del
+// usuario crea su propio cliente con conectarAJira y abre un lote con abrirLoteDiario mientras
+// dura, así que un cliente equivale a un lote.
+type contextoDiario struct {
+	lote    string
+	deshace string // entrada que se está deshaciendo cuando la escritura es una reversión
+	ultima  string // última entrada registrada con este cliente
+}
+
+var (
+	lotesDiario     sync.Map // *resty.Client -> *contextoDiario, solo mientras el lote está abierto
+	diarioMutex     sync.Mutex
+	deshacerMutex   sync.Mutex
+	secuenciaDiario atomic.Uint32
+)
+
+// diarioDirPath devuelve la carpeta del diario de un dominio.
+func diarioDirPath(domain string) string {
+	domain = strings.TrimRight(domain, "/")
+	return filepath.Join(jsonDirPath, "diario", strings.TrimSuffix(generateFileName(domain), ".json"))
+}
+
+// nuevoIDDiario genera un ID ordenable por fecha. La secuencia evita colisiones entre
+// escrituras hechas en el mismo milisegundo.
+func nuevoIDDiario() string {
+	return fmt.Sprintf("%s-%04d", time.Now().UTC().Format(snapshotIDFormat), secuenciaDiario.Add(1)%10000)
+}
+
+// abrirLoteDiario asocia un lote nuevo al cliente: todas las escrituras hechas con él hasta
+// cerrarLoteDiario se agrupan en ese lote.
+func abrirLoteDiario(client *resty.Client) *contextoDiario {
+	ctx := &contextoDiario{lote: nuevoIDDiario()}
+	lotesDiario.Store(client, ctx)
+	return ctx
+}
+
+// cerrarLoteDiario suelta el lote del cliente al terminar la operación.
+func cerrarLoteDiario(client *resty.Client) {
+	lotesDiario.Delete(client)
+}
+
+// contextoDeCliente devuelve el lote abierto para un cliente. Una escritura hecha sin lote abierto
+// forma un lote propio que no se guarda.
+func contextoDeCliente(client *resty.Client) *contextoDiario {
+	if ctx, ok := lotesDiario.Load(client); ok {
+		return ctx.(*contextoDiario)
+	}
+	return &contextoDiario{lote: nuevoIDDiario()}
+}
+
+// nuevaEntradaDiario prepara la entrada de una escritura hecha con el cliente indicado.
+func nuevaEntradaDiario(client *resty.Client, operacion, tipo string) JournalEntry {
+	ctx := contextoDeCliente(client)
+	return JournalEntry{
+		ID:         nuevoIDDiario(),
+		Batch:      ctx.lote,
+		Domain:     strings.TrimRight(client.BaseURL, "/"),
+		ExecutedAt: time.Now().UTC(),
+		Operation:  operacion,
+		ObjectType: tipo,
+		Items:      []JournalItem{},
+		RevertOf:   ctx.deshace,
+	}
+}
+
+// crudo serializa un objeto para guardarlo como Before/After de un elemento del diario.
+func crudo(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Println("Error serializando el objeto para el diario:", err)
+		return nil
+	}
+	return data
+}
+
+// registrarDiario guarda la entrada con el error de la escritura, si lo hubo. Las entradas sin
+// objetos ni error (escrituras vacías) no se guardan. Un fallo al guardar solo se registra en el
+// log: la escritura en Jira ya se ha hecho y no se puede deshacer aquí.
+func registrarDiario(client *resty.Client, entrada *JournalEntry, err error) {
+	if err != nil {
+		entrada.Error = err.Error()
+	}
+	if len(entrada.Items) == 0 && entrada.Error == "" {
+		return
+	}
+	if errGuardar := guardarEntradaDiario(*entrada); errGuardar != nil {
+		log.Println("Error al registrar la escritura en el diario:", errGuardar)
+		return
+	}
+	contextoDeCliente(client).ultima = entrada.ID
+}
+
+// guardarEntradaDiario escribe una entrada en diario/<dominio>/<id>.json.
+func guardarEntradaDiario(entrada JournalEntry) error {
+	diarioMutex.Lock()
+	defer diarioMutex.Unlock()
+
+	dir := diarioDirPath(entrada.Domain)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creando la carpeta del diario: %w", err)
+	}
+	jsonBytes, err := json.MarshalIndent(entrada, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formateando JSON: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, entrada.ID+".json"), jsonBytes, 0644)
+}
+
+// cargarEntradaDiario lee una entrada del diario de un dominio por su ID.
+func cargarEntradaDiario(domain, id string) (JournalEntry, error) {
+	var entrada JournalEntry
+	// El ID llega desde la petición: no se permite salir de la carpeta del diario
+	if id == "" || filepath.Base(id) != id {
+		return entrada, fmt.Errorf("ID de entrada inválido: %q", id)
+	}
+	diarioMutex.Lock()
+	data, err := os.ReadFile(filepath.Join(diarioDirPath(domain), id+".json"))
+	diarioMutex.Unlock()
+	if err != nil {
+		return entrada, fmt.Errorf("error leyendo la entrada %s del diario: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &entrada); err != nil {
+		return entrada, fmt.Errorf("error parseando la entrada %s del diario: %w", id, err)
+	}
+	return entrada, nil
+}
+
+// listarDiario devuelve las entradas del diario de un dominio, de la más reciente a la más antigua.
+func listarDiario(domain string) ([]JournalEntry, error) {
+	ficheros, err := os.ReadDir(diarioDirPath(domain))
+	if os.IsNotExist(err) {
+		return []JournalEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la carpeta del diario: %w", err)
+	}
+	entradas := []JournalEntry{}
+	for _, fichero := range ficheros {
+		if fichero.IsDir() || !strings.HasSuffix(fichero.Name(), ".json") {
+			continue
+		}
+		entrada, err := cargarEntradaDiario(domain, strings.TrimSuffix(fichero.Name(), ".json"))
+		if err != nil {
+			log.Println("Entrada del diario ignorada:", err)
+			continue
+		}
+		entradas = append(entradas, entrada)
+	}
+	sort.Slice(entradas, func(i, j int) bool { return entradas[i].ID > entradas[j].ID })
+	return entradas, nil
+}
+
+// deshacerElemento revierte en Jira un objeto de una entrada del diario y devuelve una
+// descripción de lo hecho. Las escrituras de la reversión pasan por escritura.go y quedan a su
+// vez en el diario.
+func deshacerElemento(client *resty.Client, entrada JournalEntry, item JournalItem) (string, error) {
+	switch entrada.Operation {
+	case opCrearEstados:
+		comprobaciones, _, err := verificarBorradoEstados(client, []string{item.ID})
+		if err != nil {
+			return "", err
+		}
+		if len(comprobaciones) == 0 || !comprobaciones[0].Deletable {
+			motivo := "no se pudo comprobar"
+			if len(comprobaciones) > 0 {
+				motivo = comprobaciones[0].Reason
+			}
+			return "", fmt.Errorf("no se puede borrar el estado %s: %s", item.Name, motivo)
+		}
+		if _, err := borrarEstadosJira(client, []string{item.ID}); err != nil {
+			return "", err
+		}
+		return "Estado borrado", nil
+
+	case opActualizarEstados:
+		var previo JiraStatus
+		if err := json.Unmarshal(item.Before, &previo); err != nil {
+			return "", fmt.Errorf("estado previo ilegible: %w", err)
+		}
+		cambio := JiraStatusUpdate{ID: previo.ID, Name: previo.Name, Description: previo.Description, StatusCategory: previo.StatusCategory}
+		if _, err := actualizarEstadosJira(client, []JiraStatusUpdate{cambio}); err != nil {
+			return "", err
+		}
+		return "Restaurados nombre, descripción y categoría", nil
+
+	case opBorrarEstados:
+		var previo JiraStatus
+		if err := json.Unmarshal(item.Before, &previo); err != nil {
+			return "", fmt.Errorf("estado previo ilegible: %w", err)
+		}
+		// Se recrea en el mismo ámbito que tenía: un estado de proyecto no debe volver como global
+		ambito := JiraScope{Type: "GLOBAL"}
+		if ambitoEstado(previo) != "GLOBAL" {
+			if previo.Scope.Project == nil || previo.Scope.Project.ID == "" {
+				return "", fmt.Errorf("el estado tenía ámbito %s sin proyecto; no se puede recrear en el mismo ámbito", previo.Scope.Type)
+			}
+			ambito = *previo.Scope
+		}
+		creados, err := crearEstadosEnAmbitoJira(client, []JiraStatus{previo}, ambito)
+		if err != nil {
+			return "", err
+		}
+		if len(creados) == 0 {
+			return "", fmt.Errorf("Jira no devolvió el estado recreado")
+		}
+		return fmt.Sprintf("Estado recreado con el ID %s; Jira asigna un ID nuevo", creados[0].ID), nil
+
+	case opCrearWorkflow:
+		ref, err := obtenerReferenciaWorkflowJira(client, item.Name)
+		if err != nil {
+			return "", err
+		}
+		if ref == nil {
+			return "", fmt.Errorf("el workflow %s ya no existe", item.Name)
+		}
+		if err := borrarWorkflowJira(client, ref.ID); err != nil {
+			return "", err
+		}
+		return "Workflow borrado", nil
+
+	case opActualizarWorkflow:
+		var previo JiraWorkflowWriteRequest
+		if err := json.Unmarshal(item.Before, &previo); err != nil || len(previo.Workflows) != 1 {
+			return "", fmt.Errorf("definición previa del workflow ilegible")
+		}
+		// La actualización exige la versión actual, que ha cambiado desde que se leyó la previa
+		vivos, err := leerWorkflowsJira(client, []string{item.ID}, nil)
+		if err != nil {
+			return "", err
+		}
+		if len(vivos.Workflows) == 0 || vivos.Workflows[0].Version == nil {
+			return "", fmt.Errorf("el workflow %s ya no existe", item.Name)
+		}
+		previo.Workflows[0].ID = vivos.Workflows[0].ID
+		previo.Workflows[0].Name = ""
+		previo.Workflows[0].Version = vivos.Workflows[0].Version
+		if err := actualizarWorkflowJira(client, previo); err != nil {
+			return "", err
+		}
+		return "Restaurada la definición previa del workflow", nil
+
+	case opBorrarWorkflow:
+		var previo JiraWorkflowWriteRequest
+		if err := json.Unmarshal(item.Before, &previo); err != nil || len(previo.Workflows) != 1 {
+			return "", fmt.Errorf("definición previa del workflow ilegible")
+		}
+		previo.Workflows[0].ID = ""
+		previo.Workflows[0].Version = nil
+		if err := crearWorkflowJira(client, previo); err != nil {
+			return "", err
+		}
+		return "Workflow recreado; hay que volver a asignarlo a sus esquemas", nil
+
+	case opCrearCategoriaProyecto:
+		if err := borrarCategoriaProyectoJira(client, item.ID); err != nil {
+			return "", err
+		}
+		return "Categoría borrada", nil
+
+	case opActualizarCategoriaProyecto:
+		var previa ProjectCategory
+		if err := json.Unmarshal(item.Before, &previa); err != nil {
+			return "", fmt.Errorf("categoría previa ilegible: %w", err)
+		}
+		if err := actualizarCategoriaProyectoJira(client, previa); err != nil {
+			return "", err
+		}
+		return "Restaurados nombre y descripción", nil
+
+	case opBorrarCategoriaProyecto:
+		var previa ProjectCategory
+		if err := json.Unmarshal(item.Before, &previa); err != nil {
+			return "", fmt.Errorf("categoría previa ilegible: %w", err)
+		}
+		creada, err := crearCategoriaProyectoJira(client, previa)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Categoría recreada con el ID %s; hay que volver a asignarla a sus proyectos", creada.ID), nil
+	}
+	return "", fmt.Errorf("operación desconocida: %s", entrada.Operation)
+}
+
+// entradasADeshacer selecciona las entradas de la petición: todas las de un lote (de la más
+// reciente a la más antigua) o una sola.
+func entradasADeshacer(domain string, peticion JournalRevertRequest) ([]JournalEntry, error) {
+	if peticion.Batch != "" {
+		todas, err := listarDiario(domain)
+		if err != nil {
+			return nil, err
+		}
+		var entradas []JournalEntry
+		for _, entrada := range todas {
+			if entrada.Batch == peticion.Batch {
+				entradas = append(entradas, entrada)
+			}
+		}
+		if len(entradas) == 0 {
+			return nil, fmt.Errorf("no hay entradas del lote %s", peticion.Batch)
+		}
+		return entradas, nil
+	}
+	entrada, err := cargarEntradaDiario(domain, peticion.Entry)
+	if err != nil {
+		return nil, err
+	}
+	if peticion.Item != nil && (*peticion.Item < 0 || *peticion.Item >= len(entrada.Items)) {
+		return nil, fmt.Errorf("la entrada %s no tiene el objeto %d", entrada.ID, *peticion.Item)
+	}
+	return []JournalEntry{entrada}, nil
+}
+
+// deshacerDiario revierte un lote, una entrada o un objeto del diario de la conexión activa (ver
+// deshacerEntradas). Al terminar se refresca el snapshot.
+func deshacerDiario(peticion JournalRevertRequest) (JournalRevertResult, error) {
+	deshacerMutex.Lock()
+	defer deshacerMutex.Unlock()
+
+	conn, err := getCredentials()
+	if err != nil {
+		return JournalRevertResult{}, fmt.Errorf("no hay conexión activa: %w", err)
+	}
+	entradas, err := entradasADeshacer(conn.Domain, peticion)
+	if err != nil {
+		return JournalRevertResult{}, err
+	}
+
+	client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+	resultado, cambiosEnJira := deshacerEntradas(client, entradas, peticion.Item)
+	if cambiosEnJira {
+		if datos, err := ejecutarConsultaJira(conn.Domain, conn.Correo, conn.Token, opcionesPlan); err != nil {
+			log.Println("Error al refrescar el snapshot tras deshacer:", err)
+		} else {
+			guardarSnapshot(conn, datos)
+		}
+	}
+	return resultado, nil
+}
+
+// deshacerEntradas revierte los objetos de las entradas en un lote nuevo, en orden inverso al que
+// se hicieron los cambios; con item solo ese objeto de la entrada. Los objetos que fallaron o ya
+// se deshicieron se omiten y los deshechos se marcan en su entrada. Indica además si se ha
+// cambiado algo en Jira.
+func deshacerEntradas(client *resty.Client, entradas []JournalEntry, item *int) (JournalRevertResult, bool) {
+	ctx := abrirLoteDiario(client)
+	defer cerrarLoteDiario(client)
+	resultado := JournalRevertResult{Batch: ctx.lote}
+
+	cambiosEnJira := false
+	for _, entrada := range entradas {
+		modificada := false
+		for i := len(entrada.Items) - 1; i >= 0; i-- {
+			if item != nil && i != *item {
+				continue
+			}
+			elemento := &entrada.Items[i]
+			linea := JournalRevertItem{Entry: entrada.ID, Operation: entrada.Operation, Name: elemento.Name}
+			switch {
+			case !elemento.Applied:
+				linea.Result = "skipped"
+				linea.Message = "El cambio no llegó a aplicarse"
+			case elemento.RevertedBy != "":
+				linea.Result = "skipped"
+				linea.Message = "Ya se deshizo en la entrada " + elemento.RevertedBy
+			default:
+				ctx.deshace = entrada.ID
+				ctx.ultima = ""
+				mensaje, err := deshacerElemento(client, entrada, *elemento)
+				switch {
+				case err != nil:
+					linea.Result = "error"
+					linea.Message = err.Error()
+				case ctx.ultima == "":
+					// Sin la entrada de la reversión no hay con qué marcar el objeto como deshecho
+					cambiosEnJira = true
+					linea.Result = "error"
+					linea.Message = mensaje + ". No se pudo registrar la reversión en el diario: revisa el objeto en Jira antes de volver a deshacerlo"
+				default:
+					cambiosEnJira = true
+					linea.Result = "reverted"
+					linea.Message = mensaje
+					elemento.RevertedBy = ctx.ultima
+					modificada = true
+				}
+			}
+			switch linea.Result {
+			case "reverted":
+				resultado.Reverted++
+			case "skipped":
+				resultado.Skipped++
+			default:
+				resultado.Failed++
+			}
+			resultado.Items = append(resultado.Items, linea)
+		}
+		if modificada {
+			if err := guardarEntradaDiario(entrada); err != nil {
+				log.Println("Error al marcar la entrada del diario como deshecha:", err)
+			}
+		}
+	}
+	return resultado, cambiosEnJira
+}
+
+// handleJournal devuelve el diario de escrituras de la conexión activa.
+func handleJournal(w http.ResponseWriter, r *http.Request) {
+	conn, err := getCredentials()
+	if err != nil {
+		http.Error(w, "No hay conexión activa: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	entradas, err := listarDiario(conn.Domain)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entradas)
+}
+
+// handleRevertJournal deshace un lote, una entrada o un objeto del diario.
+func handleRevertJournal(w http.ResponseWriter, r *http.Request) {
+	var peticion JournalRevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if peticion.Batch == "" && peticion.Entry == "" {
+		http.Error(w, "Indica el lote o la entrada a deshacer", http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := deshacerDiario(peticion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// servidorCategorias simula Jira para borrar categorías de proyecto y anota los borrados en orden.
+func servidorCategorias(t *testing.T) (*httptest.Server, func() []string) {
+	var mutex sync.Mutex
+	var borrados []string
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			mutex.Lock()
+			borrados = append(borrados, filepath.Base(r.URL.Path))
+			mutex.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"id":"` + filepath.Base(r.URL.Path) + `","name":"Categoría"}`))
+	}))
+	t.Cleanup(func() {
+		servidor.Close()
+		os.RemoveAll(diarioDirPath(servidor.URL))
+		os.Remove(filepath.Dir(diarioDirPath(servidor.URL)))
+	})
+	return servidor, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string{}, borrados...)
+	}
+}
+
+func TestDeshacerLoteDiario(t *testing.T) {
+	servidor, borrados := servidorCategorias(t)
+	dominio := servidor.URL
+	entradas := []JournalEntry{
+		{ID: "20260101-100000.000-0001", Batch: "L1", Domain: dominio, Operation: opCrearCategoriaProyecto, Items: []JournalItem{
+			{ID: "10", Name: "Primera", Applied: true},
+			{ID: "11", Name: "Segunda", Applied: true},
+		}},
+		{ID: "20260101-100001.000-0002", Batch: "L1", Domain: dominio, Operation: opCrearCategoriaProyecto, Items: []JournalItem{
+			{ID: "12", Name: "Tercera", Applied: true},
+			{ID: "13", Name: "Fallida", Applied: false},
+			{ID: "14", Name: "Deshecha", Applied: true, RevertedBy: "20260101-110000.000-0009"},
+		}},
+		{ID: "20260101-100002.000-0003", Batch: "L2", Domain: dominio, Operation: opCrearCategoriaProyecto, Items: []JournalItem{
+			{ID: "15", Name: "De otro lote", Applied: true},
+		}},
+	}
+	for _, entrada := range entradas {
+		if err := guardarEntradaDiario(entrada); err != nil {
+			t.Fatalf("no se pudo preparar el diario: %v", err)
+		}
+	}
+
+	lote, err := entradasADeshacer(dominio, JournalRevertRequest{Batch: "L1"})
+	if err != nil {
+		t.Fatalf("error seleccionando el lote: %v", err)
+	}
+	var ids []string
+	for _, e := range lote {
+		ids = append(ids, e.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{entradas[1].ID, entradas[0].ID}) {
+		t.Fatalf("entradas del lote = %v, se esperaban de la más reciente a la más antigua", ids)
+	}
+
+	client := conectarAJira(dominio, "correo", "token")
+	resultado, cambios := deshacerEntradas(client, lote, nil)
+	if !cambios || resultado.Reverted != 3 || resultado.Skipped != 2 || resultado.Failed != 0 {
+		t.Errorf("resultado = %+v", resultado)
+	}
+	// Cada objeto se deshace en orden inverso al que se hizo
+	if got := borrados(); !reflect.DeepEqual(got, []string{"12", "11", "10"}) {
+		t.Errorf("categorías borradas = %v, se esperaba [12 11 10]", got)
+	}
+	esperados := map[string]string{"Deshecha": "skipped", "Fallida": "skipped", "Tercera": "reverted", "Segunda": "reverted", "Primera": "reverted"}
+	for _, linea := range resultado.Items {
+		if linea.Result != esperados[linea.Name] {
+			t.Errorf("%s: resultado %q, se esperaba %q (%s)", linea.Name, linea.Result, esperados[linea.Name], linea.Message)
+		}
+	}
+
+	// Los objetos deshechos quedan marcados con la entrada de la reversión y no se repiten
+	marcada, err := cargarEntradaDiario(dominio, entradas[1].ID)
+	if err != nil {
+		t.Fatalf("no se pudo releer la entrada: %v", err)
+	}
+	if marcada.Items[0].RevertedBy == "" || marcada.Items[2].RevertedBy != entradas[1].Items[2].RevertedBy {
+		t.Errorf("marcas de reversión: %+v", marcada.Items)
+	}
+	reversion, err := cargarEntradaDiario(dominio, marcada.Items[0].RevertedBy)
+	if err != nil || reversion.RevertOf != entradas[1].ID || reversion.Batch != resultado.Batch {
+		t.Errorf("entrada de la reversión %+v (%v)", reversion, err)
+	}
+
+	lote, _ = entradasADeshacer(dominio, JournalRevertRequest{Batch: "L1"})
+	repetido, cambios := deshacerEntradas(client, lote, nil)
+	if cambios || repetido.Reverted != 0 || repetido.Skipped != 5 {
+		t.Errorf("al repetir la reversión: %+v", repetido)
+	}
+	if got := borrados(); len(got) != 3 {
+		t.Errorf("se ha vuelto a borrar en Jira: %v", got)
+	}
+}
+
+func TestEntradasADeshacerObjeto(t *testing.T) {
+	servidor, borrados := servidorCategorias(t)
+	entrada := JournalEntry{ID: "20260101-120000.000-0001", Batch: "L1", Domain: servidor.URL, Operation: opCrearCategoriaProyecto, Items: []JournalItem{
+		{ID: "20", Name: "A", Applied: true},
+		{ID: "21", Name: "B", Applied: true},
+	}}
+	if err := guardarEntradaDiario(entrada); err != nil {
+		t.Fatalf("no se pudo preparar el diario: %v", err)
+	}
+
+	fuera := 2
+	if _, err := entradasADeshacer(servidor.URL, JournalRevertRequest{Entry: entrada.ID, Item: &fuera}); err == nil {
+		t.Error("se esperaba un error por un objeto que no existe en la entrada")
+	}
+	if _, err := entradasADeshacer(servidor.URL, JournalRevertRequest{Batch: "no-existe"}); err == nil {
+		t.Error("se esperaba un error por un lote sin entradas")
+	}
+
+	primero := 0
+	entradas, err := entradasADeshacer(servidor.URL, JournalRevertRequest{Entry: entrada.ID, Item: &primero})
+	if err != nil {
+		t.Fatalf("error seleccionando la entrada: %v", err)
+	}
+	resultado, _ := deshacerEntradas(conectarAJira(servidor.URL, "correo", "token"), entradas, &primero)
+	if resultado.Reverted != 1 || len(resultado.Items) != 1 || resultado.Items[0].Name != "A" {
+		t.Errorf("resultado = %+v", resultado)
+	}
+	if got := borrados(); !reflect.DeepEqual(got, []string{"20"}) {
+		t.Errorf("categorías borradas = %v, solo se esperaba la 20", got)
+	}
+}
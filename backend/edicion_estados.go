@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Edición masiva de nombre, descripción y categoría de estados
+// ----------------------------------------------------------------
+
+// ambitoEstado devuelve una clave del ámbito del estado: los nombres solo deben ser únicos
+// entre los estados globales o entre los de un mismo proyecto team-managed.
+func ambitoEstado(st JiraStatus) string {
+	if st.Scope == nil || st.Scope.Type == "" || st.Scope.Type == "GLOBAL" {
+		return "GLOBAL"
+	}
+	if st.Scope.Project != nil {
+		return "PROJECT:" + st.Scope.Project.ID
+	}
+	return st.Scope.Type
+}
+
+// planificarEdicionEstados compara cada cambio con el estado del snapshot y devuelve el diff.
+// Los campos que no vienen en el cambio se mantienen. Se marcan como inválidos los cambios sobre
+// IDs desconocidos, con nombre vacío, con categoría no válida o que dejarían dos estados con el
+// mismo nombre en el mismo ámbito.
+func planificarEdicionEstados(cambios []StatusEditChange, estados []JiraStatus) []StatusEditDiff {
+	porID := make(map[string]JiraStatus, len(estados))
+	for _, st := range estados {
+		porID[st.ID] = st
+	}
+
+	// Primera pasada: validaciones de cada fila por separado
+	diffs := []StatusEditDiff{}
+	var validos []int
+	vistos := make(map[string]bool)
+	for _, c := range cambios {
+		diff := StatusEditDiff{ID: c.ID, Changes: []string{}, Result: "skipped"}
+		st, ok := porID[c.ID]
+		switch {
+		case vistos[c.ID]:
+			diff.Action, diff.Message = "invalid", "El estado aparece más de una vez en los cambios"
+			diffs = append(diffs, diff)
+			continue
+		case !ok:
+			diff.Action, diff.Message = "invalid", "El estado no existe en el snapshot"
+			diffs = append(diffs, diff)
+			continue
+		}
+		vistos[c.ID] = true
+
+		diff.Before = JiraStatusUpdate{ID: st.ID, Name: st.Name, Description: st.Description, StatusCategory: st.StatusCategory}
+		diff.After = diff.Before
+		if c.Name != nil {
+			diff.After.Name = strings.TrimSpace(*c.Name)
+		}
+		if c.Description != nil {
+			diff.After.Description = strings.TrimSpace(*c.Description)
+		}
+		if c.StatusCategory != nil {
+			categoria, ok := normalizarCategoria(*c.StatusCategory)
+			if !ok {
+				diff.Action, diff.Message = "invalid", fmt.Sprintf("Categoría %q no válida (TODO, IN_PROGRESS o DONE)", *c.StatusCategory)
+				diffs = append(diffs, diff)
+				continue
+			}
+			diff.After.StatusCategory = categoria
+		}
+		if diff.After.Name == "" {
+			diff.Action, diff.Message = "invalid", "El nombre no puede quedar vacío"
+			diffs = append(diffs, diff)
+			continue
+		}
+		validos = append(validos, len(diffs))
+		diffs = append(diffs, diff)
+	}
+
+	// Nombres finales por ámbito aplicando solo las filas válidas, para detectar choques
+	nombresNuevos := make(map[string]string, len(validos))
+	for _, i := range validos {
+		nombresNuevos[diffs[i].ID] = diffs[i].After.Name
+	}
+	nombresFinales := make(map[string][]string)
+	for _, st := range estados {
+		nombre := st.Name
+		if nuevo, ok := nombresNuevos[st.ID]; ok {
+			nombre = nuevo
+		}
+		clave := ambitoEstado(st) + "|" + strings.ToLower(nombre)
+		nombresFinales[clave] = append(nombresFinales[clave], st.ID)
+	}
+
+	// Segunda pasada: choques de nombre y cambios de las filas válidas
+	for _, i := range validos {
+		diff := &diffs[i]
+		st := porID[diff.ID]
+		if ids := nombresFinales[ambitoEstado(st)+"|"+strings.ToLower(diff.After.Name)]; len(ids) > 1 {
+			diff.Action, diff.Message = "invalid", fmt.Sprintf("El nombre %q quedaría repetido en los estados %s", diff.After.Name, strings.Join(ids, ", "))
+			continue
+		}
+
+		if diff.Before.Name != diff.After.Name {
+			diff.Changes = append(diff.Changes, cambio("nombre", diff.Before.Name, diff.After.Name))
+		}
+		if diff.Before.Description != diff.After.Description {
+			diff.Changes = append(diff.Changes, cambio("descripción", diff.Before.Description, diff.After.Description))
+		}
+		if diff.Before.StatusCategory != diff.After.StatusCategory {
+			diff.Changes = append(diff.Changes, cambio("categoría", diff.Before.StatusCategory, diff.After.StatusCategory))
+		}
+		if len(diff.Changes) == 0 {
+			diff.Action = "unchanged"
+		} else {
+			diff.Action, diff.Result = "update", "pending"
+		}
+	}
+	return diffs
+}
+
+// edicionMasivaEstados calcula el diff contra el snapshot de la conexión activa y, si no es dry-run,
+// envía los cambios a Jira y refresca en el snapshot los estados afectados.
+func edicionMasivaEstados(peticion StatusBulkEditRequest) (StatusBulkEditResult, error) {
+	resultado := StatusBulkEditResult{DryRun: peticion.DryRun}
+	if len(peticion.Changes) == 0 {
+		return resultado, fmt.Errorf("no se ha enviado ningún cambio")
+	}
+
+	conn, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		return resultado, err
+	}
+	if _, ok := snapshot["estados"]; !ok {
+		return resultado, fmt.Errorf("el snapshot no contiene estados; ejecuta antes la descarga de estados")
+	}
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		return resultado, err
+	}
+
+	diffs := planificarEdicionEstados(peticion.Changes, estados)
+
+	var cambios []JiraStatusUpdate
+	for _, d := range diffs {
+		if d.Action == "update" {
+			cambios = append(cambios, d.After)
+		}
+	}
+
+	if !peticion.DryRun && len(cambios) > 0 {
+		client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+		abrirLoteDiario(client)
+		enviados, errActualizar := actualizarEstadosJira(client, cambios)
+		cerrarLoteDiario(client)
+
+		actualizados := make(map[string]bool, enviados)
+		var ids []string
+		for _, c := range cambios[:enviados] {
+			actualizados[c.ID] = true
+			ids = append(ids, c.ID)
+		}
+		for i := range diffs {
+			if diffs[i].Action != "update" {
+				continue
+			}
+			if actualizados[diffs[i].ID] {
+				diffs[i].Result = "updated"
+			} else {
+				diffs[i].Result = "error"
+				if errActualizar != nil {
+					diffs[i].Message = errActualizar.Error()
+				}
+			}
+		}
+
+		// Se vuelven a leer de Jira los estados cambiados para guardar exactamente lo que quedó
+		if len(ids) > 0 {
+			refrescados, err := obtenerEstadosPorIDJira(client, ids)
+			if err != nil {
+				log.Println("Error al refrescar los estados actualizados:", err)
+			} else if err := guardarEstadosSnapshot(conn, refrescados, nil); err != nil {
+				log.Println("Error al actualizar los estados del snapshot:", err)
+			}
+		}
+	}
+
+	for _, d := range diffs {
+		switch {
+		case d.Action == "invalid":
+			resultado.Invalid++
+		case d.Result == "updated":
+			resultado.Updated++
+		case d.Result == "error":
+			resultado.Failed++
+		}
+	}
+	resultado.Diffs = diffs
+	return resultado, nil
+}
+
+// handleBulkEditStatuses recibe los cambios de estados y devuelve el diff y, si no es dry-run,
+// el resultado de aplicarlos.
+func handleBulkEditStatuses(w http.ResponseWriter, r *http.Request) {
+	var peticion StatusBulkEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := edicionMasivaEstados(peticion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
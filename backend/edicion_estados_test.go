@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// texto devuelve un puntero al valor, para los campos opcionales de los cambios.
+func texto(valor string) *string {
+	return &valor
+}
+
+func TestPlanificarEdicionEstados(t *testing.T) {
+	estados := []JiraStatus{
+		{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "2", Name: "In Progress", StatusCategory: "IN_PROGRESS", Description: "Trabajando"},
+		{ID: "3", Name: "Done", StatusCategory: "DONE"},
+		{ID: "4", Name: "Review", StatusCategory: "IN_PROGRESS", Scope: &JiraScope{Type: "PROJECT", Project: &JiraScopeProject{ID: "100"}}},
+	}
+	casos := []struct {
+		nombre   string
+		cambios  []StatusEditChange
+		acciones []string
+		mensajes []string
+	}{
+		{
+			nombre:   "renombrar y cambiar categoría",
+			cambios:  []StatusEditChange{{ID: "1", Name: texto(" Por hacer "), StatusCategory: texto("todo")}, {ID: "3", Name: texto("Done"), StatusCategory: texto("in progress")}},
+			acciones: []string{"update", "update"},
+		},
+		{
+			nombre:   "sin cambios",
+			cambios:  []StatusEditChange{{ID: "2", Name: texto("In Progress"), Description: texto("Trabajando")}},
+			acciones: []string{"unchanged"},
+		},
+		{
+			nombre:   "ID desconocido, repetido, sin nombre y categoría no válida",
+			cambios:  []StatusEditChange{{ID: "9", Name: texto("X")}, {ID: "1", Name: texto("A")}, {ID: "1", Name: texto("B")}, {ID: "2", Name: texto(" ")}, {ID: "3", Name: texto("Done"), StatusCategory: texto("WAITING")}},
+			acciones: []string{"invalid", "update", "invalid", "invalid", "invalid"},
+			mensajes: []string{"no existe en el snapshot", "", "más de una vez", "no puede quedar vacío", "Categoría \"WAITING\" no válida"},
+		},
+		{
+			nombre:   "nombre que ya usa otro estado",
+			cambios:  []StatusEditChange{{ID: "1", Name: texto("done")}},
+			acciones: []string{"invalid"},
+			mensajes: []string{"quedaría repetido en los estados 1, 3"},
+		},
+		{
+			nombre:   "intercambio de nombres",
+			cambios:  []StatusEditChange{{ID: "1", Name: texto("Done")}, {ID: "3", Name: texto("To Do")}},
+			acciones: []string{"update", "update"},
+		},
+		{
+			nombre:   "dos estados renombrados igual",
+			cambios:  []StatusEditChange{{ID: "1", Name: texto("Nuevo")}, {ID: "2", Name: texto("NUEVO")}},
+			acciones: []string{"invalid", "invalid"},
+		},
+		{
+			nombre:   "una fila rechazada no provoca choques",
+			cambios:  []StatusEditChange{{ID: "1", Name: texto("Nuevo"), StatusCategory: texto("WAITING")}, {ID: "2", Name: texto("Nuevo")}},
+			acciones: []string{"invalid", "update"},
+			mensajes: []string{"no válida", ""},
+		},
+		{
+			nombre:   "los campos que no se envían se mantienen",
+			cambios:  []StatusEditChange{{ID: "2"}, {ID: "3", StatusCategory: texto("IN_PROGRESS")}},
+			acciones: []string{"unchanged", "update"},
+		},
+		{
+			nombre:   "una descripción vacía la borra",
+			cambios:  []StatusEditChange{{ID: "2", Description: texto("")}},
+			acciones: []string{"update"},
+		},
+		{
+			nombre:   "categoría vacía",
+			cambios:  []StatusEditChange{{ID: "2", StatusCategory: texto("")}},
+			acciones: []string{"invalid"},
+			mensajes: []string{"no válida"},
+		},
+		{
+			nombre:   "mismo nombre en otro ámbito",
+			cambios:  []StatusEditChange{{ID: "2", Name: texto("Review")}},
+			acciones: []string{"update"},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			diffs := planificarEdicionEstados(caso.cambios, estados)
+			acciones := make([]string, len(diffs))
+			for i, d := range diffs {
+				acciones[i] = d.Action
+				esperado := "skipped"
+				if d.Action == "update" {
+					esperado = "pending"
+				}
+				if d.Result != esperado {
+					t.Errorf("fila %d: resultado %q con acción %q", i, d.Result, d.Action)
+				}
+				if i < len(caso.mensajes) && !strings.Contains(d.Message, caso.mensajes[i]) {
+					t.Errorf("fila %d: mensaje %q, se esperaba que contuviera %q", i, d.Message, caso.mensajes[i])
+				}
+			}
+			if !reflect.DeepEqual(acciones, caso.acciones) {
+				t.Errorf("acciones = %v, se esperaba %v", acciones, caso.acciones)
+			}
+		})
+	}
+}
+
+func TestPlanificarEdicionEstadosCambios(t *testing.T) {
+	estados := []JiraStatus{{ID: "1", Name: "To Do", StatusCategory: "TODO", Description: "Pendiente"}}
+	diffs := planificarEdicionEstados([]StatusEditChange{{ID: "1", Name: texto("Por hacer"), Description: texto(""), StatusCategory: texto("DONE")}}, estados)
+	if len(diffs) != 1 {
+		t.Fatalf("se esperaba un diff, hay %d", len(diffs))
+	}
+	d := diffs[0]
+	esperado := JiraStatusUpdate{ID: "1", Name: "Por hacer", StatusCategory: "DONE"}
+	if d.After != esperado {
+		t.Errorf("After = %+v, se esperaba %+v", d.After, esperado)
+	}
+	cambios := []string{
+		cambio("nombre", "To Do", "Por hacer"),
+		cambio("descripción", "Pendiente", ""),
+		cambio("categoría", "TODO", "DONE"),
+	}
+	if !reflect.DeepEqual(d.Changes, cambios) {
+		t.Errorf("Changes = %v, se esperaba %v", d.Changes, cambios)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ----------------------------------------------------------------
+// Escrituras en Jira. Todas las peticiones que modifican la configuración pasan por aquí y
+// quedan registradas en el diario (diario.go) con el estado previo de cada objeto.
+// ----------------------------------------------------------------
+
+// Máximo de estados que admite Jira en cada petición de creación, edición o borrado masivo
+const maxEstadosPorPeticion = 50
+
+// enviarJSON hace una petición de escritura (POST, PUT o DELETE) con el cuerpo indicado y, si dest
+// no es nil, deserializa la respuesta en él. Cualquier respuesta 2xx se considera correcta.
+func enviarJSON(client *resty.Client, metodo, path string, params url.Values, cuerpo interface{}, etiqueta string, dest interface{}) error {
+	req := client.R().
+		SetQueryParamsFromValues(params).
+		SetHeader("Content-Type", "application/json")
+	if cuerpo != nil {
+		req.SetBody(cuerpo)
+	}
+
+	resp, err := req.Execute(metodo, path)
+	if err != nil {
+		return fmt.Errorf("error en petición a Jira (%s): %w", etiqueta, err)
+	}
+	if resp.StatusCode() < http.StatusOK || resp.StatusCode() >= http.StatusMultipleChoices {
+		return fmt.Errorf("error en la petición (%s): %d - %s", etiqueta, resp.StatusCode(), resp.String())
+	}
+	if dest != nil && len(resp.Body()) > 0 {
+		if err := json.Unmarshal(resp.Body(), dest); err != nil {
+			return fmt.Errorf("error al parsear JSON (%s): %w", etiqueta, err)
+		}
+	}
+	return nil
+}
+
+// crearEstadosJira crea estados globales con POST /rest/api/3/statuses en bloques de
+// maxEstadosPorPeticion y devuelve los estados creados con su ID. Si falla un bloque se devuelven
+// los estados creados hasta ese momento junto con el error.
+func crearEstadosJira(client *resty.Client, nuevos []JiraStatus) ([]JiraStatus, error) {
+	return crearEstadosEnAmbitoJira(client, nuevos, JiraScope{Type: "GLOBAL"})
+}
+
+// crearEstadosEnAmbitoJira crea los estados en el ámbito indicado: global o el de un proyecto
+// team-managed.
+func crearEstadosEnAmbitoJira(client *resty.Client, nuevos []JiraStatus, ambito JiraScope) ([]JiraStatus, error) {
+	entrada := nuevaEntradaDiario(client, opCrearEstados, "estado")
+	var creados []JiraStatus
+	for inicio := 0; inicio < len(nuevos); inicio += maxEstadosPorPeticion {
+		fin := min(inicio+maxEstadosPorPeticion, len(nuevos))
+
+		cuerpo := JiraStatusCreateRequest{Scope: ambito}
+		for _, st := range nuevos[inicio:fin] {
+			cuerpo.Statuses = append(cuerpo.Statuses, JiraStatusCreate{
+				Name:           st.Name,
+				Description:    st.Description,
+				StatusCategory: st.StatusCategory,
+			})
+		}
+
+		var respuesta []JiraStatus
+		if err := enviarJSON(client, http.MethodPost, "/rest/api/3/statuses", nil, cuerpo, "crear estados", &respuesta); err != nil {
+			registrarDiario(client, &entrada, err)
+			return creados, err
+		}
+		for _, st := range respuesta {
+			entrada.Items = append(entrada.Items, JournalItem{ID: st.ID, Name: st.Name, After: crudo(st), Applied: true})
+		}
+		creados = append(creados, respuesta...)
+	}
+
+	registrarDiario(client, &entrada, nil)
+	log.Println("Total estados creados:", len(creados))
+	return creados, nil
+}
+
+// estadosPrevios lee de Jira los estados indicados justo antes de modificarlos o borrarlos, para
+// guardarlos en el diario.
+func estadosPrevios(client *resty.Client, ids []string) (map[string]JiraStatus, error) {
+	vivos, err := obtenerEstadosPorIDJira(client, ids)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el estado previo para el diario: %w", err)
+	}
+	porID := make(map[string]JiraStatus, len(vivos))
+	for _, st := range vivos {
+		porID[st.ID] = st
+	}
+	return porID, nil
+}
+
+// actualizarEstadosJira cambia nombre, descripción y categoría de estados existentes con
+// PUT /rest/api/3/statuses en bloques de maxEstadosPorPeticion. Devuelve cuántos se enviaron
+// correctamente antes del primer error.
+func actualizarEstadosJira(client *resty.Client, cambios []JiraStatusUpdate) (int, error) {
+	entrada := nuevaEntradaDiario(client, opActualizarEstados, "estado")
+	enviados := 0
+	for inicio := 0; inicio < len(cambios); inicio += maxEstadosPorPeticion {
+		fin := min(inicio+maxEstadosPorPeticion, len(cambios))
+		bloque := cambios[inicio:fin]
+
+		ids := make([]string, 0, len(bloque))
+		for _, cambio := range bloque {
+			ids = append(ids, cambio.ID)
+		}
+		previos, err := estadosPrevios(client, ids)
+		if err != nil {
+			registrarDiario(client, &entrada, err)
+			return enviados, err
+		}
+		primero := len(entrada.Items)
+		for _, cambio := range bloque {
+			item := JournalItem{ID: cambio.ID, Name: cambio.Name, After: crudo(cambio)}
+			if previo, ok := previos[cambio.ID]; ok {
+				item.Before = crudo(previo)
+			}
+			entrada.Items = append(entrada.Items, item)
+		}
+
+		cuerpo := JiraStatusUpdateRequest{Statuses: bloque}
+		if err := enviarJSON(client, http.MethodPut, "/rest/api/3/statuses", nil, cuerpo, "actualizar estados", nil); err != nil {
+			registrarDiario(client, &entrada, err)
+			return enviados, err
+		}
+		for i := primero; i < len(entrada.Items); i++ {
+			entrada.Items[i].Applied = true
+		}
+		enviados = fin
+	}
+
+	registrarDiario(client, &entrada, nil)
+	log.Println("Total estados actualizados:", enviados)
+	return enviados, nil
+}
+
+// borrarEstadosJira borra estados con DELETE /rest/api/3/statuses?id=... en bloques de
+// maxEstadosPorPeticion. Devuelve los IDs borrados antes del primer error.
+func borrarEstadosJira(client *resty.Client, ids []string) ([]string, error) {
+	entrada := nuevaEntradaDiario(client, opBorrarEstados, "estado")
+	var borrados []string
+	for _, bloque := range agruparIDs(ids, maxEstadosPorPeticion) {
+		previos, err := estadosPrevios(client, bloque)
+		if err != nil {
+			registrarDiario(client, &entrada, err)
+			return borrados, err
+		}
+		primero := len(entrada.Items)
+		for _, id := range bloque {
+			item := JournalItem{ID: id}
+			if previo, ok := previos[id]; ok {
+				item.Name = previo.Name
+				item.Before = crudo(previo)
+			}
+			entrada.Items = append(entrada.Items, item)
+		}
+
+		if err := enviarJSON(client, http.MethodDelete, "/rest/api/3/statuses", url.Values{"id": bloque}, nil, "borrar estados", nil); err != nil {
+			registrarDiario(client, &entrada, err)
+			return borrados, err
+		}
+		for i := primero; i < len(entrada.Items); i++ {
+			entrada.Items[i].Applied = true
+		}
+		borrados = append(borrados, bloque...)
+	}
+
+	registrarDiario(client, &entrada, nil)
+	log.Println("Total estados borrados:", len(borrados))
+	return borrados, nil
+}
+
+// workflowsPrevios lee de Jira la definición de los workflows indicados justo antes de
+// modificarlos o borrarlos. Cada uno se guarda en el diario como una petición de escritura
+// completa, con los estados que referencia, lista para reenviarse al deshacer.
+func workflowsPrevios(client *resty.Client, ids []string) (map[string]JiraWorkflowWriteRequest, error) {
+	lectura, err := leerWorkflowsJira(client, ids, nil)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el workflow previo para el diario: %w", err)
+	}
+	porID := make(map[string]JiraWorkflowWriteRequest, len(lectura.Workflows))
+	for _, wf := range lectura.Workflows {
+		porID[wf.ID] = JiraWorkflowWriteRequest{Statuses: lectura.Statuses, Workflows: []JiraWorkflowWrite{wf}}
+	}
+	return porID, nil
+}
+
+// crearWorkflowJira crea workflows globales con POST /rest/api/3/workflows/create.
+func crearWorkflowJira(client *resty.Client, cuerpo JiraWorkflowWriteRequest) error {
+	entrada := nuevaEntradaDiario(client, opCrearWorkflow, "workflow")
+	cuerpo.Scope = &JiraScope{Type: "GLOBAL"}
+	err := enviarJSON(client, http.MethodPost, "/rest/api/3/workflows/create", nil, cuerpo, "crear workflow", nil)
+	for _, wf := range cuerpo.Workflows {
+		entrada.Items = append(entrada.Items, JournalItem{
+			Name:    wf.Name,
+			After:   crudo(JiraWorkflowWriteRequest{Statuses: cuerpo.Statuses, Workflows: []JiraWorkflowWrite{wf}}),
+			Applied: err == nil,
+		})
+	}
+	registrarDiario(client, &entrada, err)
+	return err
+}
+
+// actualizarWorkflowJira actualiza workflows existentes con POST /rest/api/3/workflows/update.
+// Cada workflow debe llevar su ID y la versión actual (ver obtenerWorkflowVivoJira).
+func actualizarWorkflowJira(client *resty.Client, cuerpo JiraWorkflowWriteRequest) error {
+	entrada := nuevaEntradaDiario(client, opActualizarWorkflow, "workflow")
+	ids := make([]string, 0, len(cuerpo.Workflows))
+	for _, wf := range cuerpo.Workflows {
+		ids = append(ids, wf.ID)
+	}
+	previos, err := workflowsPrevios(client, ids)
+	if err != nil {
+		registrarDiario(client, &entrada, err)
+		return err
+	}
+
+	cuerpo.Scope = nil
+	err = enviarJSON(client, http.MethodPost, "/rest/api/3/workflows/update", nil, cuerpo, "actualizar workflow", nil)
+	for _, wf := range cuerpo.Workflows {
+		item := JournalItem{
+			ID:      wf.ID,
+			Name:    wf.Name,
+			After:   crudo(JiraWorkflowWriteRequest{Statuses: cuerpo.Statuses, Workflows: []JiraWorkflowWrite{wf}}),
+			Applied: err == nil,
+		}
+		if previo, ok := previos[wf.ID]; ok {
+			item.Name = previo.Workflows[0].Name
+			item.Before = crudo(previo)
+		}
+		entrada.Items = append(entrada.Items, item)
+	}
+	registrarDiario(client, &entrada, err)
+	return err
+}
+
+// borrarWorkflowJira borra un workflow inactivo con DELETE /rest/api/3/workflow/{entityId}.
+func borrarWorkflowJira(client *resty.Client, id string) error {
+	entrada := nuevaEntradaDiario(client, opBorrarWorkflow, "workflow")
+	previos, err := workflowsPrevios(client, []string{id})
+	if err != nil {
+		registrarDiario(client, &entrada, err)
+		return err
+	}
+
+	err = enviarJSON(client, http.MethodDelete, "/rest/api/3/workflow/"+url.PathEscape(id), nil, nil, "borrar workflow", nil)
+	item := JournalItem{ID: id, Applied: err == nil}
+	if previo, ok := previos[id]; ok {
+		item.Name = previo.Workflows[0].Name
+		item.Before = crudo(previo)
+	}
+	entrada.Items = append(entrada.Items, item)
+	registrarDiario(client, &entrada, err)
+	return err
+}
+
+// crearCategoriaProyectoJira crea una categoría de proyecto y devuelve la categoría con su ID.
+func crearCategoriaProyectoJira(client *resty.Client, categoria ProjectCategory) (ProjectCategory, error) {
+	entrada := nuevaEntradaDiario(client, opCrearCategoriaProyecto, "categoriaProyecto")
+	var creada ProjectCategory
+	cuerpo := ProjectCategory{Name: categoria.Name, Description: categoria.Description}
+	err := enviarJSON(client, http.MethodPost, "/rest/api/3/projectCategory", nil, cuerpo, "crear categoría de proyecto", &creada)
+	entrada.Items = append(entrada.Items, JournalItem{ID: creada.ID, Name: categoria.Name, After: crudo(creada), Applied: err == nil})
+	registrarDiario(client, &entrada, err)
+	return creada, err
+}
+
+// actualizarCategoriaProyectoJira cambia el nombre y la descripción de una categoría de proyecto.
+func actualizarCategoriaProyectoJira(client *resty.Client, categoria ProjectCategory) error {
+	entrada := nuevaEntradaDiario(client, opActualizarCategoriaProyecto, "categoriaProyecto")
+	previa, err := obtenerCategoriaProyectoJira(client, categoria.ID)
+	if err != nil {
+		err = fmt.Errorf("no se pudo leer la categoría previa para el diario: %w", err)
+		registrarDiario(client, &entrada, err)
+		return err
+	}
+
+	cuerpo := ProjectCategory{Name: categoria.Name, Description: categoria.Description}
+	err = enviarJSON(client, http.MethodPut, "/rest/api/3/projectCategory/"+url.PathEscape(categoria.ID), nil, cuerpo, "actualizar categoría de proyecto", nil)
+	entrada.Items = append(entrada.Items, JournalItem{ID: categoria.ID, Name: previa.Name, Before: crudo(previa), After: crudo(categoria), Applied: err == nil})
+	registrarDiario(client, &entrada, err)
+	return err
+}
+
+// borrarCategoriaProyectoJira borra una categoría de proyecto por ID.
+func borrarCategoriaProyectoJira(client *resty.Client, id string) error {
+	entrada := nuevaEntradaDiario(client, opBorrarCategoriaProyecto, "categoriaProyecto")
+	previa, err := obtenerCategoriaProyectoJira(client, id)
+	if err != nil {
+		err = fmt.Errorf("no se pudo leer la categoría previa para el diario: %w", err)
+		registrarDiario(client, &entrada, err)
+		return err
+	}
+
+	err = enviarJSON(client, http.MethodDelete, "/rest/api/3/projectCategory/"+url.PathEscape(id), nil, nil, "borrar categoría de proyecto", nil)
+	entrada.Items = append(entrada.Items, JournalItem{ID: id, Name: previa.Name, Before: crudo(previa), Applied: err == nil})
+	registrarDiario(client, &entrada, err)
+	return err
+}
+
+// guardarEstadosSnapshot guarda en el historial una versión nueva de la sección de estados: la
+// actual, con los estados de "actualizados" añadidos o sustituidos (por ID) y sin los IDs de
+// "borrados". Así el snapshot refleja las escrituras sin tener que volver a descargar todos los
+// estados.
+func guardarEstadosSnapshot(conn Credentials, actualizados []JiraStatus, borrados []string) error {
+	snapshot, err := componerSnapshot(conn.Domain)
+	if err != nil {
+		return err
+	}
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		return err
+	}
+
+	quitar := make(map[string]bool, len(borrados))
+	for _, id := range borrados {
+		quitar[id] = true
+	}
+	porID := make(map[string]JiraStatus, len(actualizados))
+	for _, st := range actualizados {
+		porID[st.ID] = st
+	}
+
+	resultado := make([]JiraStatus, 0, len(estados)+len(actualizados))
+	for _, st := range estados {
+		if quitar[st.ID] {
+			continue
+		}
+		if nuevo, ok := porID[st.ID]; ok {
+			resultado = append(resultado, nuevo)
+			delete(porID, st.ID)
+			continue
+		}
+		resultado = append(resultado, st)
+	}
+	// Los que no estaban en el snapshot son estados nuevos
+	for _, st := range actualizados {
+		if _, ok := porID[st.ID]; ok {
+			resultado = append(resultado, st)
+		}
+	}
+
+	_, err = guardarSnapshotHistorial(conn, origenEscritura, map[string]interface{}{"estados": resultado})
+	return err
+}
+
+// guardarWorkflowsSnapshot guarda en el historial una versión nueva de la sección de workflows
+// con los workflows indicados añadidos o sustituidos (por nombre).
+func guardarWorkflowsSnapshot(conn Credentials, actualizados []JiraWorkflow) error {
+	snapshot, err := componerSnapshot(conn.Domain)
+	if err != nil {
+		return err
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		return err
+	}
+
+	for _, nuevo := range actualizados {
+		sustituido := false
+		for i := range workflows {
+			if workflows[i].ID.Name == nuevo.ID.Name {
+				workflows[i] = nuevo
+				sustituido = true
+				break
+			}
+		}
+		if !sustituido {
+			workflows = append(workflows, nuevo)
+		}
+	}
+
+	_, err = guardarSnapshotHistorial(conn, origenEscritura, map[string]interface{}{"workflows": workflows})
+	return err
+}
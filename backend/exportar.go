@@ -0,0 +1,432 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ----------------------------------------------------------------
+// Exportación de workflows a Graphviz DOT, diagrama de estados Mermaid y SVG
+// ----------------------------------------------------------------
+
+// Colores de relleno y de texto por categoría de estado (los mismos que usa Jira)
+var coloresCategoria = map[string][2]string{
+	"TODO":        {"#DFE1E6", "#42526E"},
+	"IN_PROGRESS": {"#DEEBFF", "#0747A6"},
+	"DONE":        {"#E3FCEF", "#006644"},
+}
+
+// colorCategoria devuelve el color de relleno y de texto de una categoría.
+func colorCategoria(categoria string) (string, string) {
+	if c, ok := coloresCategoria[categoria]; ok {
+		return c[0], c[1]
+	}
+	return "#FFFFFF", "#172B4D"
+}
+
+// idsEstadosOrdenados devuelve los IDs de los estados del workflow en el orden de su lista de
+// estados, seguidos de los que solo aparecen en transiciones.
+func idsEstadosOrdenados(wf JiraWorkflow) []string {
+	vistos := make(map[string]bool)
+	var ids []string
+	for _, st := range wf.Statuses {
+		if !vistos[st.ID] {
+			vistos[st.ID] = true
+			ids = append(ids, st.ID)
+		}
+	}
+	var extra []string
+	for id := range estadosDeWorkflow(wf) {
+		if !vistos[id] {
+			extra = append(extra, id)
+		}
+	}
+	sort.Strings(extra)
+	return append(ids, extra...)
+}
+
+// tieneTransicionesGlobales indica si el workflow tiene alguna transición global.
+func tieneTransicionesGlobales(wf JiraWorkflow) bool {
+	for _, t := range wf.Transitions {
+		if t.Type == "global" {
+			return true
+		}
+	}
+	return false
+}
+
+// escaparDOT escapa comillas y barras para usar un texto entre comillas en DOT.
+func escaparDOT(texto string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(texto)
+}
+
+// exportarWorkflowDOT genera el grafo DOT del workflow, con los estados coloreados por categoría
+// y las transiciones etiquetadas con su nombre.
+func exportarWorkflowDOT(wf JiraWorkflow, categorias map[string]string) string {
+	nombres := nombresEstadosWorkflow(wf)
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph \"%s\" {\n", escaparDOT(wf.ID.Name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  \"__inicio\" [shape=circle, label=\"\", width=0.25, style=filled, fillcolor=\"#172B4D\"];\n")
+	if tieneTransicionesGlobales(wf) {
+		b.WriteString("  \"__global\" [shape=plaintext, style=\"\", label=\"Cualquier estado\"];\n")
+	}
+	for _, id := range idsEstadosOrdenados(wf) {
+		relleno, texto := colorCategoria(categorias[id])
+		fmt.Fprintf(&b, "  \"s%s\" [label=\"%s\", fillcolor=\"%s\", fontcolor=\"%s\"];\n",
+			escaparDOT(id), escaparDOT(nombreOID(nombres, id)), relleno, texto)
+	}
+	for _, t := range wf.Transitions {
+		etiqueta := escaparDOT(t.Name)
+		destino := "s" + escaparDOT(t.To)
+		switch t.Type {
+		case "initial":
+			fmt.Fprintf(&b, "  \"__inicio\" -> \"%s\" [label=\"%s\"];\n", destino, etiqueta)
+		case "global":
+			fmt.Fprintf(&b, "  \"__global\" -> \"%s\" [label=\"%s\", style=dashed];\n", destino, etiqueta)
+		default:
+			for _, from := range t.From {
+				fmt.Fprintf(&b, "  \"s%s\" -> \"%s\" [label=\"%s\"];\n", escaparDOT(from), destino, etiqueta)
+			}
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nombreOID devuelve el nombre del estado o, si el workflow no lo trae, su ID.
+func nombreOID(nombres map[string]string, id string) string {
+	if n, ok := nombres[id]; ok && n != "" {
+		return n
+	}
+	return id
+}
+
+// escaparMermaid sustituye los caracteres que rompen las etiquetas de Mermaid por sus entidades.
+func escaparMermaid(texto string) string {
+	return strings.NewReplacer(`"`, "#quot;", ":", "#58;", ";", "#59;").Replace(texto)
+}
+
+// exportarWorkflowMermaid genera un stateDiagram-v2 de Mermaid con clases por categoría.
+func exportarWorkflowMermaid(wf JiraWorkflow, categorias map[string]string) string {
+	nombres := nombresEstadosWorkflow(wf)
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	for _, categoria := range []string{"TODO", "IN_PROGRESS", "DONE"} {
+		relleno, texto := colorCategoria(categoria)
+		fmt.Fprintf(&b, "    classDef %s fill:%s,color:%s\n", strings.ToLower(strings.ReplaceAll(categoria, "_", "")), relleno, texto)
+	}
+
+	ids := idsEstadosOrdenados(wf)
+	for _, id := range ids {
+		fmt.Fprintf(&b, "    state \"%s\" as s%s\n", escaparMermaid(nombreOID(nombres, id)), id)
+	}
+	if tieneTransicionesGlobales(wf) {
+		b.WriteString("    state \"Cualquier estado\" as global\n")
+	}
+	for _, t := range wf.Transitions {
+		etiqueta := ""
+		if t.Name != "" {
+			etiqueta = " : " + escaparMermaid(t.Name)
+		}
+		switch t.Type {
+		case "initial":
+			fmt.Fprintf(&b, "    [*] --> s%s%s\n", t.To, etiqueta)
+		case "global":
+			fmt.Fprintf(&b, "    global --> s%s%s\n", t.To, etiqueta)
+		default:
+			for _, from := range t.From {
+				fmt.Fprintf(&b, "    s%s --> s%s%s\n", from, t.To, etiqueta)
+			}
+		}
+	}
+	for _, id := range ids {
+		if categoria, ok := categorias[id]; ok && categoria != "" {
+			fmt.Fprintf(&b, "    class s%s %s\n", id, strings.ToLower(strings.ReplaceAll(categoria, "_", "")))
+		}
+	}
+	return b.String()
+}
+
+// Medidas del SVG
+const (
+	svgAnchoNodo    = 170.0
+	svgAltoNodo     = 44.0
+	svgSeparacionX  = 240.0
+	svgSeparacionY  = 90.0
+	svgMargen       = 40.0
+	svgRadioInicial = 9.0
+)
+
+type nodoSVG struct {
+	x, y     float64 // centro
+	etiqueta string
+	relleno  string
+	texto    string
+	especial string // "inicio", "global" o vacío para estados
+}
+
+// nivelesWorkflow coloca cada estado en una columna según su distancia a la transición inicial.
+// Los estados inalcanzables van a una columna final.
+func nivelesWorkflow(wf JiraWorkflow) map[string]int {
+	salidas := make(map[string][]string)
+	var pendientes []string
+	for _, t := range wf.Transitions {
+		switch t.Type {
+		case "initial", "global":
+			pendientes = append(pendientes, t.To)
+		default:
+			for _, from := range t.From {
+				salidas[from] = append(salidas[from], t.To)
+			}
+		}
+	}
+
+	niveles := make(map[string]int)
+	for _, id := range pendientes {
+		niveles[id] = 1
+	}
+	for len(pendientes) > 0 {
+		id := pendientes[0]
+		pendientes = pendientes[1:]
+		for _, destino := range salidas[id] {
+			if _, ok := niveles[destino]; !ok {
+				niveles[destino] = niveles[id] + 1
+				pendientes = append(pendientes, destino)
+			}
+		}
+	}
+
+	maximo := 1
+	for _, n := range niveles {
+		if n > maximo {
+			maximo = n
+		}
+	}
+	for _, id := range idsEstadosOrdenados(wf) {
+		if _, ok := niveles[id]; !ok {
+			niveles[id] = maximo + 1
+		}
+	}
+	return niveles
+}
+
+// bordeNodo devuelve el punto del borde del nodo en dirección (dx, dy) desde su centro.
+func bordeNodo(n nodoSVG, dx, dy float64) (float64, float64) {
+	if n.especial == "inicio" {
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			return n.x, n.y
+		}
+		return n.x + dx/d*svgRadioInicial, n.y + dy/d*svgRadioInicial
+	}
+	t := math.Inf(1)
+	if dx != 0 {
+		t = math.Min(t, (svgAnchoNodo/2)/math.Abs(dx))
+	}
+	if dy != 0 {
+		t = math.Min(t, (svgAltoNodo/2)/math.Abs(dy))
+	}
+	if math.IsInf(t, 1) {
+		return n.x, n.y
+	}
+	return n.x + dx*t, n.y + dy*t
+}
+
+// exportarWorkflowSVG dibuja el workflow en SVG con un layout por columnas (distancia desde la
+// transición inicial), estados coloreados por categoría y transiciones etiquetadas.
+func exportarWorkflowSVG(wf JiraWorkflow, categorias map[string]string) string {
+	nombres := nombresEstadosWorkflow(wf)
+	niveles := nivelesWorkflow(wf)
+
+	// Posición de cada nodo: columna por nivel y fila por orden de aparición en la columna
+	nodos := make(map[string]nodoSVG)
+	filas := make(map[int]int)
+	maxNivel, maxFila := 0, 1
+	for _, id := range idsEstadosOrdenados(wf) {
+		nivel := niveles[id]
+		fila := filas[nivel]
+		filas[nivel]++
+		relleno, texto := colorCategoria(categorias[id])
+		nodos[id] = nodoSVG{
+			x:        svgMargen + svgAnchoNodo/2 + float64(nivel)*svgSeparacionX - svgSeparacionX/2,
+			y:        svgMargen + svgAltoNodo/2 + float64(fila)*svgSeparacionY,
+			etiqueta: nombreOID(nombres, id),
+			relleno:  relleno,
+			texto:    texto,
+		}
+		if nivel > maxNivel {
+			maxNivel = nivel
+		}
+		if filas[nivel] > maxFila {
+			maxFila = filas[nivel]
+		}
+	}
+	nodos["__inicio"] = nodoSVG{x: svgMargen, y: svgMargen + svgAltoNodo/2, especial: "inicio"}
+	if tieneTransicionesGlobales(wf) {
+		nodos["__global"] = nodoSVG{x: svgMargen + 40, y: svgMargen + svgAltoNodo/2 + float64(maxFila)*svgSeparacionY, etiqueta: "Cualquier estado", especial: "global"}
+		maxFila++
+	}
+
+	// El último nodo de la columna más alejada marca el ancho; la columna con más filas, el alto
+	ancho := svgMargen + svgAnchoNodo + float64(maxNivel)*svgSeparacionX - svgSeparacionX/2 + svgMargen
+	alto := svgMargen*2 + float64(maxFila-1)*svgSeparacionY + svgAltoNodo
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n", ancho, alto, ancho, alto)
+	b.WriteString(`<defs><marker id="flecha" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#6B778C"/></marker></defs>` + "\n")
+	fmt.Fprintf(&b, `<title>%s</title>`+"\n", html.EscapeString(wf.ID.Name))
+
+	// Transiciones primero para que queden por debajo de los nodos
+	for _, t := range wf.Transitions {
+		var origenes []string
+		switch t.Type {
+		case "initial":
+			origenes = []string{"__inicio"}
+		case "global":
+			origenes = []string{"__global"}
+		default:
+			origenes = t.From
+		}
+		destino, ok := nodos[t.To]
+		if !ok {
+			continue
+		}
+		for _, from := range origenes {
+			origen, ok := nodos[from]
+			if !ok {
+				continue
+			}
+			dibujarTransicionSVG(&b, origen, destino, from == t.To, t.Name, t.Type == "global")
+		}
+	}
+
+	orden := append([]string{"__inicio", "__global"}, idsEstadosOrdenados(wf)...)
+	for _, id := range orden {
+		n, ok := nodos[id]
+		if !ok {
+			continue
+		}
+		switch n.especial {
+		case "inicio":
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%.0f" fill="#172B4D"/>`+"\n", n.x, n.y, svgRadioInicial)
+		case "global":
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="12" font-style="italic" fill="#6B778C" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n", n.x, n.y, html.EscapeString(n.etiqueta))
+		default:
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.0f" height="%.0f" rx="6" fill="%s" stroke="#6B778C"/>`+"\n",
+				n.x-svgAnchoNodo/2, n.y-svgAltoNodo/2, svgAnchoNodo, svgAltoNodo, n.relleno)
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="13" font-weight="bold" fill="%s" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+				n.x, n.y, n.texto, html.EscapeString(recortarTexto(strings.ToUpper(n.etiqueta), 22)))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// dibujarTransicionSVG dibuja una transición como curva con flecha y su nombre en el punto medio.
+// La curva se desplaza hacia un lado para que las transiciones de ida y vuelta no se solapen.
+func dibujarTransicionSVG(b *strings.Builder, origen, destino nodoSVG, bucle bool, nombre string, discontinua bool) {
+	estilo := ""
+	if discontinua {
+		estilo = ` stroke-dasharray="5,4"`
+	}
+
+	// Transición de un estado a sí mismo: bucle por encima del nodo
+	if bucle {
+		x1, y1 := origen.x-20, origen.y-svgAltoNodo/2
+		x2, y2 := origen.x+20, origen.y-svgAltoNodo/2
+		fmt.Fprintf(b, `<path d="M %.1f %.1f C %.1f %.1f %.1f %.1f %.1f %.1f" fill="none" stroke="#6B778C"%s marker-end="url(#flecha)"/>`+"\n",
+			x1, y1, x1-10, y1-40, x2+10, y2-40, x2, y2, estilo)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="10" fill="#42526E" text-anchor="middle">%s</text>`+"\n",
+			origen.x, y1-34, html.EscapeString(nombre))
+		return
+	}
+
+	dx, dy := destino.x-origen.x, destino.y-origen.y
+	distancia := math.Hypot(dx, dy)
+	// Punto de control desplazado en perpendicular a la línea entre los centros
+	curva := 0.15 * distancia
+	cx := (origen.x+destino.x)/2 - dy/distancia*curva
+	cy := (origen.y+destino.y)/2 + dx/distancia*curva
+
+	x1, y1 := bordeNodo(origen, cx-origen.x, cy-origen.y)
+	x2, y2 := bordeNodo(destino, cx-destino.x, cy-destino.y)
+	fmt.Fprintf(b, `<path d="M %.1f %.1f Q %.1f %.1f %.1f %.1f" fill="none" stroke="#6B778C"%s marker-end="url(#flecha)"/>`+"\n",
+		x1, y1, cx, cy, x2, y2, estilo)
+
+	// Punto medio de la curva cuadrática
+	mx := 0.25*x1 + 0.5*cx + 0.25*x2
+	my := 0.25*y1 + 0.5*cy + 0.25*y2
+	if nombre != "" {
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="10" fill="#42526E" text-anchor="middle" paint-order="stroke" stroke="#FFFFFF" stroke-width="3">%s</text>`+"\n",
+			mx, my-3, html.EscapeString(nombre))
+	}
+}
+
+// recortarTexto acorta un texto a max caracteres añadiendo puntos suspensivos.
+func recortarTexto(texto string, max int) string {
+	runas := []rune(texto)
+	if len(runas) <= max {
+		return texto
+	}
+	return string(runas[:max-1]) + "…"
+}
+
+// handleExportWorkflow exporta el workflow ?name= del snapshot en el formato ?format=dot|mermaid|svg.
+// Con ?download=1 se devuelve como fichero adjunto.
+func handleExportWorkflow(w http.ResponseWriter, r *http.Request) {
+	nombre := r.URL.Query().Get("name")
+	if nombre == "" {
+		http.Error(w, "Falta el parámetro 'name'", http.StatusBadRequest)
+		return
+	}
+
+	_, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	wf, ok := buscarWorkflow(workflows, nombre)
+	if !ok {
+		http.Error(w, "No se encontró el workflow "+nombre, http.StatusNotFound)
+		return
+	}
+	// Sin estados en el snapshot se exporta igual, pero sin colores de categoría
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	categorias := categoriasEstados(estados)
+
+	var contenido, contentType, extension string
+	switch r.URL.Query().Get("format") {
+	case "dot":
+		contenido, contentType, extension = exportarWorkflowDOT(wf, categorias), "text/vnd.graphviz; charset=utf-8", "dot"
+	case "mermaid":
+		contenido, contentType, extension = exportarWorkflowMermaid(wf, categorias), "text/plain; charset=utf-8", "mmd"
+	case "svg", "":
+		contenido, contentType, extension = exportarWorkflowSVG(wf, categorias), "image/svg+xml", "svg"
+	default:
+		http.Error(w, "Formato no soportado; usa dot, mermaid o svg", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if r.URL.Query().Get("download") == "1" {
+		nombreFichero := strings.NewReplacer("/", "_", `"`, "", " ", "_").Replace(nombre)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", nombreFichero+"."+extension))
+	}
+	w.Write([]byte(contenido))
+}
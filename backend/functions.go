@@ -6,10 +6,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 )
 
 const jsonFilePath = "/home/spektrus/Escritorio/AtlassianAyudas/assets/jsons/datos.json"
 
+// Carpeta donde se guardan los datos de cada dominio (historial de snapshots, planes, diario...)
+const jsonDirPath = "/home/spektrus/Escritorio/AtlassianAyudas/assets/jsons/"
+
 // readJSONFile lee el fichero JSON y devuelve su contenido como un mapa.
 func readJSONFile(filePath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(filePath)
@@ -32,15 +36,8 @@ func writeJSONFile(filePath string, data map[string]interface{}) error {
 	return os.WriteFile(filePath, jsonBytes, 0644)
 }
 
-// mergeMaps actualiza el mapa original (oldData) con los valores del mapa newData.
-func mergeMaps(oldData, newData map[string]interface{}) map[string]interface{} {
-	for key, newValue := range newData {
-		oldData[key] = newValue
-	}
-	return oldData
-}
-
-// Handler para ejecutar la API de Jira, fusionando los datos nuevos con los existentes en el fichero JSON.
+// Handler para ejecutar la API de Jira, guardando la ejecución como un snapshot nuevo del
+// historial y devolviendo la vista actual del dominio.
 func handleJiraExecution(w http.ResponseWriter, r *http.Request) {
 	var form RequestData
 
@@ -64,48 +61,105 @@ func handleJiraExecution(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ejecutar la consulta a Jira usando las credenciales de la conexión activa
-	resultados, err := ejecutarConsultaJira(conn.Domain, conn.Correo, conn.Token, form.Proyectos, form.Workflows, form.Estados)
+	resultados, err := ejecutarConsultaJira(conn.Domain, conn.Correo, conn.Token, form)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Generar el nombre del archivo JSON a partir del dominio activo
-	basePath := "/home/spektrus/Escritorio/AtlassianAyudas/assets/jsons/"
-	fileName := generateFileName(conn.Domain)
-	filePath := basePath + fileName
+	vista := guardarSnapshot(conn, resultados)
 
-	// Leer el JSON existente (si existe) en un mapa
-	existingData := make(map[string]interface{})
-	if data, err := os.ReadFile(filePath); err == nil {
-		if err := json.Unmarshal(data, &existingData); err != nil {
-			log.Println("Error al parsear JSON existente, se sobrescribirá:", err)
-		}
-	} else {
-		log.Println("No se encontró el fichero existente, se creará uno nuevo")
+	// Enviar la respuesta actualizada al cliente
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vista)
+}
+
+// guardarSnapshot guarda una ejecución como snapshot nuevo del historial y devuelve la vista actual
+// del dominio (la última versión de cada sección). Los errores de escritura solo se registran en
+// el log.
+func guardarSnapshot(conn Credentials, resultados map[string]interface{}) map[string]interface{} {
+	if _, err := guardarSnapshotHistorial(conn, origenDescarga, resultados); err != nil {
+		log.Println("Error al guardar el snapshot en el historial:", err)
+		return resultados
+	}
+	vista, err := componerSnapshot(conn.Domain)
+	if err != nil {
+		log.Println("Error al componer la vista actual del snapshot:", err)
+		return resultados
 	}
+	return vista
+}
 
-	// Fusionar los datos nuevos (obtenidos de Jira) con los existentes
-	updatedData := mergeMaps(existingData, resultados)
+// snapshotFilePath devuelve la ruta del snapshot antiguo de un dominio (<dominio>.json), de antes
+// de guardar el historial. Ya no se escribe; solo se lee para las secciones que no están en el
+// historial.
+func snapshotFilePath(domain string) string {
+	return jsonDirPath + generateFileName(domain)
+}
 
-	// Convertir el mapa actualizado a JSON formateado y escribirlo en el archivo correspondiente
-	dataToWrite, err := json.MarshalIndent(updatedData, "", "  ")
+// cargarSnapshotActual devuelve la vista actual del snapshot de la conexión activa.
+func cargarSnapshotActual() (Credentials, map[string]interface{}, error) {
+	conn, err := getCredentials()
 	if err != nil {
-		log.Println("Error al formatear JSON:", err)
-	} else {
-		if err := os.WriteFile(filePath, dataToWrite, 0644); err != nil {
-			log.Println("Error al guardar el fichero JSON:", err)
-		} else {
-			log.Println("Fichero JSON actualizado guardado en:", filePath)
-		}
+		return conn, nil, fmt.Errorf("no hay conexión activa: %w", err)
 	}
+	jsonData, err := componerSnapshot(conn.Domain)
+	if err != nil {
+		return conn, nil, err
+	}
+	return conn, jsonData, nil
+}
 
-	// Enviar la respuesta actualizada al cliente
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updatedData)
+// cargarSnapshotConexion lee el snapshot guardado para la conexión de la posición indicada.
+// Con un índice negativo se usa la conexión activa.
+func cargarSnapshotConexion(index int) (Credentials, map[string]interface{}, error) {
+	if index < 0 {
+		return cargarSnapshotActual()
+	}
+	conn, err := getCredentialsIndex(index)
+	if err != nil {
+		return conn, nil, err
+	}
+	jsonData, err := componerSnapshot(conn.Domain)
+	if err != nil {
+		return conn, nil, err
+	}
+	return conn, jsonData, nil
+}
+
+// indiceConexionParam lee un índice de conexión de la query string; si no viene devuelve -1
+// (conexión activa).
+func indiceConexionParam(r *http.Request, nombre string) (int, error) {
+	valor := r.URL.Query().Get(nombre)
+	if valor == "" {
+		return -1, nil
+	}
+	index, err := strconv.Atoi(valor)
+	if err != nil {
+		return 0, fmt.Errorf("índice de conexión inválido en %q: %w", nombre, err)
+	}
+	return index, nil
 }
 
-// handleGetJSONKey lee el fichero JSON y devuelve el valor asociado a la clave pasada como query parameter "key".
+// leerSeccion convierte una sección del snapshot (por ejemplo "estados") en su tipo Go.
+// Si la sección no existe, dest se deja intacto.
+func leerSeccion(snapshot map[string]interface{}, key string, dest interface{}) error {
+	value, ok := snapshot[key]
+	if !ok {
+		return nil
+	}
+	sectionBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error convirtiendo la sección %q: %w", key, err)
+	}
+	if err := json.Unmarshal(sectionBytes, dest); err != nil {
+		return fmt.Errorf("error parseando la sección %q: %w", key, err)
+	}
+	return nil
+}
+
+// handleGetJSONKey lee el snapshot de la conexión activa y devuelve el valor asociado a la clave
+// pasada como query parameter "key" (estados, proyectos, workflows, campos...).
 func handleGetJSONKey(w http.ResponseWriter, r *http.Request) {
 	// Obtener la clave a buscar desde la query string, por ejemplo: ?key=estados
 	key := r.URL.Query().Get("key")
@@ -114,22 +168,12 @@ func handleGetJSONKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Ruta absoluta del fichero JSON
-	filePath := "/home/spektrus/Escritorio/AtlassianAyudas/assets/jsons/credenciales.json"
-
-	data, err := os.ReadFile(filePath)
+	_, jsonData, err := cargarSnapshotActual()
 	if err != nil {
 		http.Error(w, "Error leyendo fichero JSON: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Deserializamos el contenido en un mapa para extraer la clave solicitada
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(data, &jsonData); err != nil {
-		http.Error(w, "Error parseando JSON: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
 	// Buscar la clave especificada
 	value, ok := jsonData[key]
 	if !ok {
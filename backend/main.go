@@ -42,6 +42,134 @@ func handleStates(w http.ResponseWriter, r *http.Request) {
 	}
 	renderTemplate(w, "states", data)
 }
+
+func handleUnusedStatesPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Unused States",
+		"ActivePage": "Unused States",
+	}
+	renderTemplate(w, "unused_states", data)
+}
+
+func handleDuplicateStatesPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Duplicate States",
+		"ActivePage": "Duplicate States",
+	}
+	renderTemplate(w, "duplicate_states", data)
+}
+
+func handleWorkflowLintPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Workflow Lint",
+		"ActivePage": "Workflow Lint",
+	}
+	renderTemplate(w, "workflow_lint", data)
+}
+
+func handleWorkflowDiffPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Workflow Diff",
+		"ActivePage": "Workflow Diff",
+	}
+	renderTemplate(w, "workflow_diff", data)
+}
+
+func handleComparePage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Compare Sites",
+		"ActivePage": "Compare Sites",
+	}
+	renderTemplate(w, "compare", data)
+}
+
+func handleSnapshotsPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Snapshots",
+		"ActivePage": "Snapshots",
+	}
+	renderTemplate(w, "snapshots", data)
+}
+
+func handleWorkflowDiagramPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Workflow Diagram",
+		"ActivePage": "Workflow Diagram",
+	}
+	renderTemplate(w, "workflow_diagram", data)
+}
+
+func handleWorkflowClustersPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Workflow Clusters",
+		"ActivePage": "Workflow Clusters",
+	}
+	renderTemplate(w, "workflow_clusters", data)
+}
+
+func handleCategoryConsistencyPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Category Consistency",
+		"ActivePage": "Category Consistency",
+	}
+	renderTemplate(w, "category_consistency", data)
+}
+
+func handleProjectsReportPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Projects Report",
+		"ActivePage": "Projects Report",
+	}
+	renderTemplate(w, "projects_report", data)
+}
+
+func handleBulkStatusesPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Bulk Statuses",
+		"ActivePage": "Bulk Statuses",
+	}
+	renderTemplate(w, "bulk_statuses", data)
+}
+
+func handleWorkflowFilePage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Workflow File",
+		"ActivePage": "Workflow File",
+	}
+	renderTemplate(w, "workflow_file", data)
+}
+
+func handlePlanPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Plan / Apply",
+		"ActivePage": "Plan",
+	}
+	renderTemplate(w, "plan", data)
+}
+
+func handleJournalPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Journal",
+		"ActivePage": "Journal",
+	}
+	renderTemplate(w, "journal", data)
+}
+
+func handleMigrateWorkflowsPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Copy Workflows",
+		"ActivePage": "Copy Workflows",
+	}
+	renderTemplate(w, "migrate_workflows", data)
+}
+
+func handlePoliciesPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"Title":      "Policies",
+		"ActivePage": "Policies",
+	}
+	renderTemplate(w, "policies", data)
+}
 func renderTemplate(w http.ResponseWriter, tmpl string, data interface{}) {
 	tmplPath := fmt.Sprintf("../pages/%s.html", tmpl)
 	log.Println("Cargando plantilla:", tmplPath)
@@ -84,6 +212,22 @@ func runServer() {
 	router.HandleFunc("/connection_settings", handleConecctionSettings).Methods("GET")
 	router.HandleFunc("/data", handleData).Methods("GET")
 	router.HandleFunc("/states", handleStates).Methods("GET")
+	router.HandleFunc("/unused_states", handleUnusedStatesPage).Methods("GET")
+	router.HandleFunc("/duplicate_states", handleDuplicateStatesPage).Methods("GET")
+	router.HandleFunc("/workflow_lint", handleWorkflowLintPage).Methods("GET")
+	router.HandleFunc("/workflow_diff", handleWorkflowDiffPage).Methods("GET")
+	router.HandleFunc("/compare", handleComparePage).Methods("GET")
+	router.HandleFunc("/snapshots", handleSnapshotsPage).Methods("GET")
+	router.HandleFunc("/workflow_diagram", handleWorkflowDiagramPage).Methods("GET")
+	router.HandleFunc("/workflow_clusters", handleWorkflowClustersPage).Methods("GET")
+	router.HandleFunc("/policies", handlePoliciesPage).Methods("GET")
+	router.HandleFunc("/projects_report", handleProjectsReportPage).Methods("GET")
+	router.HandleFunc("/bulk_statuses", handleBulkStatusesPage).Methods("GET")
+	router.HandleFunc("/workflow_file", handleWorkflowFilePage).Methods("GET")
+	router.HandleFunc("/plan", handlePlanPage).Methods("GET")
+	router.HandleFunc("/journal", handleJournalPage).Methods("GET")
+	router.HandleFunc("/migrate_workflows", handleMigrateWorkflowsPage).Methods("GET")
+	router.HandleFunc("/category_consistency", handleCategoryConsistencyPage).Methods("GET")
 	// API POINTS
 	router.HandleFunc("/connection_status", handleConnectionStatus).Methods("GET")
 	router.HandleFunc("/setcurrent", handleSetCurrentConnection).Methods("GET")
@@ -91,8 +235,33 @@ func runServer() {
 	router.HandleFunc("/getjson", handleGetJSONKey).Methods("GET")
 	router.HandleFunc("/getconnections", handleGetConnections).Methods("GET")
 	router.HandleFunc("/deleteconnection", handleDeleteConnection).Methods("GET")
+	router.HandleFunc("/getunusedstates", handleUnusedStates).Methods("GET")
+	router.HandleFunc("/getduplicatestates", handleDuplicateStates).Methods("GET")
+	router.HandleFunc("/getworkflowlint", handleWorkflowLint).Methods("GET")
+	router.HandleFunc("/getworkflownames", handleWorkflowNames).Methods("GET")
+	router.HandleFunc("/getworkflowdiff", handleWorkflowDiff).Methods("GET")
+	router.HandleFunc("/getcompare", handleCompareConnections).Methods("GET")
+	router.HandleFunc("/getsnapshots", handleListSnapshots).Methods("GET")
+	router.HandleFunc("/getsnapshot", handleGetSnapshot).Methods("GET")
+	router.HandleFunc("/getsnapshotdiff", handleSnapshotDiff).Methods("GET")
+	router.HandleFunc("/exportworkflow", handleExportWorkflow).Methods("GET")
+	router.HandleFunc("/getworkflowclusters", handleWorkflowClusters).Methods("GET")
+	router.HandleFunc("/getpolicies", handlePolicyLint).Methods("GET")
+	router.HandleFunc("/getcategoryconsistency", handleCategoryConsistency).Methods("GET")
+	router.HandleFunc("/getprojectreport", handleProjectReport).Methods("GET")
+	router.HandleFunc("/getplans", handleListPlans).Methods("GET")
+	router.HandleFunc("/getjournal", handleJournal).Methods("GET")
 	// Ruta POST para ejecutar la consulta a Jira
 	router.HandleFunc("/execute", handleJiraExecution).Methods("POST")
+	router.HandleFunc("/bulkcreatestatuses", handleBulkCreateStatuses).Methods("POST")
+	router.HandleFunc("/bulkeditstatuses", handleBulkEditStatuses).Methods("POST")
+	router.HandleFunc("/verifydeletestatuses", handleVerifyDeleteStatuses).Methods("POST")
+	router.HandleFunc("/deletestatuses", handleDeleteStatuses).Methods("POST")
+	router.HandleFunc("/workflowfile", handleWorkflowFile).Methods("POST")
+	router.HandleFunc("/createplan", handlePlan).Methods("POST")
+	router.HandleFunc("/applyplan", handleApplyPlan).Methods("POST")
+	router.HandleFunc("/revertjournal", handleRevertJournal).Methods("POST")
+	router.HandleFunc("/migrateworkflows", handleMigrateWorkflows).Methods("POST")
 	// Servir archivos estáticos
 	router.PathPrefix("/assets/").Handler(http.StripPrefix("/assets/", http.FileServer(http.Dir("../assets/"))))
 
@@ -114,11 +283,12 @@ func runServer() {
 		port = "8080"
 	}
 
+	// WriteTimeout amplio: /execute hace varias peticiones por campo y puede tardar minutos
 	server := &http.Server{
 		Addr:         ":" + port,
 		Handler:      loggedRouter,
 		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		WriteTimeout: 10 * time.Minute,
 		IdleTimeout:  60 * time.Second,
 	}
 
@@ -167,8 +337,20 @@ func main() {
 			} else {
 				runServer()
 			}
+		case "lint":
+			// Evalúa las reglas sobre el snapshot sin arrancar el servidor
+			os.Exit(lintPoliticasCLI(os.Args[2:]))
+		case "workflow":
+			// Valida y, con --apply, crea o actualiza un workflow declarado en un fichero
+			os.Exit(workflowDeclaradoCLI(os.Args[2:]))
+		case "plan":
+			// Compara el estado deseado con el sitio y guarda el plan
+			os.Exit(planCLI(os.Args[2:]))
+		case "apply":
+			// Aplica un plan guardado tras aprobarlo
+			os.Exit(applyCLI(os.Args[2:]))
 		default:
-			fmt.Println("Uso: app [start|stop|toggle]")
+			fmt.Println("Uso: app [start|stop|toggle|lint [reglas.yaml]|workflow <fichero> [--apply]|plan <fichero>|apply <id-plan> [--approve]]")
 		}
 	} else {
 		// Si no se pasan argumentos, arranca el servidor por defecto.
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ----------------------------------------------------------------
+// Copia de workflows entre conexiones: los estados del origen se asignan a estados del destino
+// por nombre (o a mano), se crean los que faltan y después se crean los workflows en el destino
+// ----------------------------------------------------------------
+
+// Secciones que se descargan en vivo del destino antes de copiar
+var opcionesMigracion = RequestData{Estados: true, Workflows: true}
+
+// avisosMigracion señala las reglas que pueden no funcionar en el destino porque su
+// configuración se refiere a IDs propios del sitio de origen. Las pantallas de transición ya las
+// avisa validarWorkflowDeclarado.
+func avisosMigracion(wf JiraWorkflow) []string {
+	avisos := []string{}
+	for _, t := range wf.Transitions {
+		if reglasConConfiguracion(t.Rules) {
+			avisos = append(avisos, fmt.Sprintf("La transición %q tiene reglas con configuración; revisa que los IDs que usen existan en destino", t.Name))
+		}
+	}
+	return avisos
+}
+
+// reglasConConfiguracion indica si alguna condición, validador o post-función lleva parámetros.
+func reglasConConfiguracion(reglas *TransitionRules) bool {
+	if reglas == nil {
+		return false
+	}
+	for _, lista := range [][]WorkflowRule{reglas.Conditions, reglas.Validators, reglas.PostFunctions} {
+		for _, r := range lista {
+			if len(r.Configuration) > 0 {
+				return true
+			}
+		}
+	}
+	return reglas.ConditionsTree != nil && condicionConConfiguracion(*reglas.ConditionsTree)
+}
+
+func condicionConConfiguracion(nodo WorkflowCondition) bool {
+	if len(nodo.Configuration) > 0 {
+		return true
+	}
+	for _, hijo := range nodo.Conditions {
+		if condicionConConfiguracion(hijo) {
+			return true
+		}
+	}
+	return false
+}
+
+// mapearEstadosMigracion decide qué estado del destino corresponde a cada estado usado por los
+// workflows a copiar: el asignado a mano en overrides, el global con el mismo nombre o, si no
+// hay ninguno, uno nuevo con el nombre y la categoría del origen.
+func mapearEstadosMigracion(workflows []JiraWorkflow, estadosOrigen, estadosDestino []JiraStatus, overrides map[string]string) []MigrationStatus {
+	origenPorID := make(map[string]JiraStatus, len(estadosOrigen))
+	for _, st := range estadosOrigen {
+		origenPorID[st.ID] = st
+	}
+	destinoPorNombre, colisiones := estadosGlobalesPorNombre(estadosDestino)
+	destinoPorID := make(map[string]JiraStatus, len(estadosDestino))
+	for _, st := range estadosDestino {
+		if ambitoEstado(st) == "GLOBAL" {
+			destinoPorID[st.ID] = st
+		}
+	}
+	asignados := make(map[string]string, len(overrides))
+	for origen, destino := range overrides {
+		if strings.TrimSpace(destino) != "" {
+			asignados[strings.ToLower(strings.TrimSpace(origen))] = strings.TrimSpace(destino)
+		}
+	}
+
+	estados := []MigrationStatus{}
+	posicion := make(map[string]int)
+	for _, wf := range workflows {
+		for _, ws := range wf.Statuses {
+			if i, ok := posicion[ws.ID]; ok {
+				estados[i].Workflows = append(estados[i].Workflows, wf.ID.Name)
+				continue
+			}
+			m := MigrationStatus{SourceID: ws.ID, SourceName: ws.Name, Workflows: []string{wf.ID.Name}, Result: "pending"}
+			if st, ok := origenPorID[ws.ID]; ok {
+				m.SourceName = st.Name
+				m.StatusCategory = st.StatusCategory
+			}
+			clave := strings.ToLower(strings.TrimSpace(m.SourceName))
+
+			if asignado, ok := asignados[clave]; ok {
+				destino, ok := destinoPorID[asignado]
+				if !ok {
+					destino, ok = destinoPorNombre[strings.ToLower(asignado)]
+				}
+				if repetidos, ambiguo := colisiones[strings.ToLower(asignado)]; !ok && ambiguo {
+					m.Action = "error"
+					m.Result = "error"
+					m.Message = fmt.Sprintf("Hay varios estados globales %q en destino: %s; asígnalo por ID", asignado, describirColision(repetidos))
+				} else if ok {
+					m.Action = "override"
+					m.TargetID, m.TargetName = destino.ID, destino.Name
+				} else {
+					m.Action = "error"
+					m.Result = "error"
+					m.Message = fmt.Sprintf("El estado asignado %q no existe como estado global en destino", asignado)
+				}
+			} else if destino, ok := destinoPorNombre[clave]; ok {
+				m.Action = "map"
+				m.TargetID, m.TargetName = destino.ID, destino.Name
+			} else if repetidos, ok := colisiones[clave]; ok {
+				m.Action = "error"
+				m.Result = "error"
+				m.Message = fmt.Sprintf("Hay varios estados globales con ese nombre en destino: %s; asígnale uno por ID", describirColision(repetidos))
+			} else if categoria, ok := normalizarCategoria(m.StatusCategory); ok {
+				m.Action = "create"
+				m.StatusCategory = categoria
+				m.TargetName = strings.TrimSpace(m.SourceName)
+			} else {
+				m.Action = "error"
+				m.Result = "error"
+				m.Message = "No existe en destino y el snapshot de origen no tiene su categoría; asígnale un estado del destino"
+			}
+
+			posicion[ws.ID] = len(estados)
+			estados = append(estados, m)
+		}
+	}
+	return estados
+}
+
+// destinosMigracion devuelve, por ID de estado del origen, la referencia del estado en el destino:
+// su ID si ya existe o "nuevo:<nombre>" si está pendiente de crear. Los estados con error no se
+// incluyen.
+func destinosMigracion(estados []MigrationStatus) map[string]string {
+	destinoDe := make(map[string]string, len(estados))
+	for _, m := range estados {
+		switch {
+		case m.Result == "error":
+		case m.TargetID != "":
+			destinoDe[m.SourceID] = m.TargetID
+		case m.Action == "create":
+			destinoDe[m.SourceID] = "nuevo:" + m.TargetName
+		}
+	}
+	return destinoDe
+}
+
+// estadosSinDestino lista los estados del workflow que no tienen equivalente en el destino.
+func estadosSinDestino(wf JiraWorkflow, destinoDe map[string]string) []string {
+	var faltan []string
+	for _, ws := range wf.Statuses {
+		if _, ok := destinoDe[ws.ID]; !ok {
+			faltan = append(faltan, referenciaEstado(ws))
+		}
+	}
+	return faltan
+}
+
+// convertirWorkflowMigracion sustituye en estados y transiciones los IDs del origen por sus
+// equivalentes en el destino.
+func convertirWorkflowMigracion(wf JiraWorkflow, destinoDe map[string]string) JiraWorkflow {
+	copia := JiraWorkflow{ID: wf.ID, Description: wf.Description}
+	for _, ws := range wf.Statuses {
+		copia.Statuses = append(copia.Statuses, WorkflowStatus{ID: destinoDe[ws.ID], Properties: ws.Properties})
+	}
+	for _, t := range wf.Transitions {
+		nueva := t
+		nueva.To = destinoDe[t.To]
+		nueva.From = nil
+		for _, from := range t.From {
+			nueva.From = append(nueva.From, destinoDe[from])
+		}
+		copia.Transitions = append(copia.Transitions, nueva)
+	}
+	return copia
+}
+
+// migrarWorkflows prepara la copia de los workflows elegidos del snapshot del origen a la
+// configuración en vivo del destino y, con Apply, crea en el destino los estados que faltan y
+// después los workflows. Las escrituras quedan en el diario del destino.
+func migrarWorkflows(peticion MigrationRequest) (MigrationResult, error) {
+	resultado := MigrationResult{Statuses: []MigrationStatus{}, Workflows: []MigrationWorkflow{}}
+	if len(peticion.Workflows) == 0 {
+		return resultado, fmt.Errorf("no se ha elegido ningún workflow")
+	}
+	if peticion.Source == peticion.Target {
+		return resultado, fmt.Errorf("el origen y el destino deben ser conexiones distintas")
+	}
+
+	origen, snapshot, err := cargarSnapshotConexion(peticion.Source)
+	if err != nil {
+		return resultado, err
+	}
+	var estadosOrigen []JiraStatus
+	var workflowsOrigen []JiraWorkflow
+	if err := leerSeccion(snapshot, "estados", &estadosOrigen); err != nil {
+		return resultado, err
+	}
+	if err := leerSeccion(snapshot, "workflows", &workflowsOrigen); err != nil {
+		return resultado, err
+	}
+
+	destino, err := getCredentialsIndex(peticion.Target)
+	if err != nil {
+		return resultado, err
+	}
+	resultado.SourceDomain = origen.Domain
+	resultado.TargetDomain = destino.Domain
+	datos, err := ejecutarConsultaJira(destino.Domain, destino.Correo, destino.Token, opcionesMigracion)
+	if err != nil {
+		return resultado, fmt.Errorf("error descargando la configuración de %s: %w", destino.Domain, err)
+	}
+	var estadosDestino []JiraStatus
+	var workflowsDestino []JiraWorkflow
+	if err := leerSeccion(datos, "estados", &estadosDestino); err != nil {
+		return resultado, err
+	}
+	if err := leerSeccion(datos, "workflows", &workflowsDestino); err != nil {
+		return resultado, err
+	}
+
+	// Solo se copian workflows que existan en el origen y no en el destino
+	var elegidos []JiraWorkflow
+	vistos := make(map[string]bool)
+	for _, nombre := range peticion.Workflows {
+		if vistos[nombre] {
+			continue
+		}
+		vistos[nombre] = true
+		linea := MigrationWorkflow{Name: nombre, Action: "create", Errors: []string{}, Warnings: []string{}, Result: "pending"}
+		if wf, ok := buscarWorkflow(workflowsOrigen, nombre); !ok {
+			linea.Errors = append(linea.Errors, fmt.Sprintf("No existe en el snapshot de %s", origen.Domain))
+		} else if _, existe := buscarWorkflow(workflowsDestino, nombre); existe {
+			linea.Errors = append(linea.Errors, fmt.Sprintf("Ya existe un workflow con ese nombre en %s", destino.Domain))
+		} else {
+			elegidos = append(elegidos, wf)
+			linea.Warnings = append(linea.Warnings, avisosMigracion(wf)...)
+		}
+		resultado.Workflows = append(resultado.Workflows, linea)
+	}
+
+	resultado.Statuses = mapearEstadosMigracion(elegidos, estadosOrigen, estadosDestino, peticion.Overrides)
+	destinoDe := destinosMigracion(resultado.Statuses)
+	disponibles := append([]JiraStatus{}, estadosDestino...)
+	for _, m := range resultado.Statuses {
+		if destinoDe[m.SourceID] == "nuevo:"+m.TargetName {
+			disponibles = append(disponibles, JiraStatus{ID: "nuevo:" + m.TargetName, Name: m.TargetName, StatusCategory: m.StatusCategory})
+		}
+	}
+
+	// Cada workflow se valida ya convertido, con los estados nuevos como si existieran
+	aCopiar := make(map[string]JiraWorkflow)
+	necesarios := make(map[string]bool)
+	for _, wf := range elegidos {
+		linea := lineaMigracion(&resultado, wf.ID.Name)
+		if faltan := estadosSinDestino(wf, destinoDe); len(faltan) > 0 {
+			linea.Errors = append(linea.Errors, "Estados sin equivalente en destino: "+strings.Join(faltan, ", "))
+		} else {
+			_, errores, avisos := validarWorkflowDeclarado(convertirWorkflowMigracion(wf, destinoDe), disponibles)
+			linea.Errors = append(linea.Errors, errores...)
+			linea.Warnings = append(linea.Warnings, avisos...)
+		}
+		if len(linea.Errors) > 0 {
+			continue
+		}
+		aCopiar[wf.ID.Name] = wf
+		for _, ws := range wf.Statuses {
+			necesarios[ws.ID] = true
+		}
+	}
+	for i := range resultado.Workflows {
+		if len(resultado.Workflows[i].Errors) > 0 {
+			resultado.Workflows[i].Action = "skip"
+			resultado.Workflows[i].Result = "skipped"
+		}
+	}
+	for i := range resultado.Statuses {
+		m := &resultado.Statuses[i]
+		if m.Result == "pending" && !necesarios[m.SourceID] {
+			m.Result = "skipped"
+			m.Message = "Ningún workflow a copiar lo usa"
+		}
+	}
+
+	if !peticion.Apply || len(aCopiar) == 0 {
+		return resultado, nil
+	}
+
+	client := conectarAJira(destino.Domain, destino.Correo, destino.Token)
+	ctx := abrirLoteDiario(client)
+	defer cerrarLoteDiario(client)
+	creados, errCrear := crearEstadosMigracion(client, &resultado, estadosOrigen)
+	estadosDestino = append(estadosDestino, creados...)
+
+	destinoDe = destinosMigracion(resultado.Statuses)
+	copiados := 0
+	for i := range resultado.Workflows {
+		linea := &resultado.Workflows[i]
+		wf, ok := aCopiar[linea.Name]
+		if !ok {
+			continue
+		}
+		if faltan := estadosSinDestino(wf, destinoDe); len(faltan) > 0 {
+			linea.Result = "error"
+			linea.Message = "No se pudieron crear los estados: " + strings.Join(faltan, ", ")
+			if errCrear != nil {
+				linea.Message += " (" + errCrear.Error() + ")"
+			}
+			continue
+		}
+		resuelto, errores, _ := validarWorkflowDeclarado(convertirWorkflowMigracion(wf, destinoDe), estadosDestino)
+		if len(errores) > 0 {
+			linea.Result = "error"
+			linea.Message = strings.Join(errores, "; ")
+			continue
+		}
+		if err := crearWorkflowJira(client, cuerpoEscrituraWorkflow(resuelto, estadosDestino, nil)); err != nil {
+			linea.Result = "error"
+			linea.Message = err.Error()
+			continue
+		}
+		linea.Result = "created"
+		copiados++
+	}
+
+	resultado.Applied = true
+	if len(creados) > 0 || copiados > 0 {
+		resultado.Batch = ctx.lote
+		// El snapshot del destino se refresca con lo que ha quedado tras la copia
+		if datos, err := ejecutarConsultaJira(destino.Domain, destino.Correo, destino.Token, opcionesMigracion); err != nil {
+			log.Println("Error al refrescar el snapshot del destino:", err)
+		} else {
+			guardarSnapshot(destino, datos)
+		}
+	}
+	return resultado, nil
+}
+
+// lineaMigracion devuelve la línea del resultado de un workflow por nombre.
+func lineaMigracion(resultado *MigrationResult, nombre string) *MigrationWorkflow {
+	for i := range resultado.Workflows {
+		if resultado.Workflows[i].Name == nombre {
+			return &resultado.Workflows[i]
+		}
+	}
+	return nil
+}
+
+// crearEstadosMigracion crea en el destino los estados pendientes (uno por nombre) con la
+// descripción del origen y anota en cada correspondencia el ID creado o el error.
+func crearEstadosMigracion(client *resty.Client, resultado *MigrationResult, estadosOrigen []JiraStatus) ([]JiraStatus, error) {
+	descripciones := make(map[string]string, len(estadosOrigen))
+	for _, st := range estadosOrigen {
+		descripciones[st.ID] = st.Description
+	}
+
+	var nuevos []JiraStatus
+	pedidos := make(map[string]bool)
+	for _, m := range resultado.Statuses {
+		clave := strings.ToLower(m.TargetName)
+		if m.Action != "create" || m.Result != "pending" || pedidos[clave] {
+			continue
+		}
+		pedidos[clave] = true
+		nuevos = append(nuevos, JiraStatus{Name: m.TargetName, Description: descripciones[m.SourceID], StatusCategory: m.StatusCategory})
+	}
+	creados, errCrear := crearEstadosJira(client, nuevos)
+
+	porNombre := make(map[string]JiraStatus, len(creados))
+	for _, st := range creados {
+		porNombre[strings.ToLower(st.Name)] = st
+	}
+	for i := range resultado.Statuses {
+		m := &resultado.Statuses[i]
+		if m.Result != "pending" {
+			continue
+		}
+		if m.Action != "create" {
+			m.Result = "mapped"
+			continue
+		}
+		if st, ok := porNombre[strings.ToLower(m.TargetName)]; ok {
+			m.TargetID = st.ID
+			m.Result = "created"
+			continue
+		}
+		m.Result = "error"
+		m.Message = "No se creó en destino"
+		if errCrear != nil {
+			m.Message = errCrear.Error()
+		}
+	}
+	return creados, errCrear
+}
+
+// handleMigrateWorkflows previsualiza o, con "apply", ejecuta la copia de workflows entre conexiones.
+func handleMigrateWorkflows(w http.ResponseWriter, r *http.Request) {
+	var peticion MigrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := migrarWorkflows(peticion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapearEstadosMigracion(t *testing.T) {
+	origen := []JiraStatus{
+		{ID: "10", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "11", Name: "In Progress", StatusCategory: "IN_PROGRESS"},
+		{ID: "12", Name: "Done", StatusCategory: "DONE"},
+		{ID: "13", Name: "QA", StatusCategory: "in progress"},
+	}
+	destino := []JiraStatus{
+		{ID: "1", Name: "to do", StatusCategory: "TODO"},
+		{ID: "2", Name: "Doing", StatusCategory: "IN_PROGRESS"},
+		{ID: "3", Name: "Done", StatusCategory: "DONE"},
+		{ID: "4", Name: "QA", StatusCategory: "IN_PROGRESS", Scope: &JiraScope{Type: "PROJECT", Project: &JiraScopeProject{ID: "100"}}},
+	}
+	workflow := func(nombre string, estados ...WorkflowStatus) JiraWorkflow {
+		return JiraWorkflow{ID: WorkflowID{Name: nombre}, Statuses: estados}
+	}
+	porHacer := WorkflowStatus{ID: "10", Name: "To Do"}
+	enCurso := WorkflowStatus{ID: "11", Name: "In Progress"}
+	hecho := WorkflowStatus{ID: "12", Name: "Done"}
+
+	// esperado resume cada correspondencia como "acción destino" por nombre de origen
+	casos := []struct {
+		nombre    string
+		workflows []JiraWorkflow
+		destino   []JiraStatus
+		overrides map[string]string
+		esperado  map[string]string
+		mensajes  map[string]string
+	}{
+		{
+			nombre:    "mismo nombre sin distinguir mayúsculas y creación con la categoría del origen",
+			workflows: []JiraWorkflow{workflow("A", porHacer, enCurso, hecho)},
+			esperado:  map[string]string{"To Do": "map 1", "In Progress": "create ", "Done": "map 3"},
+		},
+		{
+			nombre:    "asignación manual por ID y por nombre",
+			workflows: []JiraWorkflow{workflow("A", porHacer, enCurso, hecho)},
+			overrides: map[string]string{"in progress": "Doing", "Done": "1", "To Do": " "},
+			esperado:  map[string]string{"To Do": "map 1", "In Progress": "override 2", "Done": "override 1"},
+		},
+		{
+			nombre:    "asignación a un estado que no existe",
+			workflows: []JiraWorkflow{workflow("A", enCurso)},
+			overrides: map[string]string{"In Progress": "Working"},
+			esperado:  map[string]string{"In Progress": "error "},
+			mensajes:  map[string]string{"In Progress": "no existe como estado global"},
+		},
+		{
+			nombre:    "los estados de proyecto del destino no cuentan",
+			workflows: []JiraWorkflow{workflow("A", WorkflowStatus{ID: "13", Name: "QA"})},
+			esperado:  map[string]string{"QA": "create "},
+		},
+		{
+			nombre:    "estado sin categoría en el origen",
+			workflows: []JiraWorkflow{workflow("A", WorkflowStatus{ID: "99", Name: "Perdido"})},
+			esperado:  map[string]string{"Perdido": "error "},
+			mensajes:  map[string]string{"Perdido": "no tiene su categoría"},
+		},
+		{
+			nombre:    "varios estados globales del destino con el mismo nombre",
+			workflows: []JiraWorkflow{workflow("A", hecho)},
+			destino:   append([]JiraStatus{{ID: "5", Name: "DONE", StatusCategory: "DONE"}}, destino...),
+			esperado:  map[string]string{"Done": "error "},
+			mensajes:  map[string]string{"Done": "DONE (5), Done (3)"},
+		},
+		{
+			nombre:    "nombre repetido en destino asignado por nombre",
+			workflows: []JiraWorkflow{workflow("A", hecho)},
+			destino:   append([]JiraStatus{{ID: "5", Name: "DONE", StatusCategory: "DONE"}}, destino...),
+			overrides: map[string]string{"Done": "done"},
+			esperado:  map[string]string{"Done": "error "},
+			mensajes:  map[string]string{"Done": "asígnalo por ID"},
+		},
+		{
+			nombre:    "nombre repetido en destino asignado por ID",
+			workflows: []JiraWorkflow{workflow("A", hecho)},
+			destino:   append([]JiraStatus{{ID: "5", Name: "DONE", StatusCategory: "DONE"}}, destino...),
+			overrides: map[string]string{"Done": "5"},
+			esperado:  map[string]string{"Done": "override 5"},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			destinoCaso := caso.destino
+			if destinoCaso == nil {
+				destinoCaso = destino
+			}
+			estados := mapearEstadosMigracion(caso.workflows, origen, destinoCaso, caso.overrides)
+			obtenido := make(map[string]string, len(estados))
+			for _, m := range estados {
+				obtenido[m.SourceName] = m.Action + " " + m.TargetID
+				if m.Action == "create" && m.StatusCategory == "" {
+					t.Errorf("%s se crearía sin categoría", m.SourceName)
+				}
+				if m.Action == "error" && m.Result != "error" {
+					t.Errorf("%s tiene acción error y resultado %q", m.SourceName, m.Result)
+				}
+				if fragmento, ok := caso.mensajes[m.SourceName]; ok && !strings.Contains(m.Message, fragmento) {
+					t.Errorf("mensaje de %s = %q, se esperaba que contuviera %q", m.SourceName, m.Message, fragmento)
+				}
+			}
+			if !reflect.DeepEqual(obtenido, caso.esperado) {
+				t.Errorf("correspondencias = %v, se esperaba %v", obtenido, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestMapearEstadosMigracionCompartidos(t *testing.T) {
+	origen := []JiraStatus{{ID: "10", Name: "To Do", StatusCategory: "TODO"}, {ID: "12", Name: "Done", StatusCategory: "DONE"}}
+	workflows := []JiraWorkflow{
+		{ID: WorkflowID{Name: "A"}, Statuses: []WorkflowStatus{{ID: "10", Name: "To Do"}, {ID: "12", Name: "Done"}}},
+		{ID: WorkflowID{Name: "B"}, Statuses: []WorkflowStatus{{ID: "12", Name: "Done"}}},
+	}
+	estados := mapearEstadosMigracion(workflows, origen, nil, nil)
+	if len(estados) != 2 {
+		t.Fatalf("se esperaban 2 estados, hay %d", len(estados))
+	}
+	if got := estados[1].Workflows; !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("workflows de Done = %v, se esperaba [A B]", got)
+	}
+	if estados[1].StatusCategory != "DONE" || estados[1].TargetName != "Done" {
+		t.Errorf("Done se crearía como %q en %s", estados[1].TargetName, estados[1].StatusCategory)
+	}
+}
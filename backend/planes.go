@@ -0,0 +1,820 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ----------------------------------------------------------------
+// Plan/apply: se compara un fichero de estado deseado (estados, categorías de proyecto y
+// workflows) con el sitio en vivo, se guarda el plan y solo se aplica tras aprobarlo y
+// comprobar que el sitio no ha cambiado desde que se generó
+// ----------------------------------------------------------------
+
+// Secciones que se descargan para planificar y para comprobar la deriva al aplicar
+var opcionesPlan = RequestData{Estados: true, Workflows: true, CategoriasProyecto: true}
+
+// planesDirPath devuelve la carpeta donde se guardan los planes de un dominio.
+func planesDirPath(domain string) string {
+	return filepath.Join(jsonDirPath, "planes", strings.TrimSuffix(generateFileName(domain), ".json"))
+}
+
+// configuracionViva descarga de Jira las secciones que gestiona el plan.
+func configuracionViva(conn Credentials) ([]JiraStatus, []ProjectCategory, []JiraWorkflow, error) {
+	datos, err := ejecutarConsultaJira(conn.Domain, conn.Correo, conn.Token, opcionesPlan)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var estados []JiraStatus
+	var categorias []ProjectCategory
+	var workflows []JiraWorkflow
+	if err := leerSeccion(datos, "estados", &estados); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := leerSeccion(datos, "categoriasProyecto", &categorias); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := leerSeccion(datos, "workflows", &workflows); err != nil {
+		return nil, nil, nil, err
+	}
+	return estados, categorias, workflows, nil
+}
+
+// huellaConfiguracion calcula un hash estable de la configuración gestionada. De los estados solo
+// se tienen en cuenta los datos editables, no sus usos, que cambian con el día a día del sitio.
+func huellaConfiguracion(estados []JiraStatus, categorias []ProjectCategory, workflows []JiraWorkflow) string {
+	editables := make([]JiraStatusUpdate, 0, len(estados))
+	for _, st := range estados {
+		editables = append(editables, JiraStatusUpdate{ID: st.ID, Name: st.Name, Description: st.Description, StatusCategory: st.StatusCategory})
+	}
+	sort.Slice(editables, func(i, j int) bool { return editables[i].ID < editables[j].ID })
+
+	cats := append([]ProjectCategory{}, categorias...)
+	sort.Slice(cats, func(i, j int) bool { return cats[i].ID < cats[j].ID })
+
+	wfs := append([]JiraWorkflow{}, workflows...)
+	sort.Slice(wfs, func(i, j int) bool { return wfs[i].ID.Name < wfs[j].ID.Name })
+
+	jsonBytes, _ := json.Marshal(struct {
+		Estados    []JiraStatusUpdate
+		Categorias []ProjectCategory
+		Workflows  []JiraWorkflow
+	}{editables, cats, wfs})
+	suma := sha256.Sum256(jsonBytes)
+	return hex.EncodeToString(suma[:])
+}
+
+// estadosGlobalesPorNombre indexa los estados globales por nombre en minúsculas. Los nombres que
+// comparten varios estados globales (solo cambian mayúsculas) no se indexan: se devuelven aparte
+// para que quien llama lo trate como error en lugar de quedarse con uno cualquiera.
+func estadosGlobalesPorNombre(estados []JiraStatus) (map[string]JiraStatus, map[string][]JiraStatus) {
+	porNombre := make(map[string]JiraStatus)
+	colisiones := make(map[string][]JiraStatus)
+	for _, st := range estados {
+		if ambitoEstado(st) != "GLOBAL" {
+			continue
+		}
+		clave := strings.ToLower(st.Name)
+		if anterior, ok := porNombre[clave]; ok {
+			colisiones[clave] = append(colisiones[clave], anterior)
+			delete(porNombre, clave)
+		}
+		if _, ok := colisiones[clave]; ok {
+			colisiones[clave] = append(colisiones[clave], st)
+			continue
+		}
+		porNombre[clave] = st
+	}
+	return porNombre, colisiones
+}
+
+// describirColision lista los estados que comparten nombre, con su ID, para los mensajes de error.
+func describirColision(estados []JiraStatus) string {
+	partes := make([]string, len(estados))
+	for i, st := range estados {
+		partes[i] = fmt.Sprintf("%s (%s)", st.Name, st.ID)
+	}
+	return strings.Join(partes, ", ")
+}
+
+// planificarEstados compara los estados deseados con los globales del sitio.
+func planificarEstados(plan *ConfigPlan, estados []JiraStatus) {
+	vivos, colisiones := estadosGlobalesPorNombre(estados)
+	deseados := make(map[string]bool)
+	for i := range plan.Desired.Estados {
+		deseado := &plan.Desired.Estados[i]
+		deseado.Name = strings.TrimSpace(deseado.Name)
+		clave := strings.ToLower(deseado.Name)
+		if deseado.Name == "" {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("Hay un estado deseado sin nombre (posición %d)", i+1))
+			continue
+		}
+		categoria, ok := normalizarCategoria(deseado.StatusCategory)
+		if !ok {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("Estado %s: categoría %q no válida (TODO, IN_PROGRESS o DONE)", deseado.Name, deseado.StatusCategory))
+			continue
+		}
+		deseado.StatusCategory = categoria
+		if deseados[clave] {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("El estado %s aparece más de una vez", deseado.Name))
+			continue
+		}
+		deseados[clave] = true
+		if repetidos, ok := colisiones[clave]; ok {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("Estado %s: hay varios estados globales con ese nombre salvo mayúsculas: %s", deseado.Name, describirColision(repetidos)))
+			continue
+		}
+
+		vivo, existe := vivos[clave]
+		if !existe {
+			plan.Changes = append(plan.Changes, PlanChange{
+				ObjectType: "estado",
+				Action:     "create",
+				Name:       deseado.Name,
+				Changes:    []string{fmt.Sprintf("categoría %s", categoria)},
+			})
+			continue
+		}
+		var cambios []string
+		if vivo.Name != deseado.Name {
+			cambios = append(cambios, cambio("nombre", vivo.Name, deseado.Name))
+		}
+		if vivo.Description != deseado.Description {
+			cambios = append(cambios, cambio("descripción", vivo.Description, deseado.Description))
+		}
+		if vivo.StatusCategory != categoria {
+			cambios = append(cambios, cambio("categoría", vivo.StatusCategory, categoria))
+		}
+		if len(cambios) > 0 {
+			plan.Changes = append(plan.Changes, PlanChange{ObjectType: "estado", Action: "update", Name: deseado.Name, ID: vivo.ID, Changes: cambios})
+		}
+	}
+
+	if !plan.Desired.Eliminar.Estados {
+		return
+	}
+	for _, st := range estados {
+		if ambitoEstado(st) != "GLOBAL" || deseados[strings.ToLower(st.Name)] {
+			continue
+		}
+		if len(st.Usages) > 0 || len(st.WorkflowUsages) > 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("El estado %s no está en el fichero pero está en uso; no se borrará", st.Name))
+			continue
+		}
+		plan.Changes = append(plan.Changes, PlanChange{ObjectType: "estado", Action: "delete", Name: st.Name, ID: st.ID, Changes: []string{}})
+	}
+}
+
+// planificarCategorias compara las categorías de proyecto deseadas con las del sitio.
+func planificarCategorias(plan *ConfigPlan, categorias []ProjectCategory) {
+	vivas := make(map[string]ProjectCategory, len(categorias))
+	for _, c := range categorias {
+		vivas[strings.ToLower(c.Name)] = c
+	}
+	deseadas := make(map[string]bool)
+	for i := range plan.Desired.CategoriasProyecto {
+		deseada := &plan.Desired.CategoriasProyecto[i]
+		deseada.Name = strings.TrimSpace(deseada.Name)
+		clave := strings.ToLower(deseada.Name)
+		switch {
+		case deseada.Name == "":
+			plan.Errors = append(plan.Errors, fmt.Sprintf("Hay una categoría de proyecto sin nombre (posición %d)", i+1))
+			continue
+		case deseadas[clave]:
+			plan.Errors = append(plan.Errors, fmt.Sprintf("La categoría de proyecto %s aparece más de una vez", deseada.Name))
+			continue
+		}
+		deseadas[clave] = true
+
+		viva, existe := vivas[clave]
+		if !existe {
+			plan.Changes = append(plan.Changes, PlanChange{ObjectType: "categoriaProyecto", Action: "create", Name: deseada.Name, Changes: []string{}})
+			continue
+		}
+		var cambios []string
+		if viva.Name != deseada.Name {
+			cambios = append(cambios, cambio("nombre", viva.Name, deseada.Name))
+		}
+		if viva.Description != deseada.Description {
+			cambios = append(cambios, cambio("descripción", viva.Description, deseada.Description))
+		}
+		if len(cambios) > 0 {
+			plan.Changes = append(plan.Changes, PlanChange{ObjectType: "categoriaProyecto", Action: "update", Name: deseada.Name, ID: viva.ID, Changes: cambios})
+		}
+	}
+
+	if !plan.Desired.Eliminar.CategoriasProyecto {
+		return
+	}
+	for _, c := range categorias {
+		if !deseadas[strings.ToLower(c.Name)] {
+			plan.Changes = append(plan.Changes, PlanChange{ObjectType: "categoriaProyecto", Action: "delete", Name: c.Name, ID: c.ID, Changes: []string{}})
+		}
+	}
+}
+
+// estadosConNuevos añade a los estados del sitio los que el plan va a crear, con un ID provisional,
+// para poder validar workflows que ya los usan.
+func estadosConNuevos(plan *ConfigPlan, estados []JiraStatus) []JiraStatus {
+	resultado := append([]JiraStatus{}, estados...)
+	for _, c := range plan.Changes {
+		if c.ObjectType != "estado" || c.Action != "create" {
+			continue
+		}
+		for _, deseado := range plan.Desired.Estados {
+			if deseado.Name == c.Name {
+				resultado = append(resultado, JiraStatus{ID: "nuevo:" + deseado.Name, Name: deseado.Name, StatusCategory: deseado.StatusCategory})
+			}
+		}
+	}
+	return resultado
+}
+
+// planificarWorkflows valida cada workflow deseado y lo compara con el del sitio del mismo nombre.
+func planificarWorkflows(plan *ConfigPlan, estados []JiraStatus, workflows []JiraWorkflow) {
+	disponibles := estadosConNuevos(plan, estados)
+	vistos := make(map[string]bool)
+	for _, deseado := range plan.Desired.Workflows {
+		resuelto, errores, avisos := validarWorkflowDeclarado(deseado, disponibles)
+		nombre := resuelto.ID.Name
+		for _, e := range errores {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("Workflow %s: %s", nombre, e))
+		}
+		for _, a := range avisos {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("Workflow %s: %s", nombre, a))
+		}
+		if len(errores) > 0 {
+			continue
+		}
+		if vistos[nombre] {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("El workflow %s aparece más de una vez", nombre))
+			continue
+		}
+		vistos[nombre] = true
+
+		vivo, existe := buscarWorkflow(workflows, nombre)
+		if !existe {
+			plan.Changes = append(plan.Changes, PlanChange{
+				ObjectType: "workflow",
+				Action:     "create",
+				Name:       nombre,
+				Changes:    []string{fmt.Sprintf("%d estados, %d transiciones", len(resuelto.Statuses), len(resuelto.Transitions))},
+			})
+			continue
+		}
+		// Las pantallas no se envían y la configuración de las reglas no tiene el mismo formato al
+		// leer que al escribir, así que solo se comparan los tipos de regla
+		cambios := resumenWorkflowDiff(compararWorkflows(sinPantallas(vivo), sinPantallas(resuelto), false))
+		if vivo.Description != resuelto.Description {
+			cambios = append(cambios, cambio("descripción", vivo.Description, resuelto.Description))
+		}
+		for _, perdida := range perdidasAlAplicar(vivo, resuelto) {
+			cambios = append(cambios, perdida)
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("Workflow %s: al aplicar se pierden las %s", nombre, perdida))
+		}
+		if len(cambios) > 0 {
+			plan.Changes = append(plan.Changes, PlanChange{ObjectType: "workflow", Action: "update", Name: nombre, Changes: cambios})
+		}
+	}
+}
+
+// sinPantallas devuelve una copia del workflow sin las pantallas de las transiciones, que el
+// fichero no puede cambiar: al aplicar se conservan las de Jira.
+func sinPantallas(wf JiraWorkflow) JiraWorkflow {
+	wf.Transitions = append([]Transition(nil), wf.Transitions...)
+	for i := range wf.Transitions {
+		wf.Transitions[i].Screen = nil
+	}
+	return wf
+}
+
+// tiposReglas lista los tipos de todas las reglas de una transición, condiciones del árbol incluidas.
+func tiposReglas(t Transition) []string {
+	if t.Rules == nil {
+		return nil
+	}
+	var tipos []string
+	var recorrer func(c WorkflowCondition)
+	recorrer = func(c WorkflowCondition) {
+		if c.NodeType != "compound" {
+			tipos = append(tipos, c.Type)
+			return
+		}
+		for _, hijo := range c.Conditions {
+			recorrer(hijo)
+		}
+	}
+	if t.Rules.ConditionsTree != nil {
+		recorrer(*t.Rules.ConditionsTree)
+	}
+	for _, grupo := range [][]WorkflowRule{t.Rules.Conditions, t.Rules.Validators, t.Rules.PostFunctions} {
+		for _, r := range grupo {
+			tipos = append(tipos, r.Type)
+		}
+	}
+	return tipos
+}
+
+// perdidasAlAplicar describe lo que se elimina de las transiciones del workflow vivo al sustituirlas
+// por las deseadas: reglas cuyo tipo ya no aparece y propiedades que el fichero no trae. Las
+// transiciones se emparejan por nombre, origen y destino, igual que al aplicar.
+func perdidasAlAplicar(vivo, deseado JiraWorkflow) []string {
+	deseadas := make(map[string]Transition, len(deseado.Transitions))
+	for _, t := range deseado.Transitions {
+		deseadas[claveTransicion(t.Name, t.From, t.To)] = t
+	}
+	var perdidas []string
+	for _, viva := range vivo.Transitions {
+		nueva, ok := deseadas[claveTransicion(viva.Name, viva.From, viva.To)]
+		if !ok {
+			continue
+		}
+		restantes := make(map[string]int)
+		for _, tipo := range tiposReglas(nueva) {
+			restantes[tipo]++
+		}
+		var reglas []string
+		for _, tipo := range tiposReglas(viva) {
+			if restantes[tipo] > 0 {
+				restantes[tipo]--
+				continue
+			}
+			reglas = append(reglas, tipo)
+		}
+		if len(reglas) > 0 {
+			perdidas = append(perdidas, fmt.Sprintf("reglas de la transición %s: %s", viva.Name, strings.Join(reglas, ", ")))
+		}
+		var propiedades []string
+		for clave := range viva.Properties {
+			if _, ok := nueva.Properties[clave]; !ok {
+				propiedades = append(propiedades, clave)
+			}
+		}
+		if len(propiedades) > 0 {
+			sort.Strings(propiedades)
+			perdidas = append(perdidas, fmt.Sprintf("propiedades de la transición %s: %s", viva.Name, strings.Join(propiedades, ", ")))
+		}
+	}
+	return perdidas
+}
+
+// calcularPlan compara el estado deseado con la configuración en vivo.
+func calcularPlan(deseado DesiredState, estados []JiraStatus, categorias []ProjectCategory, workflows []JiraWorkflow) ConfigPlan {
+	plan := ConfigPlan{
+		Desired:     deseado,
+		Fingerprint: huellaConfiguracion(estados, categorias, workflows),
+		Changes:     []PlanChange{},
+		Errors:      []string{},
+		Warnings:    []string{},
+	}
+	planificarCategorias(&plan, categorias)
+	planificarEstados(&plan, estados)
+	planificarWorkflows(&plan, estados, workflows)
+	return plan
+}
+
+// generarPlan lee el fichero de estado deseado, lo compara con una descarga en vivo de la conexión
+// activa y guarda el plan resultante.
+func generarPlan(contenido []byte) (ConfigPlan, error) {
+	var deseado DesiredState
+	if err := decodificarDeclarativo(contenido, &deseado); err != nil {
+		return ConfigPlan{}, fmt.Errorf("el fichero no tiene la forma de un estado deseado: %w", err)
+	}
+	conn, err := getCredentials()
+	if err != nil {
+		return ConfigPlan{}, fmt.Errorf("no hay conexión activa: %w", err)
+	}
+	estados, categorias, workflows, err := configuracionViva(conn)
+	if err != nil {
+		return ConfigPlan{}, err
+	}
+
+	plan := calcularPlan(deseado, estados, categorias, workflows)
+	plan.CreatedAt = time.Now().UTC()
+	plan.ID = plan.CreatedAt.Format(snapshotIDFormat)
+	plan.Domain = conn.Domain
+	if err := guardarPlan(plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// guardarPlan escribe el plan en planes/<dominio>/<id>.json.
+func guardarPlan(plan ConfigPlan) error {
+	dir := planesDirPath(plan.Domain)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creando la carpeta de planes: %w", err)
+	}
+	jsonBytes, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formateando JSON: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, plan.ID+".json"), jsonBytes, 0644)
+}
+
+// cargarPlan lee un plan guardado de un dominio por su ID.
+func cargarPlan(domain, id string) (ConfigPlan, error) {
+	var plan ConfigPlan
+	// El ID llega desde la URL o la línea de comandos: no se permite salir de la carpeta de planes
+	if id == "" || filepath.Base(id) != id {
+		return plan, fmt.Errorf("ID de plan inválido: %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(planesDirPath(domain), id+".json"))
+	if err != nil {
+		return plan, fmt.Errorf("error leyendo el plan %s: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("error parseando el plan %s: %w", id, err)
+	}
+	return plan, nil
+}
+
+// listarPlanes devuelve los planes guardados de un dominio, del más reciente al más antiguo.
+func listarPlanes(domain string) ([]ConfigPlan, error) {
+	entradas, err := os.ReadDir(planesDirPath(domain))
+	if os.IsNotExist(err) {
+		return []ConfigPlan{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la carpeta de planes: %w", err)
+	}
+	planes := []ConfigPlan{}
+	for _, entrada := range entradas {
+		if entrada.IsDir() || !strings.HasSuffix(entrada.Name(), ".json") {
+			continue
+		}
+		plan, err := cargarPlan(domain, strings.TrimSuffix(entrada.Name(), ".json"))
+		if err != nil {
+			log.Println("Plan ignorado:", err)
+			continue
+		}
+		planes = append(planes, plan)
+	}
+	sort.Slice(planes, func(i, j int) bool { return planes[i].ID > planes[j].ID })
+	return planes, nil
+}
+
+// intentoPlan indica si ya se intentó aplicar el plan: sus cambios tienen resultado aunque no
+// tenga AppliedAt porque alguno falló.
+func intentoPlan(plan ConfigPlan) bool {
+	for _, c := range plan.Changes {
+		if c.Result != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// aplicarPlan ejecuta los cambios de un plan aprobado. Se rechaza si ya se aplicó, si tiene errores,
+// si es de otro sitio o si la configuración en vivo ha cambiado desde que se generó. AppliedAt solo
+// se marca si todos los cambios se aplicaron; si alguno falla se guardan los resultados y se
+// devuelve el plan junto con un error de aplicación parcial.
+func aplicarPlan(id string, aprobado bool) (ConfigPlan, error) {
+	conn, err := getCredentials()
+	if err != nil {
+		return ConfigPlan{}, fmt.Errorf("no hay conexión activa: %w", err)
+	}
+	plan, err := cargarPlan(conn.Domain, id)
+	if err != nil {
+		return plan, err
+	}
+	if err := comprobarPlanAplicable(plan, aprobado, conn.Domain); err != nil {
+		return plan, err
+	}
+
+	estados, categorias, workflows, err := configuracionViva(conn)
+	if err != nil {
+		return plan, err
+	}
+	if err := comprobarHuellaPlan(plan, estados, categorias, workflows); err != nil {
+		return plan, err
+	}
+
+	client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+	abrirLoteDiario(client)
+	ejecutarCambiosPlan(client, &plan, estados)
+	cerrarLoteDiario(client)
+
+	fallidos := 0
+	for _, c := range plan.Changes {
+		if c.Result == "error" {
+			fallidos++
+		}
+	}
+	if fallidos == 0 {
+		ahora := time.Now().UTC()
+		plan.AppliedAt = &ahora
+	}
+	if err := guardarPlan(plan); err != nil {
+		log.Println("Error al guardar el resultado del plan:", err)
+	}
+
+	// El snapshot se refresca con la configuración que ha quedado tras aplicar
+	if datos, err := ejecutarConsultaJira(conn.Domain, conn.Correo, conn.Token, opcionesPlan); err != nil {
+		log.Println("Error al refrescar el snapshot tras aplicar el plan:", err)
+	} else {
+		guardarSnapshot(conn, datos)
+	}
+	if fallidos > 0 {
+		return plan, fmt.Errorf("el plan %s se aplicó solo en parte: fallaron %d de %d cambios; revisa los resultados y genera un plan nuevo", id, fallidos, len(plan.Changes))
+	}
+	return plan, nil
+}
+
+// comprobarPlanAplicable comprueba, antes de leer nada de Jira, que el plan está aprobado, no se ha
+// aplicado ni intentado aplicar, no tiene errores y es del dominio de la conexión activa.
+func comprobarPlanAplicable(plan ConfigPlan, aprobado bool, dominio string) error {
+	switch {
+	case !aprobado:
+		return fmt.Errorf("el plan %s debe aprobarse explícitamente antes de aplicarlo", plan.ID)
+	case plan.AppliedAt != nil:
+		return fmt.Errorf("el plan %s ya se aplicó el %s", plan.ID, plan.AppliedAt.Format(time.RFC3339))
+	case intentoPlan(plan):
+		return fmt.Errorf("el plan %s ya se intentó aplicar y quedó aplicado en parte; genera un plan nuevo", plan.ID)
+	case len(plan.Errors) > 0:
+		return fmt.Errorf("el plan %s tiene errores y no se puede aplicar", plan.ID)
+	case plan.Domain != dominio:
+		return fmt.Errorf("el plan %s es de %s y la conexión activa es %s", plan.ID, plan.Domain, dominio)
+	}
+	return nil
+}
+
+// comprobarHuellaPlan rechaza el plan si la configuración en vivo ya no es la que se leyó al generarlo.
+func comprobarHuellaPlan(plan ConfigPlan, estados []JiraStatus, categorias []ProjectCategory, workflows []JiraWorkflow) error {
+	if huellaConfiguracion(estados, categorias, workflows) != plan.Fingerprint {
+		return fmt.Errorf("la configuración de %s ha cambiado desde que se generó el plan %s; genera un plan nuevo", plan.Domain, plan.ID)
+	}
+	return nil
+}
+
+// ejecutarCambiosPlan aplica los cambios en orden: categorías, estados nuevos y modificados,
+// workflows y por último los borrados de estados, que pueden depender de los workflows.
+func ejecutarCambiosPlan(client *resty.Client, plan *ConfigPlan, estados []JiraStatus) {
+	deseadoEstado := make(map[string]JiraStatusCreate)
+	for _, st := range plan.Desired.Estados {
+		deseadoEstado[st.Name] = st
+	}
+	deseadaCategoria := make(map[string]ProjectCategory)
+	for _, c := range plan.Desired.CategoriasProyecto {
+		deseadaCategoria[c.Name] = c
+	}
+	deseadoWorkflow := make(map[string]JiraWorkflow)
+	for _, wf := range plan.Desired.Workflows {
+		deseadoWorkflow[wf.ID.Name] = wf
+	}
+
+	resultado := func(c *PlanChange, err error) {
+		if err != nil {
+			c.Result, c.Message = "error", err.Error()
+			return
+		}
+		c.Result = "applied"
+	}
+
+	orden := []struct{ tipo, accion string }{
+		{"categoriaProyecto", "create"}, {"categoriaProyecto", "update"}, {"categoriaProyecto", "delete"},
+		{"estado", "create"}, {"estado", "update"},
+		{"workflow", "create"}, {"workflow", "update"},
+		{"estado", "delete"},
+	}
+	for _, paso := range orden {
+		for i := range plan.Changes {
+			c := &plan.Changes[i]
+			if c.ObjectType != paso.tipo || c.Action != paso.accion {
+				continue
+			}
+			switch paso.tipo + "/" + paso.accion {
+			case "categoriaProyecto/create":
+				creada, err := crearCategoriaProyectoJira(client, deseadaCategoria[c.Name])
+				c.ID = creada.ID
+				resultado(c, err)
+			case "categoriaProyecto/update":
+				categoria := deseadaCategoria[c.Name]
+				categoria.ID = c.ID
+				resultado(c, actualizarCategoriaProyectoJira(client, categoria))
+			case "categoriaProyecto/delete":
+				resultado(c, borrarCategoriaProyectoJira(client, c.ID))
+			case "estado/create":
+				creados, err := crearEstadosJira(client, []JiraStatus{{Name: c.Name, Description: deseadoEstado[c.Name].Description, StatusCategory: deseadoEstado[c.Name].StatusCategory}})
+				if err == nil && len(creados) > 0 {
+					c.ID = creados[0].ID
+					estados = append(estados, creados[0])
+				}
+				resultado(c, err)
+			case "estado/update":
+				deseado := deseadoEstado[c.Name]
+				_, err := actualizarEstadosJira(client, []JiraStatusUpdate{{ID: c.ID, Name: deseado.Name, Description: deseado.Description, StatusCategory: deseado.StatusCategory}})
+				if err == nil {
+					for j := range estados {
+						if estados[j].ID == c.ID {
+							estados[j].Name, estados[j].StatusCategory = deseado.Name, deseado.StatusCategory
+						}
+					}
+				}
+				resultado(c, err)
+			case "workflow/create", "workflow/update":
+				resultado(c, aplicarWorkflowPlan(client, deseadoWorkflow[c.Name], estados))
+			case "estado/delete":
+				// Se vuelve a comprobar que sigue sin uso justo antes de borrarlo
+				comprobaciones, _, err := verificarBorradoEstados(client, []string{c.ID})
+				if err == nil && (len(comprobaciones) == 0 || !comprobaciones[0].Deletable) {
+					c.Result = "skipped"
+					if len(comprobaciones) > 0 {
+						c.Message = comprobaciones[0].Reason
+					}
+					continue
+				}
+				if err == nil {
+					_, err = borrarEstadosJira(client, []string{c.ID})
+				}
+				resultado(c, err)
+			}
+		}
+	}
+}
+
+// aplicarWorkflowPlan crea o actualiza un workflow del plan resolviendo sus estados contra los
+// estados actuales, que ya incluyen los creados por el propio plan.
+func aplicarWorkflowPlan(client *resty.Client, deseado JiraWorkflow, estados []JiraStatus) error {
+	resuelto, errores, _ := validarWorkflowDeclarado(deseado, estados)
+	if len(errores) > 0 {
+		return fmt.Errorf("%s", strings.Join(errores, "; "))
+	}
+	vivo, err := obtenerWorkflowVivoJira(client, resuelto.ID.Name)
+	if err != nil {
+		return err
+	}
+	cuerpo := cuerpoEscrituraWorkflow(resuelto, estados, vivo)
+	if vivo != nil {
+		return actualizarWorkflowJira(client, cuerpo)
+	}
+	return crearWorkflowJira(client, cuerpo)
+}
+
+// handlePlan genera y guarda un plan a partir del fichero de estado deseado.
+func handlePlan(w http.ResponseWriter, r *http.Request) {
+	var peticion PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := generarPlan([]byte(peticion.Content))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// handleListPlans devuelve los planes guardados de la conexión activa.
+func handleListPlans(w http.ResponseWriter, r *http.Request) {
+	conn, err := getCredentials()
+	if err != nil {
+		http.Error(w, "No hay conexión activa: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	planes, err := listarPlanes(conn.Domain)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(planes)
+}
+
+// handleApplyPlan aplica un plan guardado si viene aprobado y el sitio no ha cambiado.
+func handleApplyPlan(w http.ResponseWriter, r *http.Request) {
+	var peticion PlanApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := aplicarPlan(peticion.ID, peticion.Approve)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// imprimirPlan muestra un plan en la consola.
+func imprimirPlan(plan ConfigPlan) {
+	simbolos := map[string]string{"create": "+", "update": "~", "delete": "-"}
+	fmt.Printf("Plan %s para %s\n\n", plan.ID, plan.Domain)
+	if len(plan.Changes) == 0 {
+		fmt.Println("Sin cambios: el sitio ya está en el estado deseado.")
+	}
+	for _, c := range plan.Changes {
+		linea := fmt.Sprintf("  %s %s %s", simbolos[c.Action], c.ObjectType, c.Name)
+		if len(c.Changes) > 0 {
+			linea += ": " + strings.Join(c.Changes, "; ")
+		}
+		if c.Result != "" {
+			linea += fmt.Sprintf(" [%s]", c.Result)
+		}
+		if c.Message != "" {
+			linea += " " + c.Message
+		}
+		fmt.Println(linea)
+	}
+	for _, a := range plan.Warnings {
+		fmt.Println("  AVISO:", a)
+	}
+	for _, e := range plan.Errors {
+		fmt.Println("  ERROR:", e)
+	}
+}
+
+// planCLI genera un plan desde la línea de comandos: app plan <fichero>.
+func planCLI(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Uso: app plan <estado-deseado.yaml>")
+		return 2
+	}
+	contenido, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 2
+	}
+	plan, err := generarPlan(contenido)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 2
+	}
+	imprimirPlan(plan)
+	if len(plan.Errors) > 0 {
+		fmt.Println("\nEl plan tiene errores y no se podrá aplicar.")
+		return 1
+	}
+	fmt.Printf("\nPlan guardado. Para aplicarlo: app apply %s\n", plan.ID)
+	return 0
+}
+
+// applyCLI aplica un plan guardado: app apply <id> [--approve]. Sin --approve pide confirmación.
+func applyCLI(args []string) int {
+	var id string
+	aprobado := false
+	for _, arg := range args {
+		if arg == "--approve" {
+			aprobado = true
+		} else {
+			id = arg
+		}
+	}
+	if id == "" {
+		fmt.Println("Uso: app apply <id-plan> [--approve]")
+		return 2
+	}
+
+	if !aprobado {
+		conn, err := getCredentials()
+		if err != nil {
+			fmt.Println("Error: no hay conexión activa:", err)
+			return 2
+		}
+		plan, err := cargarPlan(conn.Domain, id)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return 2
+		}
+		imprimirPlan(plan)
+		fmt.Print("\nEscribe 'si' para aplicar este plan: ")
+		respuesta, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		aprobado = strings.EqualFold(strings.TrimSpace(respuesta), "si")
+		if !aprobado {
+			fmt.Println("Plan no aplicado.")
+			return 1
+		}
+	}
+
+	plan, err := aplicarPlan(id, aprobado)
+	if err != nil {
+		// Si se llegó a aplicar en parte se muestra qué cambios fallaron
+		if plan.AppliedAt == nil && intentoPlan(plan) {
+			imprimirPlan(plan)
+		}
+		fmt.Println("Error:", err)
+		return 1
+	}
+	imprimirPlan(plan)
+	return 0
+}
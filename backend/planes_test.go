@@ -0,0 +1,375 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// resumenCambios describe los cambios de un plan como "objeto acción nombre", ordenados.
+func resumenCambios(plan ConfigPlan) []string {
+	resumen := []string{}
+	for _, c := range plan.Changes {
+		resumen = append(resumen, c.ObjectType+" "+c.Action+" "+c.Name)
+	}
+	sort.Strings(resumen)
+	return resumen
+}
+
+func TestHuellaConfiguracion(t *testing.T) {
+	estados := []JiraStatus{
+		{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "2", Name: "Done", StatusCategory: "DONE"},
+	}
+	categorias := []ProjectCategory{{ID: "10", Name: "Interno"}, {ID: "11", Name: "Clientes"}}
+	workflows := []JiraWorkflow{{ID: WorkflowID{Name: "A"}}, {ID: WorkflowID{Name: "B"}}}
+	base := huellaConfiguracion(estados, categorias, workflows)
+
+	casos := []struct {
+		nombre     string
+		estados    []JiraStatus
+		categorias []ProjectCategory
+		workflows  []JiraWorkflow
+		igual      bool
+	}{
+		{
+			nombre:     "mismo contenido en otro orden",
+			estados:    []JiraStatus{estados[1], estados[0]},
+			categorias: []ProjectCategory{categorias[1], categorias[0]},
+			workflows:  []JiraWorkflow{workflows[1], workflows[0]},
+			igual:      true,
+		},
+		{
+			nombre: "los usos no cuentan",
+			estados: []JiraStatus{
+				{ID: "1", Name: "To Do", StatusCategory: "TODO", Usages: []JiraStatusUsage{{Project: JiraScopeProject{ID: "100"}}}},
+				estados[1],
+			},
+			categorias: categorias,
+			workflows:  workflows,
+			igual:      true,
+		},
+		{
+			nombre:     "estado renombrado",
+			estados:    []JiraStatus{{ID: "1", Name: "Por hacer", StatusCategory: "TODO"}, estados[1]},
+			categorias: categorias,
+			workflows:  workflows,
+		},
+		{
+			nombre:     "categoría nueva",
+			estados:    estados,
+			categorias: append([]ProjectCategory{{ID: "12", Name: "Otra"}}, categorias...),
+			workflows:  workflows,
+		},
+		{
+			nombre:     "workflow con otra descripción",
+			estados:    estados,
+			categorias: categorias,
+			workflows:  []JiraWorkflow{{ID: WorkflowID{Name: "A"}, Description: "nueva"}, workflows[1]},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			huella := huellaConfiguracion(caso.estados, caso.categorias, caso.workflows)
+			if (huella == base) != caso.igual {
+				t.Errorf("huella igual = %v, se esperaba %v", huella == base, caso.igual)
+			}
+		})
+	}
+}
+
+func TestCalcularPlan(t *testing.T) {
+	vivos := []JiraStatus{
+		{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+		{ID: "2", Name: "In Progress", StatusCategory: "IN_PROGRESS", Description: "Trabajando"},
+		{ID: "3", Name: "Done", StatusCategory: "DONE"},
+		{ID: "4", Name: "Obsoleto", StatusCategory: "TODO"},
+		{ID: "5", Name: "En uso", StatusCategory: "TODO", WorkflowUsages: []JiraStatusWorkflowUsage{{WorkflowName: "Viejo"}}},
+		{ID: "6", Name: "De proyecto", StatusCategory: "TODO", Scope: &JiraScope{Type: "PROJECT", Project: &JiraScopeProject{ID: "100"}}},
+	}
+	categorias := []ProjectCategory{{ID: "10", Name: "Interno"}, {ID: "11", Name: "Antigua"}}
+	existente := JiraWorkflow{
+		ID:       WorkflowID{Name: "Existente"},
+		Statuses: []WorkflowStatus{{ID: "1", Name: "To Do"}, {ID: "3", Name: "Done"}},
+		Transitions: []Transition{{ID: "1", Name: "Create", Type: "initial", To: "1"}, {
+			ID: "11", Name: "Finish", Type: "directed", From: []string{"1"}, To: "3",
+			Screen: &TransitionScreen{ID: "20", Name: "Resolución"},
+			Rules:  &TransitionRules{Validators: []WorkflowRule{{Type: "FieldRequiredValidator", Configuration: map[string]interface{}{"fieldIds": []interface{}{"10001"}}}}},
+		}},
+	}
+	// Lo que se puede escribir en un fichero para el workflow existente: sin pantalla y con otra
+	// forma de configurar el validador
+	declarado := func(reglas *TransitionRules) JiraWorkflow {
+		return JiraWorkflow{
+			ID:       WorkflowID{Name: "Existente"},
+			Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "Done"}},
+			Transitions: []Transition{
+				{Name: "Create", Type: "initial", To: "To Do"},
+				{Name: "Finish", From: []string{"To Do"}, To: "Done", Rules: reglas},
+			},
+		}
+	}
+
+	casos := []struct {
+		nombre  string
+		deseado DesiredState
+		estados []JiraStatus
+		cambios []string
+		errores []string
+		avisos  []string
+	}{
+		{
+			nombre: "sin cambios",
+			deseado: DesiredState{
+				Estados:            []JiraStatusCreate{{Name: "To Do", StatusCategory: "TODO"}, {Name: "Done", StatusCategory: "done"}},
+				CategoriasProyecto: []ProjectCategory{{Name: "Interno"}},
+				Workflows:          []JiraWorkflow{existente},
+			},
+			cambios: []string{},
+		},
+		{
+			nombre: "crear y actualizar estados",
+			deseado: DesiredState{Estados: []JiraStatusCreate{
+				{Name: "Review", StatusCategory: "IN_PROGRESS"},
+				{Name: "in progress", StatusCategory: "IN_PROGRESS", Description: "Trabajando"},
+				{Name: "Done", StatusCategory: "DONE", Description: "Terminado"},
+			}},
+			cambios: []string{"estado create Review", "estado update Done", "estado update in progress"},
+		},
+		{
+			nombre: "borrar estados globales sin uso",
+			deseado: DesiredState{
+				Estados:  []JiraStatusCreate{{Name: "To Do", StatusCategory: "TODO"}, {Name: "In Progress", StatusCategory: "IN_PROGRESS", Description: "Trabajando"}, {Name: "Done", StatusCategory: "DONE"}},
+				Eliminar: DesiredDeletes{Estados: true},
+			},
+			cambios: []string{"estado delete Obsoleto"},
+			avisos:  []string{"El estado En uso no está en el fichero pero está en uso"},
+		},
+		{
+			nombre: "estado repetido y categoría no válida",
+			deseado: DesiredState{Estados: []JiraStatusCreate{
+				{Name: "Nuevo", StatusCategory: "TODO"},
+				{Name: "NUEVO", StatusCategory: "TODO"},
+				{Name: "Raro", StatusCategory: "WAITING"},
+			}},
+			cambios: []string{"estado create Nuevo"},
+			errores: []string{"El estado NUEVO aparece más de una vez", "Estado Raro: categoría \"WAITING\" no válida"},
+		},
+		{
+			nombre:  "estados globales que solo cambian en mayúsculas",
+			deseado: DesiredState{Estados: []JiraStatusCreate{{Name: "Done", StatusCategory: "DONE"}}},
+			estados: append([]JiraStatus{{ID: "7", Name: "DONE", StatusCategory: "DONE"}}, vivos...),
+			cambios: []string{},
+			errores: []string{"Estado Done: hay varios estados globales con ese nombre salvo mayúsculas: DONE (7), Done (3)"},
+		},
+		{
+			nombre: "categorías de proyecto",
+			deseado: DesiredState{
+				CategoriasProyecto: []ProjectCategory{{Name: "Interno", Description: "Equipos internos"}, {Name: "Clientes"}},
+				Eliminar:           DesiredDeletes{CategoriasProyecto: true},
+			},
+			cambios: []string{"categoriaProyecto create Clientes", "categoriaProyecto delete Antigua", "categoriaProyecto update Interno"},
+		},
+		{
+			nombre: "workflow nuevo con un estado que crea el plan",
+			deseado: DesiredState{
+				Estados: []JiraStatusCreate{{Name: "Review", StatusCategory: "IN_PROGRESS"}},
+				Workflows: []JiraWorkflow{{
+					ID:       WorkflowID{Name: "Con revisión"},
+					Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "Review"}, {Name: "Done"}},
+					Transitions: []Transition{
+						{Name: "Create", Type: "initial", To: "To Do"},
+						{Name: "Review", From: []string{"To Do"}, To: "Review"},
+						{Name: "Finish", From: []string{"Review"}, To: "Done"},
+					},
+				}},
+			},
+			cambios: []string{"estado create Review", "workflow create Con revisión"},
+		},
+		{
+			nombre: "workflow existente modificado",
+			deseado: DesiredState{Workflows: []JiraWorkflow{{
+				ID:       WorkflowID{Name: "Existente"},
+				Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "In Progress"}, {Name: "Done"}},
+				Transitions: []Transition{
+					{Name: "Create", Type: "initial", To: "To Do"},
+					{Name: "Start", From: []string{"To Do"}, To: "In Progress"},
+					{Name: "Finish", From: []string{"In Progress"}, To: "Done"},
+				},
+			}}},
+			cambios: []string{"workflow update Existente"},
+		},
+		{
+			nombre: "workflow existente sin pantalla y con la configuración de reglas en otro formato",
+			deseado: DesiredState{Workflows: []JiraWorkflow{declarado(&TransitionRules{
+				Validators: []WorkflowRule{{Type: "FieldRequiredValidator", Configuration: map[string]interface{}{"fieldIds": "10001"}}},
+			})}},
+			cambios: []string{},
+		},
+		{
+			nombre:  "workflow existente que pierde un validador",
+			deseado: DesiredState{Workflows: []JiraWorkflow{declarado(nil)}},
+			cambios: []string{"workflow update Existente"},
+			avisos:  []string{"Workflow Existente: al aplicar se pierden las reglas de la transición Finish: FieldRequiredValidator"},
+		},
+		{
+			nombre: "workflow con errores",
+			deseado: DesiredState{Workflows: []JiraWorkflow{{
+				ID:          WorkflowID{Name: "Roto"},
+				Statuses:    []WorkflowStatus{{Name: "To Do"}, {Name: "Fantasma"}},
+				Transitions: []Transition{{Name: "Create", Type: "initial", To: "To Do"}},
+			}}},
+			cambios: []string{},
+			errores: []string{"Workflow Roto: Estado desconocido en el snapshot: Fantasma"},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			estados := caso.estados
+			if estados == nil {
+				estados = vivos
+			}
+			plan := calcularPlan(caso.deseado, estados, categorias, []JiraWorkflow{existente})
+			if got := resumenCambios(plan); !reflect.DeepEqual(got, caso.cambios) {
+				t.Errorf("cambios = %v, se esperaba %v", got, caso.cambios)
+			}
+			if len(caso.errores) == 0 && len(plan.Errors) > 0 {
+				t.Errorf("errores inesperados: %v", plan.Errors)
+			}
+			for _, e := range caso.errores {
+				if !contieneTexto(plan.Errors, e) {
+					t.Errorf("falta el error %q en %v", e, plan.Errors)
+				}
+			}
+			for _, a := range caso.avisos {
+				if !contieneTexto(plan.Warnings, a) {
+					t.Errorf("falta el aviso %q en %v", a, plan.Warnings)
+				}
+			}
+			if plan.Fingerprint != huellaConfiguracion(estados, categorias, []JiraWorkflow{existente}) {
+				t.Error("la huella del plan no es la de la configuración leída")
+			}
+		})
+	}
+}
+
+func TestComprobarPlanAplicable(t *testing.T) {
+	aplicado := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	valido := ConfigPlan{ID: "p1", Domain: "https://sitio.atlassian.net", Changes: []PlanChange{{ObjectType: "estado", Action: "create", Name: "Review"}}}
+
+	casos := []struct {
+		nombre   string
+		cambiar  func(p *ConfigPlan)
+		aprobado bool
+		dominio  string
+		error    string
+	}{
+		{nombre: "aplicable", aprobado: true},
+		{nombre: "sin aprobar", aprobado: false, error: "debe aprobarse"},
+		{nombre: "ya aplicado", aprobado: true, cambiar: func(p *ConfigPlan) { p.AppliedAt = &aplicado }, error: "ya se aplicó"},
+		{nombre: "aplicado en parte", aprobado: true, cambiar: func(p *ConfigPlan) { p.Changes[0].Result = "error" }, error: "quedó aplicado en parte"},
+		{nombre: "con errores", aprobado: true, cambiar: func(p *ConfigPlan) { p.Errors = []string{"Estado Raro: categoría no válida"} }, error: "tiene errores"},
+		{nombre: "de otro dominio", aprobado: true, dominio: "https://otro.atlassian.net", error: "la conexión activa es https://otro.atlassian.net"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			plan := valido
+			plan.Changes = append([]PlanChange{}, valido.Changes...)
+			if caso.cambiar != nil {
+				caso.cambiar(&plan)
+			}
+			dominio := caso.dominio
+			if dominio == "" {
+				dominio = valido.Domain
+			}
+			err := comprobarPlanAplicable(plan, caso.aprobado, dominio)
+			switch {
+			case caso.error == "" && err != nil:
+				t.Errorf("error inesperado: %v", err)
+			case caso.error != "" && (err == nil || !contieneTexto([]string{err.Error()}, caso.error)):
+				t.Errorf("error = %v, se esperaba uno con %q", err, caso.error)
+			}
+		})
+	}
+}
+
+func TestComprobarHuellaPlan(t *testing.T) {
+	estados := []JiraStatus{{ID: "1", Name: "To Do", StatusCategory: "TODO"}}
+	categorias := []ProjectCategory{{ID: "10", Name: "Interno"}}
+	workflows := []JiraWorkflow{{ID: WorkflowID{Name: "A"}}}
+	plan := calcularPlan(DesiredState{}, estados, categorias, workflows)
+
+	if err := comprobarHuellaPlan(plan, estados, categorias, workflows); err != nil {
+		t.Errorf("la misma configuración no debería rechazarse: %v", err)
+	}
+	renombrados := []JiraStatus{{ID: "1", Name: "Pendiente", StatusCategory: "TODO"}}
+	if err := comprobarHuellaPlan(plan, renombrados, categorias, workflows); err == nil {
+		t.Error("se esperaba un rechazo porque un estado ha cambiado desde que se generó el plan")
+	}
+	if err := comprobarHuellaPlan(plan, estados, nil, workflows); err == nil {
+		t.Error("se esperaba un rechazo porque ha desaparecido una categoría")
+	}
+}
+
+// Con un servidor que hace de Jira se comprueba que solo se escriben los cambios del plan, aunque
+// el estado deseado describa más objetos, y en el orden de ejecutarCambiosPlan.
+func TestEjecutarCambiosPlanSoloLosListados(t *testing.T) {
+	var mutex sync.Mutex
+	var escrituras []string
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			switch r.URL.Path {
+			case "/rest/api/3/statuses":
+				w.Write([]byte(`[{"id":"2","name":"In Progress","statusCategory":"IN_PROGRESS"}]`))
+			default:
+				w.Write([]byte(`{"id":"10","name":"Interno"}`))
+			}
+			return
+		}
+		mutex.Lock()
+		escrituras = append(escrituras, r.Method+" "+r.URL.Path)
+		mutex.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer servidor.Close()
+	// Las escrituras quedan en el diario, que se guarda en la carpeta de datos
+	t.Cleanup(func() {
+		os.RemoveAll(diarioDirPath(servidor.URL))
+		os.Remove(filepath.Dir(diarioDirPath(servidor.URL)))
+	})
+
+	plan := ConfigPlan{
+		Desired: DesiredState{
+			Estados:            []JiraStatusCreate{{Name: "In Progress", StatusCategory: "IN_PROGRESS", Description: "Trabajando"}, {Name: "Done", StatusCategory: "DONE"}},
+			CategoriasProyecto: []ProjectCategory{{Name: "Interno", Description: "Equipos internos"}, {Name: "Clientes"}},
+			Workflows:          []JiraWorkflow{{ID: WorkflowID{Name: "Existente"}}},
+		},
+		Changes: []PlanChange{
+			{ObjectType: "estado", Action: "update", Name: "In Progress", ID: "2"},
+			{ObjectType: "categoriaProyecto", Action: "update", Name: "Interno", ID: "10"},
+		},
+	}
+	client := conectarAJira(servidor.URL, "correo", "token")
+	abrirLoteDiario(client)
+	ejecutarCambiosPlan(client, &plan, []JiraStatus{{ID: "2", Name: "In Progress", StatusCategory: "IN_PROGRESS"}})
+	cerrarLoteDiario(client)
+
+	esperadas := []string{"PUT /rest/api/3/projectCategory/10", "PUT /rest/api/3/statuses"}
+	if !reflect.DeepEqual(escrituras, esperadas) {
+		t.Errorf("escrituras = %v, se esperaba %v", escrituras, esperadas)
+	}
+	for _, c := range plan.Changes {
+		if c.Result != "applied" {
+			t.Errorf("el cambio %s %s quedó como %q: %s", c.ObjectType, c.Name, c.Result, c.Message)
+		}
+	}
+}
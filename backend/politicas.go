@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ----------------------------------------------------------------
+// Motor de reglas de administración definidas en YAML y evaluadas sobre el snapshot
+// ----------------------------------------------------------------
+
+// Fichero de reglas por defecto (hay un ejemplo en backend/reglas.example.yaml)
+const reglasFilePath = jsonDirPath + "reglas.yaml"
+
+// Tipos de regla soportados
+const (
+	reglaPatronNombreEstado        = "patron_nombre_estado"
+	reglaPatronClaveProyecto       = "patron_clave_proyecto"
+	reglaMaxEstadosWorkflow        = "max_estados_workflow"
+	reglaCategoriaProyectoObligada = "categoria_proyecto_obligatoria"
+	reglaEstadosProhibidos         = "estados_prohibidos"
+)
+
+// Orden de las severidades para ordenar los resultados
+var ordenSeveridad = map[string]int{"error": 0, "warning": 1, "info": 2}
+
+// cargarReglas lee y valida el fichero YAML de reglas.
+func cargarReglas(filePath string) ([]PolicyRule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el fichero de reglas: %w", err)
+	}
+	var fichero PolicyFile
+	if err := yaml.Unmarshal(data, &fichero); err != nil {
+		return nil, fmt.Errorf("error parseando el fichero de reglas: %w", err)
+	}
+
+	for i := range fichero.Reglas {
+		regla := &fichero.Reglas[i]
+		if regla.ID == "" {
+			regla.ID = fmt.Sprintf("%s-%d", regla.Tipo, i+1)
+		}
+		if regla.Severidad == "" {
+			regla.Severidad = "warning"
+		}
+		if _, ok := ordenSeveridad[regla.Severidad]; !ok {
+			return nil, fmt.Errorf("regla %s: severidad %q no válida (error, warning o info)", regla.ID, regla.Severidad)
+		}
+		switch regla.Tipo {
+		case reglaPatronNombreEstado, reglaPatronClaveProyecto:
+			if _, err := regexp.Compile(regla.Patron); err != nil || regla.Patron == "" {
+				return nil, fmt.Errorf("regla %s: patrón no válido %q", regla.ID, regla.Patron)
+			}
+		case reglaMaxEstadosWorkflow:
+			if regla.Maximo <= 0 {
+				return nil, fmt.Errorf("regla %s: falta 'maximo'", regla.ID)
+			}
+		case reglaEstadosProhibidos:
+			if len(regla.Nombres) == 0 {
+				return nil, fmt.Errorf("regla %s: falta la lista 'nombres'", regla.ID)
+			}
+		case reglaCategoriaProyectoObligada:
+		default:
+			return nil, fmt.Errorf("regla %s: tipo desconocido %q", regla.ID, regla.Tipo)
+		}
+	}
+	return fichero.Reglas, nil
+}
+
+// Enlaces a la administración de Jira para cada tipo de objeto
+func enlaceEstados(domain string) string {
+	return strings.TrimSuffix(domain, "/") + "/secure/admin/ViewStatuses.jspa"
+}
+
+func enlaceProyecto(domain, key string) string {
+	return strings.TrimSuffix(domain, "/") + "/browse/" + url.PathEscape(key)
+}
+
+func enlaceWorkflow(domain, nombre string) string {
+	return strings.TrimSuffix(domain, "/") + "/secure/admin/workflows/ViewWorkflowSteps.jspa?workflowMode=live&workflowName=" + url.QueryEscape(nombre)
+}
+
+// evaluarReglas aplica cada regla a las secciones del snapshot que le corresponden.
+// Las reglas cuyas secciones faltan en el snapshot se ignoran.
+func evaluarReglas(reglas []PolicyRule, domain string, snapshot map[string]interface{}) ([]PolicyResult, error) {
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		return nil, err
+	}
+	var proyectos []JiraProject
+	if err := leerSeccion(snapshot, "proyectos", &proyectos); err != nil {
+		return nil, err
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		return nil, err
+	}
+
+	resultados := []PolicyResult{}
+	incumple := func(regla PolicyRule, tipoObjeto, objeto, mensaje, enlace string) {
+		resultados = append(resultados, PolicyResult{
+			Rule:       regla.ID,
+			Severity:   regla.Severidad,
+			ObjectType: tipoObjeto,
+			Object:     objeto,
+			Message:    mensaje,
+			Link:       enlace,
+		})
+	}
+
+	for _, regla := range reglas {
+		switch regla.Tipo {
+		case reglaPatronNombreEstado:
+			patron := regexp.MustCompile(regla.Patron)
+			for _, st := range estados {
+				if !patron.MatchString(st.Name) {
+					incumple(regla, "estado", st.Name, fmt.Sprintf("El nombre no cumple el patrón %s", regla.Patron), enlaceEstados(domain))
+				}
+			}
+		case reglaPatronClaveProyecto:
+			patron := regexp.MustCompile(regla.Patron)
+			for _, p := range proyectos {
+				if !patron.MatchString(p.Key) {
+					incumple(regla, "proyecto", p.Key, fmt.Sprintf("La clave no cumple el patrón %s", regla.Patron), enlaceProyecto(domain, p.Key))
+				}
+			}
+		case reglaMaxEstadosWorkflow:
+			for _, wf := range workflows {
+				if n := len(estadosDeWorkflow(wf)); n > regla.Maximo {
+					incumple(regla, "workflow", wf.ID.Name, fmt.Sprintf("Tiene %d estados (máximo %d)", n, regla.Maximo), enlaceWorkflow(domain, wf.ID.Name))
+				}
+			}
+		case reglaCategoriaProyectoObligada:
+			for _, p := range proyectos {
+				if nombreCategoria(p) == "" {
+					incumple(regla, "proyecto", p.Key, "No tiene categoría de proyecto", enlaceProyecto(domain, p.Key))
+				}
+			}
+		case reglaEstadosProhibidos:
+			prohibidos := make(map[string]bool)
+			for _, nombre := range regla.Nombres {
+				prohibidos[strings.ToLower(strings.TrimSpace(nombre))] = true
+			}
+			for _, st := range estados {
+				if prohibidos[strings.ToLower(strings.TrimSpace(st.Name))] {
+					incumple(regla, "estado", st.Name, "Nombre de estado prohibido", enlaceEstados(domain))
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(resultados, func(i, j int) bool {
+		return ordenSeveridad[resultados[i].Severity] < ordenSeveridad[resultados[j].Severity]
+	})
+	return resultados, nil
+}
+
+// ejecutarLintPoliticas carga las reglas y las evalúa sobre el snapshot de la conexión activa.
+func ejecutarLintPoliticas(filePath string) ([]PolicyResult, error) {
+	reglas, err := cargarReglas(filePath)
+	if err != nil {
+		return nil, err
+	}
+	conn, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		return nil, err
+	}
+	return evaluarReglas(reglas, conn.Domain, snapshot)
+}
+
+// handlePolicyLint devuelve los incumplimientos de las reglas del fichero de reglas por defecto.
+func handlePolicyLint(w http.ResponseWriter, r *http.Request) {
+	resultados, err := ejecutarLintPoliticas(reglasFilePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultados)
+}
+
+// lintPoliticasCLI ejecuta el lint desde la línea de comandos (app lint [reglas.yaml]).
+// Devuelve el código de salida: 1 si hay algún incumplimiento de severidad error.
+func lintPoliticasCLI(args []string) int {
+	filePath := reglasFilePath
+	if len(args) > 0 {
+		filePath = args[0]
+	}
+
+	resultados, err := ejecutarLintPoliticas(filePath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 2
+	}
+	return imprimirResultadosLint(os.Stdout, resultados)
+}
+
+// imprimirResultadosLint escribe los incumplimientos como tabla y devuelve el código de salida
+// del lint: 1 si alguno es de severidad error y 0 en otro caso.
+func imprimirResultadosLint(salida io.Writer, resultados []PolicyResult) int {
+	if len(resultados) == 0 {
+		fmt.Fprintln(salida, "Sin incumplimientos.")
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(salida, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERIDAD\tREGLA\tTIPO\tOBJETO\tMENSAJE\tENLACE")
+	codigo := 0
+	for _, res := range resultados {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", res.Severity, res.Rule, res.ObjectType, res.Object, res.Message, res.Link)
+		if res.Severity == "error" {
+			codigo = 1
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(salida, "\n%d incumplimientos.\n", len(resultados))
+	return codigo
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ficheroReglas escribe un fichero de reglas temporal y devuelve su ruta.
+func ficheroReglas(t *testing.T, contenido string) string {
+	t.Helper()
+	ruta := filepath.Join(t.TempDir(), "reglas.yaml")
+	if err := os.WriteFile(ruta, []byte(contenido), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return ruta
+}
+
+func TestCargarReglas(t *testing.T) {
+	casos := []struct {
+		nombre string
+		yaml   string
+		error  string
+	}{
+		{nombre: "todas válidas", yaml: `
+reglas:
+  - tipo: patron_nombre_estado
+    patron: "^[A-Z]"
+  - id: max
+    tipo: max_estados_workflow
+    severidad: error
+    maximo: 10
+  - tipo: estados_prohibidos
+    severidad: info
+    nombres: [Nuevo estado]
+  - tipo: categoria_proyecto_obligatoria
+`},
+		{nombre: "severidad desconocida", yaml: "reglas:\n  - tipo: categoria_proyecto_obligatoria\n    severidad: grave\n", error: "severidad \"grave\" no válida"},
+		{nombre: "patrón que no compila", yaml: "reglas:\n  - tipo: patron_clave_proyecto\n    patron: \"[A-Z\"\n", error: "patrón no válido"},
+		{nombre: "patrón vacío", yaml: "reglas:\n  - tipo: patron_nombre_estado\n", error: "patrón no válido"},
+		{nombre: "máximo sin indicar", yaml: "reglas:\n  - tipo: max_estados_workflow\n", error: "falta 'maximo'"},
+		{nombre: "lista de prohibidos vacía", yaml: "reglas:\n  - tipo: estados_prohibidos\n", error: "falta la lista 'nombres'"},
+		{nombre: "tipo desconocido", yaml: "reglas:\n  - tipo: longitud_nombre\n", error: "tipo desconocido"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			reglas, err := cargarReglas(ficheroReglas(t, caso.yaml))
+			if caso.error != "" {
+				if err == nil || !strings.Contains(err.Error(), caso.error) {
+					t.Errorf("error = %v, se esperaba uno con %q", err, caso.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			// Sin id se numera por tipo y posición; sin severidad es warning
+			if reglas[0].ID != "patron_nombre_estado-1" || reglas[0].Severidad != "warning" || reglas[1].ID != "max" || reglas[1].Severidad != "error" {
+				t.Errorf("valores por defecto: %+v", reglas)
+			}
+		})
+	}
+}
+
+func TestEvaluarReglas(t *testing.T) {
+	snapshot := map[string]interface{}{
+		"estados": []JiraStatus{{ID: "1", Name: "To Do"}, {ID: "2", Name: "en curso"}, {ID: "3", Name: " nuevo ESTADO "}},
+		"proyectos": []JiraProject{
+			{Key: "APP", ProjectCategory: &ProjectCategory{Name: "Interno"}},
+			{Key: "web2"},
+		},
+		"workflows": []JiraWorkflow{
+			{ID: WorkflowID{Name: "Grande"}, Statuses: []WorkflowStatus{{ID: "1"}, {ID: "2"}, {ID: "3"}}},
+			{ID: WorkflowID{Name: "Pequeño"}, Statuses: []WorkflowStatus{{ID: "1"}}},
+		},
+	}
+	reglas := []PolicyRule{
+		{ID: "mayusculas", Tipo: reglaPatronNombreEstado, Severidad: "info", Patron: "^[A-Z]"},
+		{ID: "claves", Tipo: reglaPatronClaveProyecto, Severidad: "warning", Patron: "^[A-Z]+$"},
+		{ID: "tamaño", Tipo: reglaMaxEstadosWorkflow, Severidad: "error", Maximo: 2},
+		{ID: "categoria", Tipo: reglaCategoriaProyectoObligada, Severidad: "warning"},
+		{ID: "prohibidos", Tipo: reglaEstadosProhibidos, Severidad: "error", Nombres: []string{"Nuevo estado"}},
+	}
+
+	resultados, err := evaluarReglas(reglas, "https://sitio.atlassian.net/", snapshot)
+	if err != nil {
+		t.Fatalf("error evaluando: %v", err)
+	}
+	var resumen []string
+	for _, r := range resultados {
+		resumen = append(resumen, r.Severity+" "+r.Rule+" "+r.Object)
+	}
+	// Primero los errores, luego warnings e info; dentro de cada severidad, el orden de las reglas
+	esperado := []string{
+		"error tamaño Grande",
+		"error prohibidos  nuevo ESTADO ",
+		"warning claves web2",
+		"warning categoria web2",
+		"info mayusculas en curso",
+		"info mayusculas  nuevo ESTADO ",
+	}
+	if !reflect.DeepEqual(resumen, esperado) {
+		t.Errorf("resultados =\n%q\nse esperaba\n%q", resumen, esperado)
+	}
+	if resultados[0].Link != "https://sitio.atlassian.net/secure/admin/workflows/ViewWorkflowSteps.jspa?workflowMode=live&workflowName=Grande" {
+		t.Errorf("enlace del workflow: %s", resultados[0].Link)
+	}
+	if resultados[2].Link != "https://sitio.atlassian.net/browse/web2" {
+		t.Errorf("enlace del proyecto: %s", resultados[2].Link)
+	}
+
+	// Sin las secciones que usa una regla no hay nada que evaluar
+	if vacios, err := evaluarReglas(reglas, "https://sitio.atlassian.net", map[string]interface{}{}); err != nil || len(vacios) != 0 {
+		t.Errorf("snapshot vacío: %v, %v", vacios, err)
+	}
+}
+
+func TestCodigoSalidaLint(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		resultados []PolicyResult
+		codigo     int
+		texto      string
+	}{
+		{nombre: "sin incumplimientos", codigo: 0, texto: "Sin incumplimientos."},
+		{nombre: "solo avisos", resultados: []PolicyResult{{Rule: "r1", Severity: "warning"}, {Rule: "r2", Severity: "info"}}, codigo: 0, texto: "2 incumplimientos."},
+		{nombre: "algún error", resultados: []PolicyResult{{Rule: "r1", Severity: "error", Object: "Grande"}, {Rule: "r2", Severity: "warning"}}, codigo: 1, texto: "Grande"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			var salida bytes.Buffer
+			if codigo := imprimirResultadosLint(&salida, caso.resultados); codigo != caso.codigo {
+				t.Errorf("código de salida = %d, se esperaba %d", codigo, caso.codigo)
+			}
+			if !strings.Contains(salida.String(), caso.texto) {
+				t.Errorf("falta %q en la salida:\n%s", caso.texto, salida.String())
+			}
+		})
+	}
+
+	// Un fichero de reglas que no se puede cargar es un error de uso, no un incumplimiento
+	if codigo := lintPoliticasCLI([]string{ficheroReglas(t, "reglas:\n  - tipo: desconocido\n")}); codigo != 2 {
+		t.Errorf("código de salida con reglas inválidas = %d, se esperaba 2", codigo)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------
+// Historial de snapshots: cada ejecución de /execute se guarda en
+// historial/<dominio>/<id>.json con sus metadatos, que se copian además en <id>.meta.json para
+// poder listar el historial sin leer los datos completos. Nada se sobrescribe: la vista actual
+// del dominio se compone con la última versión de cada sección
+// ----------------------------------------------------------------
+
+// Formato del ID de cada snapshot; ordena cronológicamente como texto
+const snapshotIDFormat = "20060102-150405.000"
+
+// Origen de un snapshot: una descarga de Jira o la actualización local tras una escritura
+const (
+	origenDescarga  = "execute"
+	origenEscritura = "write"
+)
+
+// historialMutex evita que dos snapshots guardados en el mismo milisegundo compartan ID
+var historialMutex sync.Mutex
+
+// Sufijo del fichero de metadatos que acompaña a cada snapshot
+const sufijoMetadatos = ".meta.json"
+
+// historialDirPath devuelve la carpeta de historial de un dominio.
+func historialDirPath(domain string) string {
+	return filepath.Join(jsonDirPath, "historial", strings.TrimSuffix(generateFileName(domain), ".json"))
+}
+
+// guardarMetadatosSnapshot escribe los metadatos de un snapshot en <id>.meta.json.
+func guardarMetadatosSnapshot(domain string, metadata SnapshotMetadata) error {
+	jsonBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formateando JSON: %w", err)
+	}
+	return os.WriteFile(filepath.Join(historialDirPath(domain), metadata.ID+sufijoMetadatos), jsonBytes, 0644)
+}
+
+// cargarMetadatosSnapshot lee los metadatos de un snapshot. Los snapshots guardados antes de
+// existir el fichero de metadatos se leen completos una vez y se les crea el fichero.
+func cargarMetadatosSnapshot(domain, id string) (SnapshotMetadata, error) {
+	var metadata SnapshotMetadata
+	data, err := os.ReadFile(filepath.Join(historialDirPath(domain), id+sufijoMetadatos))
+	if err == nil {
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return metadata, fmt.Errorf("error parseando los metadatos del snapshot %s: %w", id, err)
+		}
+		return metadata, nil
+	}
+	if !os.IsNotExist(err) {
+		return metadata, fmt.Errorf("error leyendo los metadatos del snapshot %s: %w", id, err)
+	}
+
+	snapshot, err := cargarSnapshotHistorial(domain, id)
+	if err != nil {
+		return metadata, err
+	}
+	if err := guardarMetadatosSnapshot(domain, snapshot.Metadata); err != nil {
+		log.Println("Error al guardar los metadatos del snapshot", id+":", err)
+	}
+	return snapshot.Metadata, nil
+}
+
+// guardarSnapshotHistorial guarda los datos de una ejecución con sus metadatos
+// (fecha, conexión, origen, secciones incluidas y número de elementos de cada una).
+func guardarSnapshotHistorial(conn Credentials, origen string, datos map[string]interface{}) (SnapshotMetadata, error) {
+	historialMutex.Lock()
+	defer historialMutex.Unlock()
+
+	dir := historialDirPath(conn.Domain)
+	ahora := time.Now().UTC()
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ahora.Format(snapshotIDFormat)+".json")); os.IsNotExist(err) {
+			break
+		}
+		ahora = ahora.Add(time.Millisecond)
+	}
+	metadata := SnapshotMetadata{
+		ID:        ahora.Format(snapshotIDFormat),
+		FetchedAt: ahora,
+		Domain:    conn.Domain,
+		Correo:    conn.Correo,
+		Origin:    origen,
+		Sections:  []string{},
+		Counts:    make(map[string]int),
+	}
+	for seccion, valor := range datos {
+		metadata.Sections = append(metadata.Sections, seccion)
+		if v := reflect.ValueOf(valor); v.Kind() == reflect.Slice {
+			metadata.Counts[seccion] = v.Len()
+		}
+	}
+	sort.Strings(metadata.Sections)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return metadata, fmt.Errorf("error creando la carpeta de historial: %w", err)
+	}
+	jsonBytes, err := json.MarshalIndent(SnapshotFile{Metadata: metadata, Datos: datos}, "", "  ")
+	if err != nil {
+		return metadata, fmt.Errorf("error formateando JSON: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, metadata.ID+".json"), jsonBytes, 0644); err != nil {
+		return metadata, fmt.Errorf("error guardando el snapshot: %w", err)
+	}
+	if err := guardarMetadatosSnapshot(conn.Domain, metadata); err != nil {
+		return metadata, fmt.Errorf("error guardando los metadatos del snapshot: %w", err)
+	}
+	return metadata, nil
+}
+
+// cargarSnapshotHistorial lee un snapshot del historial de un dominio por su ID.
+func cargarSnapshotHistorial(domain, id string) (SnapshotFile, error) {
+	var snapshot SnapshotFile
+	// El ID llega desde la URL: no se permite que salga de la carpeta de historial
+	if id == "" || filepath.Base(id) != id {
+		return snapshot, fmt.Errorf("ID de snapshot inválido: %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(historialDirPath(domain), id+".json"))
+	if err != nil {
+		return snapshot, fmt.Errorf("error leyendo el snapshot %s: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("error parseando el snapshot %s: %w", id, err)
+	}
+	return snapshot, nil
+}
+
+// listarSnapshotsHistorial devuelve los metadatos de todos los snapshots de un dominio,
+// del más reciente al más antiguo. Solo se leen los ficheros de metadatos.
+func listarSnapshotsHistorial(domain string) ([]SnapshotMetadata, error) {
+	entradas, err := os.ReadDir(historialDirPath(domain))
+	if os.IsNotExist(err) {
+		return []SnapshotMetadata{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el historial: %w", err)
+	}
+
+	lista := []SnapshotMetadata{}
+	for _, entrada := range entradas {
+		nombre := entrada.Name()
+		if entrada.IsDir() || !strings.HasSuffix(nombre, ".json") || strings.HasSuffix(nombre, sufijoMetadatos) {
+			continue
+		}
+		metadata, err := cargarMetadatosSnapshot(domain, strings.TrimSuffix(nombre, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		lista = append(lista, metadata)
+	}
+	sort.Slice(lista, func(i, j int) bool {
+		return lista[i].ID > lista[j].ID
+	})
+	return lista, nil
+}
+
+// componerSnapshot devuelve la vista actual de un dominio: cada sección se toma del snapshot más
+// reciente del historial que la incluye. Las secciones que no están en el historial, o cuya última
+// versión es anterior al fichero antiguo <dominio>.json, se leen de ese fichero.
+func componerSnapshot(domain string) (map[string]interface{}, error) {
+	lista, err := listarSnapshotsHistorial(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	vista := make(map[string]interface{})
+	var fechaAntiguo time.Time
+	if info, err := os.Stat(snapshotFilePath(domain)); err == nil {
+		antiguo, err := readJSONFile(snapshotFilePath(domain))
+		if err != nil {
+			return nil, err
+		}
+		vista, fechaAntiguo = antiguo, info.ModTime()
+	} else if len(lista) == 0 {
+		return nil, fmt.Errorf("no hay ningún snapshot guardado para %s", domain)
+	}
+
+	// La lista va del más reciente al más antiguo: la primera aparición de cada sección es la buena
+	cubiertas := make(map[string]bool)
+	porSnapshot := make(map[string][]string)
+	for _, metadata := range lista {
+		for _, seccion := range metadata.Sections {
+			if cubiertas[seccion] {
+				continue
+			}
+			cubiertas[seccion] = true
+			if _, ok := vista[seccion]; ok && metadata.FetchedAt.Before(fechaAntiguo) {
+				continue
+			}
+			porSnapshot[metadata.ID] = append(porSnapshot[metadata.ID], seccion)
+		}
+	}
+	for id, secciones := range porSnapshot {
+		snapshot, err := cargarSnapshotHistorial(domain, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, seccion := range secciones {
+			vista[seccion] = snapshot.Datos[seccion]
+		}
+	}
+	return vista, nil
+}
+
+// conexionHistorial devuelve la conexión cuyo historial se consulta (?conexion=índice, por
+// defecto la activa).
+func conexionHistorial(r *http.Request) (Credentials, error) {
+	indice, err := indiceConexionParam(r, "conexion")
+	if err != nil {
+		return Credentials{}, err
+	}
+	if indice < 0 {
+		return getCredentials()
+	}
+	return getCredentialsIndex(indice)
+}
+
+// handleListSnapshots devuelve los metadatos de los snapshots guardados de una conexión.
+func handleListSnapshots(w http.ResponseWriter, r *http.Request) {
+	conn, err := conexionHistorial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lista, err := listarSnapshotsHistorial(conn.Domain)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lista)
+}
+
+// handleGetSnapshot devuelve un snapshot completo del historial (?id=).
+func handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	conn, err := conexionHistorial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	snapshot, err := cargarSnapshotHistorial(conn.Domain, r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// handleSnapshotDiff compara dos snapshots del historial (?a=id&b=id), de "a" (antiguo) a "b".
+func handleSnapshotDiff(w http.ResponseWriter, r *http.Request) {
+	conn, err := conexionHistorial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	snapshotA, err := cargarSnapshotHistorial(conn.Domain, r.URL.Query().Get("a"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	snapshotB, err := cargarSnapshotHistorial(conn.Domain, r.URL.Query().Get("b"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	comparacion, err := compararSnapshots(snapshotA.Datos, snapshotB.Datos, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	comparacion.DomainA = snapshotA.Metadata.Domain
+	comparacion.DomainB = snapshotB.Metadata.Domain
+	comparacion.SnapshotA = snapshotA.Metadata.ID
+	comparacion.SnapshotB = snapshotB.Metadata.ID
+	comparacion.Source = "historial"
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"metadataA":   snapshotA.Metadata,
+		"metadataB":   snapshotB.Metadata,
+		"comparacion": comparacion,
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Dominio ficticio: el historial se guarda bajo jsonDirPath y se borra al terminar cada prueba.
+const dominioHistorialPrueba = "https://zz-prueba-historial.atlassian.net"
+
+func limpiarHistorialPrueba(t *testing.T) {
+	t.Helper()
+	limpiar := func() {
+		os.RemoveAll(historialDirPath(dominioHistorialPrueba))
+		os.Remove(filepath.Dir(historialDirPath(dominioHistorialPrueba)))
+		os.Remove(snapshotFilePath(dominioHistorialPrueba))
+	}
+	limpiar()
+	t.Cleanup(limpiar)
+}
+
+// escribirSnapshotPrueba guarda un snapshot del historial con la fecha indicada.
+func escribirSnapshotPrueba(t *testing.T, fecha time.Time, datos map[string]interface{}) SnapshotMetadata {
+	t.Helper()
+	metadata := SnapshotMetadata{ID: fecha.Format(snapshotIDFormat), FetchedAt: fecha, Domain: dominioHistorialPrueba, Counts: map[string]int{}}
+	for seccion := range datos {
+		metadata.Sections = append(metadata.Sections, seccion)
+	}
+	if err := os.MkdirAll(historialDirPath(dominioHistorialPrueba), 0755); err != nil {
+		t.Fatal(err)
+	}
+	jsonBytes, _ := json.Marshal(SnapshotFile{Metadata: metadata, Datos: datos})
+	if err := os.WriteFile(filepath.Join(historialDirPath(dominioHistorialPrueba), metadata.ID+".json"), jsonBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := guardarMetadatosSnapshot(dominioHistorialPrueba, metadata); err != nil {
+		t.Fatal(err)
+	}
+	return metadata
+}
+
+// nombreUnico devuelve el nombre del único estado (o proyecto, por su clave) de una sección.
+func nombreUnico(t *testing.T, vista map[string]interface{}, seccion string) string {
+	t.Helper()
+	var elementos []struct {
+		Name string `json:"name"`
+		Key  string `json:"key"`
+	}
+	if err := leerSeccion(vista, seccion, &elementos); err != nil || len(elementos) != 1 {
+		t.Fatalf("sección %s: %v (%v)", seccion, elementos, err)
+	}
+	if elementos[0].Key != "" {
+		return elementos[0].Key
+	}
+	return elementos[0].Name
+}
+
+func TestComponerSnapshot(t *testing.T) {
+	limpiarHistorialPrueba(t)
+	if _, err := componerSnapshot(dominioHistorialPrueba); err == nil {
+		t.Fatal("sin snapshots guardados se esperaba un error")
+	}
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	escribirSnapshotPrueba(t, base, map[string]interface{}{
+		"estados":   []JiraStatus{{Name: "Estados de marzo"}},
+		"proyectos": []JiraProject{{Key: "MARZO"}},
+	})
+	escribirSnapshotPrueba(t, base.AddDate(0, 1, 0), map[string]interface{}{
+		"estados": []JiraStatus{{Name: "Estados de abril"}},
+	})
+
+	vista, err := componerSnapshot(dominioHistorialPrueba)
+	if err != nil {
+		t.Fatalf("error componiendo: %v", err)
+	}
+	// Cada sección sale del snapshot más reciente que la contiene
+	if got := nombreUnico(t, vista, "estados"); got != "Estados de abril" {
+		t.Errorf("estados de %q", got)
+	}
+	if got := nombreUnico(t, vista, "proyectos"); got != "MARZO" {
+		t.Errorf("proyectos de %q", got)
+	}
+	if len(vista) != 2 {
+		t.Errorf("secciones de la vista: %v", vista)
+	}
+
+	// Fichero antiguo <dominio>.json escrito a mediados de marzo: gana a las secciones del
+	// historial anteriores a él y aporta las que el historial no tiene
+	antiguo, _ := json.Marshal(map[string]interface{}{
+		"estados":   []JiraStatus{{Name: "Estados del fichero antiguo"}},
+		"proyectos": []JiraProject{{Key: "ANTIGUO"}},
+		"workflows": []JiraWorkflow{{ID: WorkflowID{Name: "Workflow del fichero antiguo"}}},
+	})
+	if err := os.WriteFile(snapshotFilePath(dominioHistorialPrueba), antiguo, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mediados := base.AddDate(0, 0, 15)
+	if err := os.Chtimes(snapshotFilePath(dominioHistorialPrueba), mediados, mediados); err != nil {
+		t.Fatal(err)
+	}
+
+	vista, err = componerSnapshot(dominioHistorialPrueba)
+	if err != nil {
+		t.Fatalf("error componiendo con el fichero antiguo: %v", err)
+	}
+	if got := nombreUnico(t, vista, "estados"); got != "Estados de abril" {
+		t.Errorf("estados de %q, se esperaba el snapshot de abril, posterior al fichero antiguo", got)
+	}
+	if got := nombreUnico(t, vista, "proyectos"); got != "ANTIGUO" {
+		t.Errorf("proyectos de %q, se esperaba el fichero antiguo, posterior al snapshot de marzo", got)
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(vista, "workflows", &workflows); err != nil || len(workflows) != 1 {
+		t.Errorf("workflows del fichero antiguo: %v (%v)", workflows, err)
+	}
+}
+
+func TestListarSnapshotsSinMetadatos(t *testing.T) {
+	limpiarHistorialPrueba(t)
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	viejo := escribirSnapshotPrueba(t, base, map[string]interface{}{"estados": []JiraStatus{}})
+	nuevo := escribirSnapshotPrueba(t, base.Add(time.Hour), map[string]interface{}{"proyectos": []JiraProject{}})
+
+	// Un snapshot de antes de existir el fichero de metadatos se lee completo y se le crea
+	sidecar := filepath.Join(historialDirPath(dominioHistorialPrueba), viejo.ID+sufijoMetadatos)
+	if err := os.Remove(sidecar); err != nil {
+		t.Fatal(err)
+	}
+	lista, err := listarSnapshotsHistorial(dominioHistorialPrueba)
+	if err != nil {
+		t.Fatalf("error listando: %v", err)
+	}
+	if len(lista) != 2 || lista[0].ID != nuevo.ID || lista[1].ID != viejo.ID {
+		t.Fatalf("lista = %+v, se esperaba del más reciente al más antiguo", lista)
+	}
+	if len(lista[1].Sections) != 1 || lista[1].Sections[0] != "estados" {
+		t.Errorf("metadatos leídos del snapshot completo: %+v", lista[1])
+	}
+	if _, err := os.Stat(sidecar); err != nil {
+		t.Errorf("no se ha recreado el fichero de metadatos: %v", err)
+	}
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ----------------------------------------------------------------
+// Workflows declarados en un fichero YAML o JSON con la misma forma que JiraWorkflow.
+// Se validan sin conexión contra el snapshot y se crean o actualizan en la conexión activa.
+//
+// Los estados se pueden indicar por ID o por nombre, tanto en "statuses" como en el from/to
+// de las transiciones. En las reglas, "type" es el ruleKey de la API de workflows
+// (por ejemplo system:check-permission) y "configuration" sus parámetros.
+// ----------------------------------------------------------------
+
+// Campos de texto que en YAML se suelen escribir sin comillas aunque su valor sea un número,
+// como los IDs de estado en "id", "to" o "from"
+var camposTextoDeclarativo = map[string]bool{"id": true, "name": true, "description": true, "to": true, "from": true}
+
+// textoEnCamposDeclarativos convierte a texto los números que aparecen en camposTextoDeclarativo
+// (también dentro de listas como "from"). No entra en properties ni configuration, cuyos valores
+// se envían a Jira tal cual.
+func textoEnCamposDeclarativos(valor interface{}, enCampoTexto bool) interface{} {
+	switch v := valor.(type) {
+	case map[string]interface{}:
+		for clave, hijo := range v {
+			if clave == "properties" || clave == "configuration" {
+				continue
+			}
+			v[clave] = textoEnCamposDeclarativos(hijo, camposTextoDeclarativo[clave])
+		}
+	case []interface{}:
+		for i, hijo := range v {
+			v[i] = textoEnCamposDeclarativos(hijo, enCampoTexto)
+		}
+	case int, int64, uint64, float64:
+		if enCampoTexto {
+			return fmt.Sprint(v)
+		}
+	}
+	return valor
+}
+
+// decodificarDeclarativo interpreta un fichero YAML o JSON en dest. Se pasa por JSON para usar
+// las mismas etiquetas que el snapshot y se rechazan campos desconocidos. Los IDs escritos sin
+// comillas (id: 10001, to: 3, from: [2]) se leen como texto.
+func decodificarDeclarativo(contenido []byte, dest interface{}) error {
+	var generico interface{}
+	if err := yaml.Unmarshal(contenido, &generico); err != nil {
+		return fmt.Errorf("error parseando el fichero: %w", err)
+	}
+	jsonBytes, err := json.Marshal(textoEnCamposDeclarativos(generico, false))
+	if err != nil {
+		return fmt.Errorf("error convirtiendo el fichero: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dest)
+}
+
+// leerWorkflowDeclarado interpreta el contenido (YAML o JSON) como un JiraWorkflow.
+func leerWorkflowDeclarado(contenido []byte) (JiraWorkflow, error) {
+	var wf JiraWorkflow
+	if err := decodificarDeclarativo(contenido, &wf); err != nil {
+		return wf, fmt.Errorf("la definición no tiene la forma de un workflow: %w", err)
+	}
+	return wf, nil
+}
+
+// validarWorkflowDeclarado resuelve los estados del workflow contra los estados del snapshot y
+// comprueba las transiciones y el grafo. Devuelve el workflow con IDs reales y tipos normalizados,
+// los errores (que impiden enviarlo) y los avisos.
+func validarWorkflowDeclarado(wf JiraWorkflow, estados []JiraStatus) (JiraWorkflow, []string, []string) {
+	errores, avisos := []string{}, []string{}
+	resuelto := JiraWorkflow{ID: wf.ID, Description: wf.Description}
+	if strings.TrimSpace(wf.ID.Name) == "" {
+		errores = append(errores, "Falta el nombre del workflow (id.name)")
+	}
+
+	porID := make(map[string]JiraStatus, len(estados))
+	porNombre := make(map[string]JiraStatus, len(estados))
+	for _, st := range estados {
+		porID[st.ID] = st
+		// Ante nombres repetidos se prefiere el estado global
+		if anterior, ok := porNombre[strings.ToLower(st.Name)]; !ok || ambitoEstado(anterior) != "GLOBAL" {
+			porNombre[strings.ToLower(st.Name)] = st
+		}
+	}
+
+	// Referencias válidas dentro del workflow: ID o nombre (sin distinguir mayúsculas)
+	referencias := make(map[string]string)
+	for _, ws := range wf.Statuses {
+		st, ok := porID[ws.ID]
+		if !ok {
+			st, ok = porNombre[strings.ToLower(strings.TrimSpace(ws.Name))]
+		}
+		if !ok {
+			errores = append(errores, fmt.Sprintf("Estado desconocido en el snapshot: %s", referenciaEstado(ws)))
+			continue
+		}
+		if _, repetido := referencias[st.ID]; repetido {
+			avisos = append(avisos, fmt.Sprintf("El estado %s aparece más de una vez", st.Name))
+			continue
+		}
+		referencias[st.ID] = st.ID
+		referencias[strings.ToLower(st.Name)] = st.ID
+		resuelto.Statuses = append(resuelto.Statuses, WorkflowStatus{ID: st.ID, Name: st.Name, Properties: ws.Properties})
+	}
+	if len(wf.Statuses) == 0 {
+		errores = append(errores, "El workflow no declara ningún estado")
+	}
+	resolver := func(ref string) (string, bool) {
+		if id, ok := referencias[ref]; ok {
+			return id, true
+		}
+		id, ok := referencias[strings.ToLower(strings.TrimSpace(ref))]
+		return id, ok
+	}
+
+	iniciales := 0
+	for _, t := range wf.Transitions {
+		nueva := t
+		nueva.Type = strings.ToLower(t.Type)
+		if nueva.Type == "" {
+			nueva.Type = "directed"
+		}
+		nombre := t.Name
+		if strings.TrimSpace(nombre) == "" {
+			errores = append(errores, fmt.Sprintf("Hay una transición sin nombre hacia %s", t.To))
+			nombre = "(sin nombre)"
+		}
+
+		switch nueva.Type {
+		case "initial":
+			iniciales++
+			if len(t.From) > 0 {
+				errores = append(errores, fmt.Sprintf("La transición inicial %q no puede tener origen", nombre))
+			}
+		case "global":
+			if len(t.From) > 0 {
+				errores = append(errores, fmt.Sprintf("La transición global %q no puede tener origen", nombre))
+			}
+		case "directed":
+			if len(t.From) == 0 {
+				errores = append(errores, fmt.Sprintf("La transición %q no tiene estado de origen", nombre))
+			}
+		default:
+			errores = append(errores, fmt.Sprintf("Tipo de transición %q no válido en %q (initial, global o directed)", t.Type, nombre))
+		}
+
+		id, ok := resolver(t.To)
+		if !ok {
+			errores = append(errores, fmt.Sprintf("La transición %q va a un estado que no está en el workflow: %s", nombre, t.To))
+		}
+		nueva.To = id
+		nueva.From = nil
+		for _, from := range t.From {
+			id, ok := resolver(from)
+			if !ok {
+				errores = append(errores, fmt.Sprintf("La transición %q sale de un estado que no está en el workflow: %s", nombre, from))
+				continue
+			}
+			nueva.From = append(nueva.From, id)
+		}
+		if t.Screen != nil {
+			avisos = append(avisos, fmt.Sprintf("La pantalla de la transición %q no se envía; se mantiene la que tenga en Jira", nombre))
+		}
+		resuelto.Transitions = append(resuelto.Transitions, nueva)
+	}
+	switch {
+	case iniciales == 0:
+		errores = append(errores, "El workflow no tiene transición inicial")
+	case iniciales > 1:
+		errores = append(errores, fmt.Sprintf("El workflow tiene %d transiciones iniciales", iniciales))
+	}
+
+	// El análisis del grafo solo tiene sentido si las referencias se han resuelto
+	if len(errores) == 0 {
+		lint := analizarGrafoWorkflow(resuelto, categoriasEstados(estados))
+		for _, st := range lint.Unreachable {
+			errores = append(errores, fmt.Sprintf("El estado %s es inalcanzable desde la transición inicial", st.Name))
+		}
+		for _, st := range lint.DeadEnds {
+			avisos = append(avisos, fmt.Sprintf("El estado %s no tiene salida y no está en la categoría Done", st.Name))
+		}
+	}
+	return resuelto, errores, avisos
+}
+
+// referenciaEstado describe un estado del fichero para los mensajes de error.
+func referenciaEstado(ws WorkflowStatus) string {
+	switch {
+	case ws.Name != "" && ws.ID != "":
+		return fmt.Sprintf("%s (%s)", ws.Name, ws.ID)
+	case ws.Name != "":
+		return ws.Name
+	}
+	return ws.ID
+}
+
+// claveTransicion identifica una transición por nombre, origen y destino (IDs de estado).
+func claveTransicion(nombre string, from []string, to string) string {
+	origen := append([]string(nil), from...)
+	sort.Strings(origen)
+	return strings.ToLower(nombre) + "|" + strings.Join(origen, ",") + "|" + to
+}
+
+// heredarTransicionesVivas empareja las transiciones del fichero con las del workflow de Jira: por
+// ID si el fichero lo trae y si no por nombre, origen y destino. Las emparejadas conservan el ID y
+// la pantalla que tienen en Jira, que el fichero no puede expresar. Devuelve la pantalla de cada
+// transición (nil si no hay pareja).
+func heredarTransicionesVivas(transiciones []Transition, vivo JiraWorkflowWrite) []*JiraWorkflowRuleWrite {
+	porID := make(map[string]JiraWorkflowWriteTransition, len(vivo.Transitions))
+	porClave := make(map[string]JiraWorkflowWriteTransition, len(vivo.Transitions))
+	for _, t := range vivo.Transitions {
+		var from []string
+		for _, link := range t.Links {
+			from = append(from, link.FromStatusReference)
+		}
+		porID[t.ID] = t
+		porClave[claveTransicion(t.Name, from, t.ToStatusReference)] = t
+	}
+	usados := make(map[string]bool)
+	for _, t := range transiciones {
+		if t.ID != "" {
+			usados[t.ID] = true
+		}
+	}
+
+	pantallas := make([]*JiraWorkflowRuleWrite, len(transiciones))
+	for i := range transiciones {
+		t := &transiciones[i]
+		pareja, ok := porID[t.ID]
+		if t.ID == "" {
+			pareja, ok = porClave[claveTransicion(t.Name, t.From, t.To)]
+			if !ok || usados[pareja.ID] {
+				continue
+			}
+			t.ID = pareja.ID
+			usados[t.ID] = true
+		}
+		if ok {
+			pantallas[i] = pareja.TransitionScreen
+		}
+	}
+	return pantallas
+}
+
+// asignarIDsTransiciones da ID a las transiciones que no lo traen: 1 a la inicial y 11, 21, 31...
+// al resto, saltando los que ya estén en uso y los reservados (los del workflow vivo, para no
+// reutilizar el ID de una transición que desaparece).
+func asignarIDsTransiciones(transiciones []Transition, reservados []string) {
+	usados := make(map[string]bool)
+	for _, id := range reservados {
+		usados[id] = true
+	}
+	for _, t := range transiciones {
+		if t.ID != "" {
+			usados[t.ID] = true
+		}
+	}
+	siguiente := 11
+	for i := range transiciones {
+		if transiciones[i].ID != "" {
+			continue
+		}
+		if transiciones[i].Type == "initial" && !usados["1"] {
+			transiciones[i].ID = "1"
+			usados["1"] = true
+			continue
+		}
+		for usados[strconv.Itoa(siguiente)] {
+			siguiente += 10
+		}
+		transiciones[i].ID = strconv.Itoa(siguiente)
+		usados[transiciones[i].ID] = true
+	}
+}
+
+// parametrosTexto convierte una configuración a los parámetros de texto que espera la API.
+func parametrosTexto(configuracion map[string]interface{}) map[string]string {
+	parametros := make(map[string]string, len(configuracion))
+	for clave, valor := range configuracion {
+		if texto, ok := valor.(string); ok {
+			parametros[clave] = texto
+			continue
+		}
+		jsonBytes, _ := json.Marshal(valor)
+		parametros[clave] = string(jsonBytes)
+	}
+	return parametros
+}
+
+// reglasEscritura convierte validadores o post-funciones al formato ruleKey/parameters.
+func reglasEscritura(reglas []WorkflowRule) []JiraWorkflowRuleWrite {
+	resultado := []JiraWorkflowRuleWrite{}
+	for _, r := range reglas {
+		resultado = append(resultado, JiraWorkflowRuleWrite{RuleKey: r.Type, Parameters: parametrosTexto(r.Configuration)})
+	}
+	return resultado
+}
+
+// grupoCondiciones convierte un nodo del árbol de condiciones en un grupo de la API.
+func grupoCondiciones(nodo WorkflowCondition) JiraConditionGroup {
+	grupo := JiraConditionGroup{
+		Operation:       "ALL",
+		Conditions:      []JiraWorkflowRuleWrite{},
+		ConditionGroups: []JiraConditionGroup{},
+	}
+	if nodo.NodeType != "compound" {
+		grupo.Conditions = append(grupo.Conditions, JiraWorkflowRuleWrite{RuleKey: nodo.Type, Parameters: parametrosTexto(nodo.Configuration)})
+		return grupo
+	}
+	if strings.EqualFold(nodo.Operator, "OR") {
+		grupo.Operation = "ANY"
+	}
+	for _, hijo := range nodo.Conditions {
+		if hijo.NodeType == "compound" {
+			grupo.ConditionGroups = append(grupo.ConditionGroups, grupoCondiciones(hijo))
+		} else {
+			grupo.Conditions = append(grupo.Conditions, JiraWorkflowRuleWrite{RuleKey: hijo.Type, Parameters: parametrosTexto(hijo.Configuration)})
+		}
+	}
+	return grupo
+}
+
+// cuerpoEscrituraWorkflow construye el cuerpo de creación o actualización a partir del workflow
+// ya validado. Si vivo no es nil se trata de una actualización: las transiciones conservan el ID y
+// la pantalla que tienen en Jira (ver heredarTransicionesVivas).
+func cuerpoEscrituraWorkflow(wf JiraWorkflow, estados []JiraStatus, vivo *JiraWorkflowWrite) JiraWorkflowWriteRequest {
+	porID := make(map[string]JiraStatus, len(estados))
+	for _, st := range estados {
+		porID[st.ID] = st
+	}
+
+	escritura := JiraWorkflowWrite{
+		Description: wf.Description,
+		Statuses:    []JiraWorkflowWriteStatus{},
+		Transitions: []JiraWorkflowWriteTransition{},
+	}
+	transiciones := append([]Transition(nil), wf.Transitions...)
+	pantallas := make([]*JiraWorkflowRuleWrite, len(transiciones))
+	var reservados []string
+	if vivo != nil {
+		escritura.ID = vivo.ID
+		escritura.Version = vivo.Version
+		pantallas = heredarTransicionesVivas(transiciones, *vivo)
+		for _, t := range vivo.Transitions {
+			reservados = append(reservados, t.ID)
+		}
+	} else {
+		escritura.Name = wf.ID.Name
+	}
+	asignarIDsTransiciones(transiciones, reservados)
+
+	cuerpo := JiraWorkflowWriteRequest{Statuses: []JiraWorkflowStatusRef{}}
+	for _, ws := range wf.Statuses {
+		st := porID[ws.ID]
+		cuerpo.Statuses = append(cuerpo.Statuses, JiraWorkflowStatusRef{
+			ID:              st.ID,
+			Name:            st.Name,
+			StatusCategory:  st.StatusCategory,
+			StatusReference: st.ID,
+		})
+		escritura.Statuses = append(escritura.Statuses, JiraWorkflowWriteStatus{
+			StatusReference: st.ID,
+			Properties:      parametrosTexto(ws.Properties),
+		})
+	}
+
+	for i, t := range transiciones {
+		transicion := JiraWorkflowWriteTransition{
+			ID:                t.ID,
+			Name:              t.Name,
+			Description:       t.Description,
+			Type:              strings.ToUpper(t.Type),
+			ToStatusReference: t.To,
+			Links:             []JiraTransitionLink{},
+			Validators:        []JiraWorkflowRuleWrite{},
+			Actions:           []JiraWorkflowRuleWrite{},
+			TransitionScreen:  pantallas[i],
+			Properties:        parametrosTexto(t.Properties),
+		}
+		for _, from := range t.From {
+			transicion.Links = append(transicion.Links, JiraTransitionLink{FromStatusReference: from})
+		}
+		if t.Rules != nil {
+			switch {
+			case t.Rules.ConditionsTree != nil:
+				grupo := grupoCondiciones(*t.Rules.ConditionsTree)
+				transicion.Conditions = &grupo
+			case len(t.Rules.Conditions) > 0:
+				transicion.Conditions = &JiraConditionGroup{
+					Operation:       "ALL",
+					Conditions:      reglasEscritura(t.Rules.Conditions),
+					ConditionGroups: []JiraConditionGroup{},
+				}
+			}
+			transicion.Validators = reglasEscritura(t.Rules.Validators)
+			transicion.Actions = reglasEscritura(t.Rules.PostFunctions)
+		}
+		escritura.Transitions = append(escritura.Transitions, transicion)
+	}
+
+	cuerpo.Workflows = []JiraWorkflowWrite{escritura}
+	return cuerpo
+}
+
+// procesarWorkflowDeclarado valida la definición contra el snapshot de la conexión activa y, si
+// "aplicar" y no hay errores, crea o actualiza el workflow en Jira y lo refresca en el snapshot.
+func procesarWorkflowDeclarado(contenido []byte, aplicar bool) (WorkflowFileResult, error) {
+	resultado := WorkflowFileResult{Errors: []string{}, Warnings: []string{}}
+	wf, err := leerWorkflowDeclarado(contenido)
+	if err != nil {
+		return resultado, err
+	}
+	conn, snapshot, err := cargarSnapshotActual()
+	if err != nil {
+		return resultado, err
+	}
+	if _, ok := snapshot["estados"]; !ok {
+		return resultado, fmt.Errorf("el snapshot no contiene estados; ejecuta antes la descarga de estados")
+	}
+	var estados []JiraStatus
+	if err := leerSeccion(snapshot, "estados", &estados); err != nil {
+		return resultado, err
+	}
+	var workflows []JiraWorkflow
+	if err := leerSeccion(snapshot, "workflows", &workflows); err != nil {
+		return resultado, err
+	}
+
+	resuelto, errores, avisos := validarWorkflowDeclarado(wf, estados)
+	resultado.Workflow = resuelto.ID.Name
+	resultado.Errors, resultado.Warnings = errores, avisos
+	resultado.Valid = len(errores) == 0
+	resultado.Action = "create"
+	if _, ok := buscarWorkflow(workflows, resuelto.ID.Name); ok {
+		resultado.Action = "update"
+	}
+	if !aplicar || !resultado.Valid {
+		return resultado, nil
+	}
+
+	// La decisión final entre crear y actualizar se toma con el estado real de Jira
+	client := conectarAJira(conn.Domain, conn.Correo, conn.Token)
+	abrirLoteDiario(client)
+	defer cerrarLoteDiario(client)
+	vivo, err := obtenerWorkflowVivoJira(client, resuelto.ID.Name)
+	if err != nil {
+		return resultado, err
+	}
+	cuerpo := cuerpoEscrituraWorkflow(resuelto, estados, vivo)
+	if vivo != nil {
+		resultado.Action = "update"
+		err = actualizarWorkflowJira(client, cuerpo)
+	} else {
+		resultado.Action = "create"
+		err = crearWorkflowJira(client, cuerpo)
+	}
+	if err != nil {
+		resultado.Message = err.Error()
+		return resultado, nil
+	}
+	resultado.Applied = true
+
+	if refrescados, err := buscarWorkflowsJira(client, []string{resuelto.ID.Name}); err != nil {
+		log.Println("Error al refrescar el workflow:", err)
+	} else if err := guardarWorkflowsSnapshot(conn, refrescados); err != nil {
+		log.Println("Error al actualizar los workflows del snapshot:", err)
+	}
+	return resultado, nil
+}
+
+// handleWorkflowFile valida una definición de workflow y, con "apply", la envía a Jira.
+func handleWorkflowFile(w http.ResponseWriter, r *http.Request) {
+	var peticion WorkflowFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&peticion); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := procesarWorkflowDeclarado([]byte(peticion.Content), peticion.Apply)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resultado)
+}
+
+// workflowDeclaradoCLI valida (y con --apply aplica) un fichero de workflow desde la línea de
+// comandos: app workflow <fichero> [--apply]. Devuelve 1 si el fichero no es válido o falla el envío.
+func workflowDeclaradoCLI(args []string) int {
+	var fichero string
+	aplicar := false
+	for _, arg := range args {
+		if arg == "--apply" {
+			aplicar = true
+		} else {
+			fichero = arg
+		}
+	}
+	if fichero == "" {
+		fmt.Println("Uso: app workflow <fichero.yaml|fichero.json> [--apply]")
+		return 2
+	}
+	contenido, err := os.ReadFile(fichero)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 2
+	}
+
+	resultado, err := procesarWorkflowDeclarado(contenido, aplicar)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 2
+	}
+	fmt.Printf("Workflow %q (%s)\n", resultado.Workflow, resultado.Action)
+	for _, e := range resultado.Errors {
+		fmt.Println("  ERROR:", e)
+	}
+	for _, a := range resultado.Warnings {
+		fmt.Println("  AVISO:", a)
+	}
+	switch {
+	case !resultado.Valid:
+		fmt.Println("El workflow no es válido; no se ha enviado nada.")
+		return 1
+	case !aplicar:
+		fmt.Println("Validación correcta. Usa --apply para enviarlo a Jira.")
+	case resultado.Applied:
+		fmt.Println("Workflow enviado a Jira.")
+	default:
+		fmt.Println("Error al enviar el workflow:", resultado.Message)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// estadosPrueba son los estados del snapshot que usan las pruebas de workflows.
+var estadosPrueba = []JiraStatus{
+	{ID: "1", Name: "To Do", StatusCategory: "TODO"},
+	{ID: "2", Name: "In Progress", StatusCategory: "IN_PROGRESS"},
+	{ID: "3", Name: "Done", StatusCategory: "DONE"},
+	{ID: "4", Name: "Blocked", StatusCategory: "IN_PROGRESS"},
+}
+
+// contieneTexto indica si algún mensaje de la lista contiene el fragmento.
+func contieneTexto(mensajes []string, fragmento string) bool {
+	for _, m := range mensajes {
+		if strings.Contains(m, fragmento) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidarWorkflowDeclarado(t *testing.T) {
+	inicial := Transition{Name: "Create", Type: "initial", To: "To Do"}
+	casos := []struct {
+		nombre  string
+		wf      JiraWorkflow
+		errores []string
+		avisos  []string
+	}{
+		{
+			nombre: "válido por nombre y por ID",
+			wf: JiraWorkflow{
+				ID:       WorkflowID{Name: "Simple"},
+				Statuses: []WorkflowStatus{{Name: "to do"}, {ID: "2"}, {Name: "Done"}},
+				Transitions: []Transition{
+					inicial,
+					{Name: "Start", From: []string{"To Do"}, To: "In Progress"},
+					{Name: "Finish", From: []string{"2"}, To: "done"},
+				},
+			},
+		},
+		{
+			nombre: "estado desconocido",
+			wf: JiraWorkflow{
+				ID:          WorkflowID{Name: "Desconocido"},
+				Statuses:    []WorkflowStatus{{Name: "To Do"}, {Name: "Fantasma"}},
+				Transitions: []Transition{inicial},
+			},
+			errores: []string{"Estado desconocido en el snapshot: Fantasma"},
+		},
+		{
+			nombre: "transición hacia un estado fuera del workflow",
+			wf: JiraWorkflow{
+				ID:       WorkflowID{Name: "Fuera"},
+				Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "Done"}},
+				Transitions: []Transition{
+					inicial,
+					{Name: "Block", From: []string{"To Do"}, To: "Blocked"},
+					{Name: "Finish", From: []string{"To Do"}, To: "Done"},
+				},
+			},
+			errores: []string{"va a un estado que no está en el workflow: Blocked"},
+		},
+		{
+			nombre: "estado inalcanzable",
+			wf: JiraWorkflow{
+				ID:       WorkflowID{Name: "Inalcanzable"},
+				Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "Blocked"}, {Name: "Done"}},
+				Transitions: []Transition{
+					inicial,
+					{Name: "Finish", From: []string{"To Do"}, To: "Done"},
+					{Name: "Unblock", From: []string{"Blocked"}, To: "Done"},
+				},
+			},
+			errores: []string{"El estado Blocked es inalcanzable"},
+		},
+		{
+			nombre: "sin transición inicial ni nombre",
+			wf: JiraWorkflow{
+				Statuses:    []WorkflowStatus{{Name: "To Do"}, {Name: "Done"}},
+				Transitions: []Transition{{Name: "Finish", From: []string{"To Do"}, To: "Done"}},
+			},
+			errores: []string{"Falta el nombre del workflow", "no tiene transición inicial"},
+		},
+		{
+			nombre: "transición dirigida sin origen y tipo no válido",
+			wf: JiraWorkflow{
+				ID:       WorkflowID{Name: "Tipos"},
+				Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "Done"}},
+				Transitions: []Transition{
+					inicial,
+					{Name: "Finish", To: "Done"},
+					{Name: "Raro", Type: "loop", From: []string{"Done"}, To: "To Do"},
+				},
+			},
+			errores: []string{"La transición \"Finish\" no tiene estado de origen", "Tipo de transición \"loop\" no válido"},
+		},
+		{
+			nombre: "estado sin salida fuera de Done",
+			wf: JiraWorkflow{
+				ID:       WorkflowID{Name: "Atasco"},
+				Statuses: []WorkflowStatus{{Name: "To Do"}, {Name: "Blocked"}, {Name: "Done"}},
+				Transitions: []Transition{
+					inicial,
+					{Name: "Block", From: []string{"To Do"}, To: "Blocked"},
+					{Name: "Finish", From: []string{"To Do"}, To: "Done"},
+				},
+			},
+			avisos: []string{"El estado Blocked no tiene salida"},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			resuelto, errores, avisos := validarWorkflowDeclarado(caso.wf, estadosPrueba)
+			if len(errores) != 0 && len(caso.errores) == 0 {
+				t.Fatalf("errores inesperados: %v", errores)
+			}
+			for _, e := range caso.errores {
+				if !contieneTexto(errores, e) {
+					t.Errorf("falta el error %q en %v", e, errores)
+				}
+			}
+			for _, a := range caso.avisos {
+				if !contieneTexto(avisos, a) {
+					t.Errorf("falta el aviso %q en %v", a, avisos)
+				}
+			}
+			if len(errores) > 0 {
+				return
+			}
+			// Las referencias por nombre se sustituyen por los IDs del snapshot
+			for _, tr := range resuelto.Transitions {
+				if _, ok := categoriasEstados(estadosPrueba)[tr.To]; !ok {
+					t.Errorf("la transición %q apunta a %q, que no es un ID", tr.Name, tr.To)
+				}
+			}
+		})
+	}
+}
+
+func TestLeerWorkflowDeclaradoIDsNumericos(t *testing.T) {
+	contenido := []byte(`
+id:
+  name: 2024
+statuses:
+  - id: 1
+  - name: In Progress
+  - id: 3
+transitions:
+  - id: 1
+    name: Create
+    type: initial
+    to: 1
+  - name: Start
+    from: [1]
+    to: In Progress
+    properties:
+      orden: 5
+  - name: Finish
+    from: [In Progress, 1]
+    to: 3
+`)
+	wf, err := leerWorkflowDeclarado(contenido)
+	if err != nil {
+		t.Fatalf("error leyendo el workflow: %v", err)
+	}
+	if wf.ID.Name != "2024" || wf.Statuses[0].ID != "1" || wf.Statuses[2].ID != "3" {
+		t.Errorf("nombre o IDs de estado mal leídos: %+v", wf)
+	}
+	if wf.Transitions[0].ID != "1" || wf.Transitions[0].To != "1" || wf.Transitions[2].To != "3" {
+		t.Errorf("transiciones mal leídas: %+v", wf.Transitions)
+	}
+	if got := wf.Transitions[2].From; len(got) != 2 || got[0] != "In Progress" || got[1] != "1" {
+		t.Errorf("from = %v", got)
+	}
+	// Las propiedades se conservan con su tipo
+	if _, ok := wf.Transitions[1].Properties["orden"].(float64); !ok {
+		t.Errorf("la propiedad orden ha cambiado de tipo: %#v", wf.Transitions[1].Properties["orden"])
+	}
+
+	_, errores, _ := validarWorkflowDeclarado(wf, estadosPrueba)
+	if len(errores) > 0 {
+		t.Errorf("errores inesperados con IDs numéricos: %v", errores)
+	}
+
+	// Los campos desconocidos se siguen rechazando
+	if _, err := leerWorkflowDeclarado([]byte("id: {name: X}\nestados: []\n")); err == nil {
+		t.Error("se esperaba un error por el campo desconocido estados")
+	}
+}
+
+func TestCuerpoEscrituraWorkflowConservaTransicionesVivas(t *testing.T) {
+	wf := JiraWorkflow{
+		ID:       WorkflowID{Name: "Simple"},
+		Statuses: []WorkflowStatus{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		Transitions: []Transition{
+			{Name: "Create", Type: "initial", To: "1"},
+			{Name: "start", Type: "directed", From: []string{"1"}, To: "2"},
+			{Name: "Finish", Type: "directed", From: []string{"2"}, To: "3"},
+			{Name: "Reopen", Type: "directed", From: []string{"3"}, To: "1"},
+		},
+	}
+	pantalla := &JiraWorkflowRuleWrite{RuleKey: "system:transition-screen", Parameters: map[string]string{"screenId": "10"}}
+	vivo := &JiraWorkflowWrite{
+		ID:      "wf-1",
+		Version: &JiraWorkflowVersion{ID: "v", VersionNumber: 3},
+		Transitions: []JiraWorkflowWriteTransition{
+			{ID: "1", Name: "Create", ToStatusReference: "1"},
+			{ID: "11", Name: "Start", ToStatusReference: "2", Links: []JiraTransitionLink{{FromStatusReference: "1"}}, TransitionScreen: pantalla},
+			// Mismo nombre pero otro destino: no es la misma transición
+			{ID: "21", Name: "Finish", ToStatusReference: "4", Links: []JiraTransitionLink{{FromStatusReference: "2"}}},
+		},
+	}
+
+	cuerpo := cuerpoEscrituraWorkflow(wf, estadosPrueba, vivo)
+	escritura := cuerpo.Workflows[0]
+	if escritura.ID != "wf-1" || escritura.Version == nil || escritura.Version.VersionNumber != 3 || escritura.Name != "" {
+		t.Fatalf("referencia de actualización incorrecta: %+v", escritura)
+	}
+	ids := map[string]string{}
+	for _, tr := range escritura.Transitions {
+		ids[tr.Name] = tr.ID
+	}
+	esperados := map[string]string{"Create": "1", "start": "11", "Finish": "31", "Reopen": "41"}
+	for nombre, id := range esperados {
+		if ids[nombre] != id {
+			t.Errorf("la transición %s tiene ID %q, se esperaba %q", nombre, ids[nombre], id)
+		}
+	}
+	if escritura.Transitions[1].TransitionScreen != pantalla {
+		t.Error("la transición Start ha perdido la pantalla que tiene en Jira")
+	}
+	if escritura.Transitions[2].TransitionScreen != nil {
+		t.Error("una transición sin pareja no debe llevar pantalla")
+	}
+	// El workflow validado no se modifica
+	if wf.Transitions[1].ID != "" {
+		t.Errorf("cuerpoEscrituraWorkflow ha modificado el workflow de entrada: %q", wf.Transitions[1].ID)
+	}
+
+	// Al crear no hay nada que heredar
+	nuevo := cuerpoEscrituraWorkflow(wf, estadosPrueba, nil).Workflows[0]
+	if nuevo.Name != "Simple" || nuevo.Transitions[0].ID != "1" || nuevo.Transitions[1].ID != "11" {
+		t.Errorf("IDs al crear: %+v", nuevo.Transitions)
+	}
+}